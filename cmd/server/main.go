@@ -1,40 +1,283 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/backup"
+	"github.com/jorgebaptista/octo-manager/internal/blueprint"
 	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/httpcache"
+	"github.com/jorgebaptista/octo-manager/internal/insights"
+	"github.com/jorgebaptista/octo-manager/internal/reports"
+	"github.com/jorgebaptista/octo-manager/internal/reposync"
+	"github.com/jorgebaptista/octo-manager/internal/safeguard"
+	"github.com/jorgebaptista/octo-manager/internal/softdelete"
+	"github.com/jorgebaptista/octo-manager/internal/webhooks"
 )
 
 func main() {
+	// GitHub responses are cached with their ETags, GitHub doesn't count revalidating them
+	var cacheStore httpcache.Store
+	switch backend := os.Getenv("GITHUB_CACHE"); backend {
+	case "", "memory":
+		size := httpcache.DefaultMemorySize
+		if value := os.Getenv("GITHUB_CACHE_SIZE"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				log.Fatalf("Invalid GITHUB_CACHE_SIZE: %q", value)
+			}
+			size = n
+		}
+		cacheStore = httpcache.NewMemoryStore(size)
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		redis, err := httpcache.DialRedis(addr, os.Getenv("REDIS_PASSWORD"))
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis: %v", err)
+		}
+		cacheStore = httpcache.NewRedisStore(redis, "octo-manager:")
+	case "off":
+	default:
+		log.Fatalf("Invalid GITHUB_CACHE: %q", backend)
+	}
+	var cacheTTL time.Duration
+	if value := os.Getenv("GITHUB_CACHE_TTL"); value != "" {
+		var err error
+		cacheTTL, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid GITHUB_CACHE_TTL: %v", err)
+		}
+	}
+
 	// Initialize GitHub client
-	ghClient, err := githubapi.NewClient()
+	var ghClient *githubapi.Client
+	var err error
+	if cacheStore != nil {
+		ghClient, err = githubapi.NewCachedClient(httpcache.New(cacheStore, cacheTTL))
+	} else {
+		ghClient, err = githubapi.NewClient()
+	}
 	if err != nil {
 		log.Fatalf("Failed to create GitHub client: %v", err)
 	}
 
+	// Load repository blueprints
+	blueprints := blueprint.NewRegistry()
+	if dir := os.Getenv("BLUEPRINTS_DIR"); dir != "" {
+		blueprints, err = blueprint.LoadDir(dir)
+		if err != nil {
+			log.Fatalf("Failed to load blueprints: %v", err)
+		}
+		log.Printf("Loaded blueprints: %v", blueprints.Names())
+	}
+
+	// Soft delete archives repos first and a reaper deletes them after the grace period
+	grace := softdelete.DefaultGracePeriod
+	if value := os.Getenv("SOFT_DELETE_GRACE_PERIOD"); value != "" {
+		grace, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid SOFT_DELETE_GRACE_PERIOD: %v", err)
+		}
+	}
+	var store softdelete.Store = softdelete.NewMemoryStore()
+	if path := os.Getenv("SOFT_DELETE_STORE"); path != "" {
+		store = softdelete.NewFileStore(path)
+	}
+	trash := softdelete.NewManager(ghClient, store, grace)
+	go trash.Run(context.Background(), time.Minute)
+	softDeleteDefault := os.Getenv("SOFT_DELETE") == "true"
+
+	// Protected repos can't be deleted, and deleting takes a confirmed second request
+	var protected, patterns []string
+	if value := os.Getenv("PROTECTED_REPOS"); value != "" {
+		protected = strings.Split(value, ",")
+	}
+	if value := os.Getenv("PROTECTED_REPOS_REGEX"); value != "" {
+		patterns = []string{value}
+	}
+	confirmTTL := safeguard.DefaultConfirmationTTL
+	if value := os.Getenv("DELETE_CONFIRMATION_TTL"); value != "" {
+		confirmTTL, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid DELETE_CONFIRMATION_TTL: %v", err)
+		}
+	}
+	guard, err := safeguard.NewGuard(ghClient, protected, patterns, confirmTTL)
+	if err != nil {
+		log.Fatalf("Invalid PROTECTED_REPOS_REGEX: %v", err)
+	}
+
+	// Backups before deletion go to a local directory or an S3 compatible bucket
+	var backups *backup.Service
+	if dir := os.Getenv("BACKUP_DIR"); dir != "" {
+		backups = backup.NewService(ghClient, backup.NewLocalStorage(dir))
+	} else if endpoint := os.Getenv("BACKUP_S3_ENDPOINT"); endpoint != "" {
+		backups = backup.NewService(ghClient, &backup.S3Storage{
+			Endpoint:  endpoint,
+			Bucket:    os.Getenv("BACKUP_S3_BUCKET"),
+			Region:    os.Getenv("BACKUP_S3_REGION"),
+			AccessKey: os.Getenv("BACKUP_S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("BACKUP_S3_SECRET_KEY"),
+		})
+	}
+
+	// Reports read this many repos at a time
+	parallelism := reports.DefaultParallelism
+	if value := os.Getenv("REPORT_PARALLELISM"); value != "" {
+		parallelism, err = strconv.Atoi(value)
+		if err != nil || parallelism < 1 {
+			log.Fatalf("Invalid REPORT_PARALLELISM: %q", value)
+		}
+	}
+	reporter := reports.NewReporter(ghClient, parallelism)
+
+	// GitHub only recomputes statistics after pushes, so insights are cached
+	insightsTTL := insights.DefaultCacheTTL
+	if value := os.Getenv("INSIGHTS_CACHE_TTL"); value != "" {
+		insightsTTL, err = time.ParseDuration(value)
+		if err != nil {
+			log.Fatalf("Invalid INSIGHTS_CACHE_TTL: %v", err)
+		}
+	}
+	stats := insights.NewService(ghClient, insightsTTL)
+
+	// Webhooks keep the cached insights fresh, GitHub recomputes statistics after pushes
+	var receiver *webhooks.Receiver
+	if secret := os.Getenv("GITHUB_WEBHOOK_SECRET"); secret != "" {
+		receiver = webhooks.NewReceiver(secret, webhooks.DefaultDeliveryTTL)
+		receiver.OnPush(func(ctx context.Context, event *github.PushEvent) error {
+			stats.Invalidate(event.GetRepo().GetName())
+			return nil
+		})
+		receiver.OnRepository(func(ctx context.Context, event *github.RepositoryEvent) error {
+			stats.Invalidate(event.GetRepo().GetName())
+			if from := event.GetChanges().GetRepo().GetName().GetFrom(); from != "" {
+				stats.Invalidate(from)
+			}
+			return nil
+		})
+	}
+
+	syncer := reposync.NewSyncer(ghClient, githubapi.MaxConcurrentRequests)
+
 	router := gin.Default()
+	// Branch names can hold slashes, which are sent encoded as %2F
+	router.UseRawPath = true
+
+	// Rate limited responses tell when GitHub accepts requests again
+	router.Use(func(c *gin.Context) {
+		c.Writer = &retryAfterWriter{ResponseWriter: c.Writer, ghClient: ghClient}
+		c.Next()
+	})
 
 	// Create repo
 	router.POST("/repos", func(c *gin.Context) {
 		var req struct {
-			Name string `json:"name"`
+			githubapi.CreateRepoOptions
+			Blueprint string `json:"blueprint"`
 		}
 		if err := c.BindJSON(&req); err != nil || req.Name == "" {
 			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+
+		// The blueprint's settings count when validating the request
+		var bp *blueprint.Blueprint
+		if req.Blueprint != "" {
+			var ok bool
+			bp, ok = blueprints.Get(req.Blueprint)
+			if !ok {
+				c.JSON(400, gin.H{"error": "unknown blueprint"})
+				return
+			}
+			if bp.AutoInit {
+				req.AutoInit = true
+			}
 		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if bp != nil {
+			result, err := blueprint.Apply(c.Request.Context(), ghClient, req.CreateRepoOptions, bp)
+			if err != nil {
+				c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error(), "steps": result.Steps, "rolled_back": result.RolledBack})
+				return
+			}
 
-		repo, err := ghClient.CreateRepo(context.Background(), req.Name)
+			c.JSON(201, gin.H{
+				"message":    "Repository created",
+				"name":       req.Name,
+				"blueprint":  bp.Name,
+				"steps":      result.Steps,
+				"repository": githubapi.NewRepository(result.Repository),
+			})
+			return
+		}
+
+		repo, err := ghClient.CreateRepoWithOptions(c.Request.Context(), req.CreateRepoOptions)
+		if errors.Is(err, githubapi.ErrInternalOutsideOrg) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Repository created", "name": *repo.Name, "repository": githubapi.NewRepository(repo)})
+	})
+
+	// Create repo from a template repo
+	router.POST("/repos/from-template", func(c *gin.Context) {
+		var req githubapi.TemplateRepoOptions
+		if err := c.BindJSON(&req); err != nil || req.Name == "" {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		repo, err := ghClient.CreateRepoFromTemplate(c.Request.Context(), req)
+		if errors.Is(err, githubapi.ErrTemplateNotReady) && repo != nil {
+			// The repo exists, GitHub is still copying the template into it
+			c.JSON(202, gin.H{
+				"message":    err.Error(),
+				"name":       repo.GetName(),
+				"template":   req.TemplateOwner + "/" + req.TemplateRepo,
+				"repository": githubapi.NewRepository(repo),
+			})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(201, gin.H{"message": "Repository created", "name": *repo.Name})
+		c.JSON(201, gin.H{
+			"message":    "Repository created from template",
+			"name":       *repo.Name,
+			"template":   req.TemplateOwner + "/" + req.TemplateRepo,
+			"repository": githubapi.NewRepository(repo),
+		})
 	})
 
 	// Delete repo
@@ -45,32 +288,229 @@ func main() {
 			return
 		}
 
+		soft := softDeleteDefault
+		if value := c.Query("soft"); value != "" {
+			var err error
+			soft, err = strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid value for soft"})
+				return
+			}
+		}
+
+		dryRun := false
+		if value := c.Query("dry_run"); value != "" {
+			var err error
+			dryRun, err = strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid value for dry_run"})
+				return
+			}
+		}
+
+		if dryRun {
+			report, err := guard.DryRun(c.Request.Context(), name)
+			if err != nil {
+				c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+				return
+			}
+
+			c.JSON(200, gin.H{"dry_run": true, "repo": name, "soft": soft, "would_delete": report})
+			return
+		}
+
+		if guard.Protected(name) {
+			c.JSON(403, gin.H{"error": safeguard.ErrProtected.Error()})
+			return
+		}
+
+		// The first request only hands out a token, repeating it with the token deletes
+		format := c.Query("backup")
+		token := c.Query("confirm")
+		if guard.ConfirmationRequired() {
+			if token == "" {
+				token, expiresAt, err := guard.IssueToken(name, soft, format)
+				if err != nil {
+					c.JSON(500, gin.H{"error": err.Error()})
+					return
+				}
+
+				c.JSON(428, gin.H{
+					"message":            "Repeat the request with ?confirm=<confirmation_token> to delete the repository",
+					"repo":               name,
+					"confirmation_token": token,
+					"expires_at":         expiresAt,
+				})
+				return
+			}
+			err := guard.Confirm(name, token, soft, format)
+			if errors.Is(err, safeguard.ErrTokenInUse) {
+				c.JSON(409, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(403, gin.H{"error": err.Error()})
+				return
+			}
+			// Unless the deletion goes through and consumes it
+			defer guard.Release(token)
+		}
+
+		// Back up first, a failed backup keeps the repository around
+		backupLocation := ""
+		if format != "" {
+			if backups == nil {
+				c.JSON(503, gin.H{"error": "backups are not configured"})
+				return
+			}
+
+			manifest, err := backups.Run(c.Request.Context(), name, format)
+			if errors.Is(err, backup.ErrInvalidFormat) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(githubapi.HTTPStatus(err), gin.H{"error": "backup failed: " + err.Error()})
+				return
+			}
+			backupLocation = manifest.Location
+		}
+
+		// Archive now and delete once the grace period is over
+		if soft {
+			entry, err := trash.SoftDelete(c.Request.Context(), name)
+			if errors.Is(err, softdelete.ErrAlreadyPending) {
+				c.JSON(409, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+				return
+			}
+
+			guard.Consume(token)
+
+			response := gin.H{"message": "Repository scheduled for deletion", "repo": name, "purge_at": entry.PurgeAt}
+			if backupLocation != "" {
+				response["backup"] = backupLocation
+			}
+			c.JSON(202, response)
+			return
+		}
+
 		err := ghClient.DeleteRepo(context.Background(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		guard.Consume(token)
+
+		// The repo is gone, so there's nothing left to reap
+		if err := trash.Forget(name); err != nil {
+			log.Printf("Failed to forget pending deletion of %s: %v", name, err)
+		}
+
+		response := gin.H{"message": "Repository deleted", "repo": name}
+		if backupLocation != "" {
+			response["backup"] = backupLocation
+		}
+		c.JSON(200, response)
+	})
+
+	// List backups, optionally for one repo
+	router.GET("/backups", func(c *gin.Context) {
+		if backups == nil {
+			c.JSON(503, gin.H{"error": "backups are not configured"})
+			return
+		}
+
+		manifests, err := backups.List(c.Request.Context(), c.Query("repo"))
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(200, gin.H{"message": "Repository deleted", "repo": name})
+		c.JSON(200, gin.H{"backups": manifests, "count": len(manifests)})
+	})
+
+	// Restore a soft deleted repo
+	router.POST("/repos/:name/restore", func(c *gin.Context) {
+		name := c.Param("name")
+
+		err := trash.Restore(c.Request.Context(), name)
+		if errors.Is(err, softdelete.ErrNotPending) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, softdelete.ErrRestoreExpired) {
+			c.JSON(410, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Repository restored", "repo": name})
 	})
 
-	// List all repos
+	// List all repos, optionally filtered and sorted
 	router.GET("/repos", func(c *gin.Context) {
-		repos, err := ghClient.ListRepos(context.Background())
+		filter, err := githubapi.ParseRepoFilter(c.Request.URL.Query())
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		page, paginated, err := githubapi.ParsePageRequest(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		var repos []githubapi.Repository
+		var nextCursor string
+		if paginated {
+			repos, nextCursor, err = ghClient.ListRepositoriesPage(c.Request.Context(), filter, page)
+		} else {
+			repos, err = ghClient.ListRepositories(c.Request.Context(), filter)
+		}
+		if errors.Is(err, githubapi.ErrInvalidCursor) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		// todo extract only the names for now
-		var repoNames []string
-		for _, repo := range repos {
-			if repo.Name != nil {
-				repoNames = append(repoNames, *repo.Name)
+		response := gin.H{}
+		if paginated {
+			setNextCursor(c, response, nextCursor)
+		}
+
+		fields := c.Query("fields")
+		switch fields {
+		case "":
+			// Only the names unless fields are requested
+			var repoNames []string
+			for _, repo := range repos {
+				repoNames = append(repoNames, repo.Name)
 			}
+			response["repositories"] = repoNames
+		case "all":
+			response["repositories"] = repos
+		default:
+			selected, err := githubapi.SelectFields(repos, strings.Split(fields, ","))
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			response["repositories"] = selected
 		}
 
-		c.JSON(200, gin.H{"repositories": repoNames})
+		c.JSON(200, response)
 	})
 
 	// List N open pull requests for a repo
@@ -81,6 +521,49 @@ func main() {
 			return
 		}
 
+		filter, err := githubapi.ParsePullRequestFilter(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		view, err := githubapi.ParsePullRequestView(c.Query("view"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		page, paginated, err := githubapi.ParsePageRequest(c.Request.URL.Query())
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if paginated {
+			if c.Query("n") != "" {
+				c.JSON(400, gin.H{"error": "n cannot be combined with page_size or cursor"})
+				return
+			}
+
+			prs, nextCursor, err := ghClient.ListPullRequestsPage(c.Request.Context(), name, filter, page)
+			if errors.Is(err, githubapi.ErrInvalidCursor) {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+				return
+			}
+
+			response := gin.H{
+				"schema_version": githubapi.PullRequestSchemaVersion,
+				"repository":     name,
+				"pull_requests":  view.Render(prs),
+				"count":          len(prs),
+			}
+			setNextCursor(c, response, nextCursor)
+			c.JSON(200, response)
+			return
+		}
+
 		// Default is -1 means no limit
 		nStr := c.DefaultQuery("n", "-1")
 		n, err := strconv.Atoi(nStr)
@@ -89,19 +572,960 @@ func main() {
 			return
 		}
 
-		prs, err := ghClient.ListPullRequests(context.Background(), name, n)
+		prs, err := ghClient.ListPullRequestsWithFilter(c.Request.Context(), name, filter, n)
 		if err != nil {
-			c.JSON(500, gin.H{"error": err.Error()})
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(200, gin.H{"reposiroty": name, "pull_requests": prs, "count": len(prs)})
+		c.JSON(200, gin.H{
+			"schema_version": githubapi.PullRequestSchemaVersion,
+			"repository":     name,
+			"pull_requests":  view.Render(prs),
+			"count":          len(prs),
+		})
 	})
 
-	// Start server
-	port := ":8080"
-	fmt.Printf("Server running on port http://localhost%s\n", port)
-	if err := router.Run(port); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	// Get a pull request with its reviews, checks and files
+	router.GET("/repos/:name/pulls/:number", func(c *gin.Context) {
+		name := c.Param("name")
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidPullNumber.Error()})
+			return
+		}
+
+		detail, err := ghClient.GetPullRequestDetail(c.Request.Context(), name, number)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{
+			"schema_version": githubapi.PullRequestSchemaVersion,
+			"repository":     name,
+			"pull_request":   detail,
+		})
+	})
+
+	// Open a pull request
+	router.POST("/repos/:name/pulls", func(c *gin.Context) {
+		name := c.Param("name")
+
+		var req githubapi.CreatePullRequestOptions
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		pr, err := ghClient.CreatePullRequest(c.Request.Context(), name, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Pull request created", "repository": name, "pull_request": githubapi.NewPullRequest(pr)})
+	})
+
+	// Edit a pull request
+	router.PATCH("/repos/:name/pulls/:number", func(c *gin.Context) {
+		name := c.Param("name")
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidPullNumber.Error()})
+			return
+		}
+
+		var req githubapi.UpdatePullRequestOptions
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		pr, err := ghClient.UpdatePullRequest(c.Request.Context(), name, number, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Pull request updated", "repository": name, "pull_request": githubapi.NewPullRequest(pr)})
+	})
+
+	// Merge a pull request
+	router.PUT("/repos/:name/pulls/:number/merge", func(c *gin.Context) {
+		name := c.Param("name")
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidPullNumber.Error()})
+			return
+		}
+
+		// The body is optional, an empty one merges with the default method
+		var req githubapi.MergePullRequestOptions
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := ghClient.MergePullRequest(c.Request.Context(), name, number, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": result.GetMessage(), "repository": name, "number": number, "merged": result.GetMerged(), "sha": result.GetSHA()})
+	})
+
+	// Close a pull request
+	router.POST("/repos/:name/pulls/:number/close", func(c *gin.Context) {
+		name := c.Param("name")
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidPullNumber.Error()})
+			return
+		}
+
+		pr, err := ghClient.ClosePullRequest(c.Request.Context(), name, number)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Pull request closed", "repository": name, "pull_request": githubapi.NewPullRequest(pr)})
+	})
+
+	// Reopen a pull request
+	router.POST("/repos/:name/pulls/:number/reopen", func(c *gin.Context) {
+		name := c.Param("name")
+		number, err := strconv.Atoi(c.Param("number"))
+		if err != nil || number < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidPullNumber.Error()})
+			return
+		}
+
+		pr, err := ghClient.ReopenPullRequest(c.Request.Context(), name, number)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Pull request reopened", "repository": name, "pull_request": githubapi.NewPullRequest(pr)})
+	})
+
+	// List the collaborators of a repo
+	router.GET("/repos/:name/collaborators", func(c *gin.Context) {
+		name := c.Param("name")
+
+		users, err := ghClient.ListCollaborators(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		collaborators := make([]githubapi.Collaborator, 0, len(users))
+		for _, user := range users {
+			collaborators = append(collaborators, githubapi.NewCollaborator(user))
+		}
+		c.JSON(200, gin.H{"repository": name, "collaborators": collaborators, "count": len(collaborators)})
+	})
+
+	// Invite a collaborator, or change the permission of an existing one
+	router.PUT("/repos/:name/collaborators/:user", func(c *gin.Context) {
+		name, user := c.Param("name"), c.Param("user")
+
+		// The body is optional, GitHub grants push by default
+		req := struct {
+			Permission string `json:"permission"`
+		}{Permission: "push"}
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+
+		invitation, err := ghClient.SetCollaborator(c.Request.Context(), name, user, req.Permission)
+		if errors.Is(err, githubapi.ErrInvalidPermission) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		if invitation != nil {
+			c.JSON(201, gin.H{"message": "Invitation sent", "repository": name, "invitation": githubapi.NewInvitation(invitation)})
+			return
+		}
+		c.JSON(200, gin.H{"message": "Collaborator updated", "repository": name, "collaborator": githubapi.Collaborator{Login: user, Permission: req.Permission}})
+	})
+
+	// Remove a collaborator
+	router.DELETE("/repos/:name/collaborators/:user", func(c *gin.Context) {
+		name, user := c.Param("name"), c.Param("user")
+
+		if err := ghClient.RemoveCollaborator(c.Request.Context(), name, user); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Collaborator removed", "repository": name, "user": user})
+	})
+
+	// List pending invitations to a repo
+	router.GET("/repos/:name/invitations", func(c *gin.Context) {
+		name := c.Param("name")
+
+		pending, err := ghClient.ListInvitations(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		invitations := make([]githubapi.Invitation, 0, len(pending))
+		for _, invitation := range pending {
+			invitations = append(invitations, githubapi.NewInvitation(invitation))
+		}
+		c.JSON(200, gin.H{"repository": name, "invitations": invitations, "count": len(invitations)})
+	})
+
+	// Change the permission of a pending invitation
+	router.PATCH("/repos/:name/invitations/:id", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidInvitation.Error()})
+			return
+		}
+
+		var req struct {
+			Permission string `json:"permission"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+
+		invitation, err := ghClient.UpdateInvitation(c.Request.Context(), name, id, req.Permission)
+		if errors.Is(err, githubapi.ErrInvalidPermission) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Invitation updated", "repository": name, "invitation": githubapi.NewInvitation(invitation)})
+	})
+
+	// Cancel a pending invitation
+	router.DELETE("/repos/:name/invitations/:id", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidInvitation.Error()})
+			return
+		}
+
+		if err := ghClient.DeleteInvitation(c.Request.Context(), name, id); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Invitation cancelled", "repository": name, "id": id})
+	})
+
+	// List the teams with access to a repo
+	router.GET("/repos/:name/teams", func(c *gin.Context) {
+		name := c.Param("name")
+
+		teams, err := ghClient.ListTeams(c.Request.Context(), name)
+		if errors.Is(err, githubapi.ErrTeamsOutsideOrg) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		access := make([]githubapi.TeamAccess, 0, len(teams))
+		for _, team := range teams {
+			access = append(access, githubapi.NewTeamAccess(team))
+		}
+		c.JSON(200, gin.H{"repository": name, "teams": access, "count": len(access)})
+	})
+
+	// Grant a team access to a repo, or change its permission
+	router.PUT("/repos/:name/teams/:team", func(c *gin.Context) {
+		name, team := c.Param("name"), c.Param("team")
+
+		var req struct {
+			Permission string `json:"permission"`
+		}
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+
+		err := ghClient.AddTeamRepo(c.Request.Context(), name, team, req.Permission)
+		if errors.Is(err, githubapi.ErrInvalidPermission) || errors.Is(err, githubapi.ErrTeamsOutsideOrg) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Team access granted", "repository": name, "team": githubapi.TeamAccess{Slug: team, Permission: req.Permission}})
+	})
+
+	// Revoke a team's access to a repo
+	router.DELETE("/repos/:name/teams/:team", func(c *gin.Context) {
+		name, team := c.Param("name"), c.Param("team")
+
+		err := ghClient.RemoveTeamRepo(c.Request.Context(), name, team)
+		if errors.Is(err, githubapi.ErrTeamsOutsideOrg) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Team access revoked", "repository": name, "team": team})
+	})
+
+	// List the branches of a repo
+	router.GET("/repos/:name/branches", func(c *gin.Context) {
+		name := c.Param("name")
+
+		branches, err := ghClient.ListBranches(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		converted := make([]githubapi.Branch, 0, len(branches))
+		for _, branch := range branches {
+			converted = append(converted, githubapi.NewBranch(branch))
+		}
+		c.JSON(200, gin.H{"repository": name, "branches": converted, "count": len(converted)})
+	})
+
+	// Create a branch from a branch, tag or commit
+	router.POST("/repos/:name/branches", func(c *gin.Context) {
+		name := c.Param("name")
+
+		var req githubapi.CreateBranchOptions
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		branch, err := ghClient.CreateBranch(c.Request.Context(), name, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Branch created", "repository": name, "branch": githubapi.NewBranch(branch)})
+	})
+
+	// Delete a branch
+	router.DELETE("/repos/:name/branches/:branch", func(c *gin.Context) {
+		name, branch := c.Param("name"), c.Param("branch")
+
+		if err := ghClient.DeleteBranch(c.Request.Context(), name, branch); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Branch deleted", "repository": name, "branch": branch})
+	})
+
+	// Get the protection rules of a branch
+	router.GET("/repos/:name/branches/:branch/protection", func(c *gin.Context) {
+		name, branch := c.Param("name"), c.Param("branch")
+
+		protection, err := ghClient.GetBranchProtection(c.Request.Context(), name, branch)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"repository": name, "branch": branch, "protection": githubapi.NewBranchProtection(protection)})
+	})
+
+	// Replace the protection rules of a branch
+	router.PUT("/repos/:name/branches/:branch/protection", func(c *gin.Context) {
+		name, branch := c.Param("name"), c.Param("branch")
+
+		var req githubapi.BranchProtection
+		if err := c.BindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		protection, err := ghClient.UpdateBranchProtection(c.Request.Context(), name, branch, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Branch protection updated", "repository": name, "branch": branch, "protection": githubapi.NewBranchProtection(protection)})
+	})
+
+	// Remove the protection of a branch
+	router.DELETE("/repos/:name/branches/:branch/protection", func(c *gin.Context) {
+		name, branch := c.Param("name"), c.Param("branch")
+
+		if err := ghClient.RemoveBranchProtection(c.Request.Context(), name, branch); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Branch protection removed", "repository": name, "branch": branch})
+	})
+
+	// List the webhooks of a repo
+	router.GET("/repos/:name/hooks", func(c *gin.Context) {
+		name := c.Param("name")
+
+		listed, err := ghClient.ListHooks(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		hooks := make([]githubapi.RepoHook, 0, len(listed))
+		for _, hook := range listed {
+			hooks = append(hooks, githubapi.NewRepoHook(hook))
+		}
+		c.JSON(200, gin.H{"repository": name, "hooks": hooks, "count": len(hooks)})
+	})
+
+	// Add a webhook to a repo
+	router.POST("/repos/:name/hooks", func(c *gin.Context) {
+		name := c.Param("name")
+
+		var req githubapi.Hook
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		hook, err := ghClient.CreateHook(c.Request.Context(), name, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, gin.H{"message": "Webhook created", "repository": name, "hook": githubapi.NewRepoHook(hook)})
+	})
+
+	// Get a webhook
+	router.GET("/repos/:name/hooks/:id", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidHookID.Error()})
+			return
+		}
+
+		hook, err := ghClient.GetHook(c.Request.Context(), name, id)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"repository": name, "hook": githubapi.NewRepoHook(hook)})
+	})
+
+	// Change the fields given of a webhook
+	router.PATCH("/repos/:name/hooks/:id", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidHookID.Error()})
+			return
+		}
+
+		var req githubapi.HookUpdate
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := req.Validate(); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		hook, err := ghClient.UpdateHook(c.Request.Context(), name, id, req)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Webhook updated", "repository": name, "hook": githubapi.NewRepoHook(hook)})
+	})
+
+	// Delete a webhook
+	router.DELETE("/repos/:name/hooks/:id", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidHookID.Error()})
+			return
+		}
+
+		if err := ghClient.DeleteHook(c.Request.Context(), name, id); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Webhook deleted", "repository": name, "id": id})
+	})
+
+	// Send a ping event to a webhook
+	router.POST("/repos/:name/hooks/:id/test", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidHookID.Error()})
+			return
+		}
+
+		if err := ghClient.PingHook(c.Request.Context(), name, id); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"message": "Ping sent", "repository": name, "id": id})
+	})
+
+	// Recent deliveries of a webhook, most recent first
+	router.GET("/repos/:name/hooks/:id/deliveries", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidHookID.Error()})
+			return
+		}
+		size := githubapi.DefaultPageSize
+		if value := c.Query("page_size"); value != "" {
+			size, err = strconv.Atoi(value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": githubapi.ErrInvalidPageSize.Error()})
+				return
+			}
+		}
+
+		listed, next, err := ghClient.ListHookDeliveries(c.Request.Context(), name, id, c.Query("cursor"), size)
+		if errors.Is(err, githubapi.ErrInvalidPageSize) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		deliveries := make([]githubapi.HookDelivery, 0, len(listed))
+		for _, delivery := range listed {
+			deliveries = append(deliveries, githubapi.NewHookDelivery(delivery))
+		}
+		response := gin.H{"repository": name, "hook_id": id, "deliveries": deliveries, "count": len(deliveries)}
+		setNextCursor(c, response, next)
+		c.JSON(200, response)
+	})
+
+	// Send a past delivery again
+	router.POST("/repos/:name/hooks/:id/deliveries/:delivery/redeliver", func(c *gin.Context) {
+		name := c.Param("name")
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil || id < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidHookID.Error()})
+			return
+		}
+		deliveryID, err := strconv.ParseInt(c.Param("delivery"), 10, 64)
+		if err != nil || deliveryID < 1 {
+			c.JSON(400, gin.H{"error": githubapi.ErrInvalidDeliveryID.Error()})
+			return
+		}
+
+		if err := ghClient.RedeliverHookDelivery(c.Request.Context(), name, id, deliveryID); err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(202, gin.H{"message": "Redelivery requested", "repository": name, "hook_id": id, "delivery_id": deliveryID})
+	})
+
+	// List the labels of a repo
+	router.GET("/repos/:name/labels", func(c *gin.Context) {
+		name := c.Param("name")
+
+		listed, err := ghClient.ListLabels(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		labels := make([]githubapi.Label, 0, len(listed))
+		for _, label := range listed {
+			labels = append(labels, githubapi.NewLabel(label))
+		}
+		c.JSON(200, gin.H{"repository": name, "labels": labels, "count": len(labels)})
+	})
+
+	// Make the labels of a repo match the given set
+	router.PUT("/repos/:name/labels", func(c *gin.Context) {
+		name := c.Param("name")
+		opts, err := parseSyncOptions(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req struct {
+			Labels []githubapi.Label `json:"labels" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := reposync.ValidateLabels(req.Labels); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := syncer.Labels(c.Request.Context(), name, req.Labels, opts)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, result)
+	})
+
+	// Make the labels of every repo match the given set
+	router.POST("/labels/sync", func(c *gin.Context) {
+		opts, err := parseSyncOptions(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req struct {
+			Labels []githubapi.Label `json:"labels" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := reposync.ValidateLabels(req.Labels); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		results, err := syncer.AllLabels(c.Request.Context(), req.Labels, opts)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"dry_run": opts.DryRun, "results": results, "count": len(results), "failed": reposync.Failed(results)})
+	})
+
+	// List the open and closed milestones of a repo
+	router.GET("/repos/:name/milestones", func(c *gin.Context) {
+		name := c.Param("name")
+
+		listed, err := ghClient.ListMilestones(c.Request.Context(), name)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		milestones := make([]githubapi.Milestone, 0, len(listed))
+		for _, milestone := range listed {
+			milestones = append(milestones, githubapi.NewMilestone(milestone))
+		}
+		c.JSON(200, gin.H{"repository": name, "milestones": milestones, "count": len(milestones)})
+	})
+
+	// Make the milestones of a repo match the given set
+	router.PUT("/repos/:name/milestones", func(c *gin.Context) {
+		name := c.Param("name")
+		opts, err := parseSyncOptions(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req struct {
+			Milestones []githubapi.Milestone `json:"milestones" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := reposync.ValidateMilestones(req.Milestones); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		result, err := syncer.Milestones(c.Request.Context(), name, req.Milestones, opts)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, result)
+	})
+
+	// Make the milestones of every repo match the given set
+	router.POST("/milestones/sync", func(c *gin.Context) {
+		opts, err := parseSyncOptions(c)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req struct {
+			Milestones []githubapi.Milestone `json:"milestones" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": "invalid request"})
+			return
+		}
+		if err := reposync.ValidateMilestones(req.Milestones); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		results, err := syncer.AllMilestones(c.Request.Context(), req.Milestones, opts)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, gin.H{"dry_run": opts.DryRun, "results": results, "count": len(results), "failed": reposync.Failed(results)})
+	})
+
+	// Languages, contributors, activity and traffic of a repo
+	router.GET("/repos/:name/insights", func(c *gin.Context) {
+		name := c.Param("name")
+
+		refresh := false
+		if value := c.Query("refresh"); value != "" {
+			var err error
+			refresh, err = strconv.ParseBool(value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid value for refresh"})
+				return
+			}
+		}
+
+		result, err := stats.Get(c.Request.Context(), name, refresh)
+		if errors.Is(err, githubapi.ErrStatsPending) {
+			c.Header("Retry-After", "10")
+			c.JSON(202, gin.H{"message": "Statistics are being computed, retry later", "repository": name})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, result)
+	})
+
+	// Pull requests across every repo, or the repos matching the repo filters
+	router.GET("/pulls", func(c *gin.Context) {
+		query := c.Request.URL.Query()
+		filter, err := githubapi.ParsePullRequestFilter(query)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		// sort and direction are the pull requests', which are always sorted by update time
+		query.Del("sort")
+		query.Del("direction")
+		repoFilter, err := githubapi.ParseRepoFilter(query)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		prs, searched, err := ghClient.ListOwnerPullRequests(c.Request.Context(), repoFilter, filter)
+		if errors.Is(err, githubapi.ErrOwnerPullsSort) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		source := "repos"
+		if searched {
+			source = "search"
+		}
+		c.JSON(200, gin.H{
+			"schema_version": githubapi.PullRequestSchemaVersion,
+			"source":         source,
+			"pull_requests":  prs,
+			"repositories":   githubapi.CountByRepository(prs),
+			"count":          len(prs),
+		})
+	})
+
+	// Open pull requests not updated in the last days, across every repo
+	router.GET("/reports/stale-pulls", func(c *gin.Context) {
+		days := reports.DefaultStaleDays
+		if value := c.Query("days"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 1 {
+				c.JSON(400, gin.H{"error": reports.ErrInvalidDays.Error()})
+				return
+			}
+			days = parsed
+		}
+		format := c.DefaultQuery("format", "json")
+		if format != "json" && format != "csv" {
+			c.JSON(400, gin.H{"error": "format must be json or csv"})
+			return
+		}
+
+		report, err := reporter.StalePulls(c.Request.Context(), days)
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		if format == "csv" {
+			var buf bytes.Buffer
+			if err := report.WriteCSV(&buf); err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			c.Header("Content-Disposition", `attachment; filename="stale-pulls.csv"`)
+			c.Data(200, "text/csv; charset=utf-8", buf.Bytes())
+			return
+		}
+		c.JSON(200, report)
+	})
+
+	// Receive GitHub webhook deliveries
+	router.POST("/webhooks/github", func(c *gin.Context) {
+		if receiver == nil {
+			c.JSON(503, gin.H{"error": "webhooks are not configured"})
+			return
+		}
+
+		delivery, err := receiver.Receive(c.Request.Context(), c.Request)
+		switch {
+		case errors.Is(err, webhooks.ErrInvalidSignature):
+			c.JSON(401, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, webhooks.ErrDuplicateDelivery):
+			// Answered as a success so that GitHub doesn't retry it
+			c.JSON(200, gin.H{"message": "Delivery already received", "delivery": delivery.ID})
+			return
+		case errors.Is(err, webhooks.ErrInvalidPayload), errors.Is(err, webhooks.ErrMissingDelivery):
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, delivery)
+	})
+
+	// Remaining GitHub API quota of each resource, checking it doesn't count against it
+	router.GET("/ratelimit", func(c *gin.Context) {
+		limits, err := ghClient.RateLimits(c.Request.Context())
+		if err != nil {
+			c.JSON(githubapi.HTTPStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, limits)
+	})
+
+	// Start server
+	port := ":8080"
+	fmt.Printf("Server running on port http://localhost%s\n", port)
+	if err := router.Run(port); err != nil {
+		log.Fatalf("Failed to start server: %v", err)
+	}
+}
+
+// setNextCursor adds the next page cursor to the response body and Link header
+func setNextCursor(c *gin.Context, response gin.H, nextCursor string) {
+	if nextCursor == "" {
+		response["next_cursor"] = nil
+		return
+	}
+	response["next_cursor"] = nextCursor
+	c.Header("Link", githubapi.NextLink(c.Request.URL, nextCursor))
+}
+
+// parseSyncOptions reads the prune and dry_run query parameters
+func parseSyncOptions(c *gin.Context) (reposync.Options, error) {
+	var opts reposync.Options
+	for param, target := range map[string]*bool{"prune": &opts.Prune, "dry_run": &opts.DryRun} {
+		if value := c.Query(param); value != "" {
+			parsed, err := strconv.ParseBool(value)
+			if err != nil {
+				return opts, fmt.Errorf("invalid value for %s", param)
+			}
+			*target = parsed
+		}
+	}
+	return opts, nil
+}
+
+// retryAfterWriter sets Retry-After on rate limited responses that don't have one
+type retryAfterWriter struct {
+	gin.ResponseWriter
+	ghClient *githubapi.Client
+}
+
+func (w *retryAfterWriter) WriteHeader(code int) {
+	if code == 429 && w.Header().Get("Retry-After") == "" {
+		seconds := int(math.Ceil(w.ghClient.RetryAfter().Seconds()))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
 	}
+	w.ResponseWriter.WriteHeader(code)
 }
@@ -0,0 +1,280 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const (
+	FormatTarball = "tarball"
+	FormatZipball = "zipball"
+	FormatMirror  = "mirror" // git clone --mirror, stored as a git bundle
+)
+
+var (
+	ErrInvalidFormat = githubapi.Error("backup must be tarball, zipball or mirror")
+	// The distroless image doesn't ship git
+	ErrMirrorUnavailable = fmt.Errorf("mirror backups need git on the server: %w", githubapi.ErrNotImplemented)
+)
+
+// Manifest describes a finished backup, it's written last so a backup
+// without one is incomplete
+type Manifest struct {
+	Repo      string    `json:"repo"`
+	Format    string    `json:"format"`
+	CreatedAt time.Time `json:"created_at"`
+	Location  string    `json:"location"`
+	Files     []string  `json:"files"`
+}
+
+const manifestFile = "manifest.json"
+
+var archiveFiles = map[string]string{
+	FormatTarball: "repository.tar.gz",
+	FormatZipball: "repository.zip",
+	FormatMirror:  "repository.bundle",
+}
+
+// Service backs up a repository's code and metadata to a Storage
+type Service struct {
+	client  *githubapi.Client // Its token clones private repositories for mirror backups
+	storage Storage
+}
+
+func NewService(client *githubapi.Client, storage Storage) *Service {
+	return &Service{client: client, storage: storage}
+}
+
+// Run backs up the repository, every artifact goes under <repo>/<timestamp>/
+func (s *Service) Run(ctx context.Context, repoName, format string) (Manifest, error) {
+	if _, ok := archiveFiles[format]; !ok {
+		return Manifest{}, ErrInvalidFormat
+	}
+	if format == FormatMirror {
+		if _, err := exec.LookPath("git"); err != nil {
+			return Manifest{}, ErrMirrorUnavailable
+		}
+	}
+
+	now := time.Now().UTC()
+	prefix := repoName + "/" + now.Format("20060102T150405Z") + "/"
+	manifest := Manifest{
+		Repo:      repoName,
+		Format:    format,
+		CreatedAt: now,
+		Location:  s.storage.Location(prefix),
+		Files:     []string{},
+	}
+
+	put := func(name string, body io.ReadSeeker) error {
+		if err := s.storage.Put(ctx, prefix+name, body); err != nil {
+			return fmt.Errorf("storing %s: %w", name, err)
+		}
+		manifest.Files = append(manifest.Files, name)
+		return nil
+	}
+
+	// Spool the code to disk, archives can be far bigger than we want in memory
+	code, err := s.fetchCode(ctx, repoName, format)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer os.Remove(code.Name())
+	defer code.Close()
+	if err := put(archiveFiles[format], code); err != nil {
+		return Manifest{}, err
+	}
+
+	issues, err := s.client.ListIssues(ctx, repoName)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("listing issues: %w", err)
+	}
+	labels, err := s.client.ListLabels(ctx, repoName)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("listing labels: %w", err)
+	}
+	releases, err := s.client.ListReleases(ctx, repoName)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("listing releases: %w", err)
+	}
+
+	// The issues listing includes pull requests, keep them apart
+	onlyIssues, pulls := []*github.Issue{}, []*github.Issue{}
+	for _, issue := range issues {
+		if issue.IsPullRequest() {
+			pulls = append(pulls, issue)
+		} else {
+			onlyIssues = append(onlyIssues, issue)
+		}
+	}
+
+	dumps := []struct {
+		name string
+		data interface{}
+	}{
+		{"issues.json", onlyIssues},
+		{"pulls.json", pulls},
+		{"labels.json", nonNil(labels)},
+		{"releases.json", nonNil(releases)},
+	}
+	for _, dump := range dumps {
+		raw, err := json.MarshalIndent(dump.data, "", "  ")
+		if err != nil {
+			return Manifest{}, err
+		}
+		if err := put(dump.name, bytes.NewReader(raw)); err != nil {
+			return Manifest{}, err
+		}
+	}
+
+	raw, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, err
+	}
+	if err := s.storage.Put(ctx, prefix+manifestFile, bytes.NewReader(raw)); err != nil {
+		return Manifest{}, fmt.Errorf("storing %s: %w", manifestFile, err)
+	}
+
+	return manifest, nil
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// fetchCode writes the repository's code to a temporary file, rewound for reading
+func (s *Service) fetchCode(ctx context.Context, repoName, format string) (*os.File, error) {
+	f, err := os.CreateTemp("", "octo-backup-*")
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatMirror {
+		err = s.mirror(ctx, repoName, f)
+	} else {
+		err = s.download(ctx, repoName, format, f)
+	}
+	if err == nil {
+		_, err = f.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+func (s *Service) download(ctx context.Context, repoName, format string, dst io.Writer) error {
+	archive, err := s.client.DownloadArchive(ctx, repoName, format)
+	if err != nil {
+		return fmt.Errorf("downloading %s: %w", format, err)
+	}
+	defer archive.Close()
+
+	_, err = io.Copy(dst, archive)
+	return err
+}
+
+// mirror clones every ref of the repository and packs them into a single git bundle
+func (s *Service) mirror(ctx context.Context, repoName string, dst *os.File) error {
+	repo, err := s.client.GetRepo(ctx, repoName)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "octo-mirror-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	// Pass the token through git's environment config so it never shows up in the process list
+	token, err := s.client.Token()
+	if err != nil {
+		return err
+	}
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if token != "" {
+		auth := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http.extraHeader",
+			"GIT_CONFIG_VALUE_0=Authorization: Basic "+auth,
+		)
+	}
+
+	mirrorDir := filepath.Join(dir, "repo.git")
+	bundle := filepath.Join(dir, "repo.bundle")
+	commands := [][]string{
+		{"clone", "--mirror", "--quiet", repo.GetCloneURL(), mirrorDir},
+		{"-C", mirrorDir, "bundle", "create", bundle, "--all"},
+	}
+	for _, args := range commands {
+		cmd := exec.CommandContext(ctx, "git", args...) // #nosec G204 -- arguments aren't passed through a shell
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	src, err := os.Open(bundle)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// List returns the finished backups, newest first, optionally only for one repository
+func (s *Service) List(ctx context.Context, repoName string) ([]Manifest, error) {
+	prefix := ""
+	if repoName != "" {
+		prefix = repoName + "/"
+	}
+
+	keys, err := s.storage.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []Manifest{}
+	for _, key := range keys {
+		if !strings.HasSuffix(key, "/"+manifestFile) {
+			continue
+		}
+
+		body, err := s.storage.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		var manifest Manifest
+		err = json.NewDecoder(body).Decode(&manifest)
+		body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", key, err)
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].CreatedAt.After(manifests[j].CreatedAt) })
+	return manifests, nil
+}
@@ -0,0 +1,147 @@
+package blueprint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+type StepResult struct {
+	Step   string `json:"step"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Result reports how each step of the blueprint went
+type Result struct {
+	Repository *github.Repository `json:"-"`
+	Steps      []StepResult       `json:"steps"`
+	RolledBack bool               `json:"rolled_back"`
+}
+
+type step struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// Apply creates the repository and runs every step of the blueprint in order.
+// If a step fails the repository is deleted so no half-provisioned repo is left behind.
+func Apply(ctx context.Context, client *githubapi.Client, opts githubapi.CreateRepoOptions, bp *Blueprint) (*Result, error) {
+	if bp.AutoInit {
+		opts.AutoInit = true
+	}
+
+	result := &Result{}
+	steps := []step{{
+		name: "create repository",
+		run: func(ctx context.Context) error {
+			repo, err := client.CreateRepoWithOptions(ctx, opts)
+			result.Repository = repo
+			return err
+		},
+	}}
+	steps = append(steps, bp.steps(client, opts.Name, result)...)
+
+	for i, s := range steps {
+		err := s.run(ctx)
+		if err == nil {
+			result.Steps = append(result.Steps, StepResult{Step: s.name, Status: StatusOK})
+			continue
+		}
+
+		result.Steps = append(result.Steps, StepResult{Step: s.name, Status: StatusFailed, Error: err.Error()})
+		for _, skipped := range steps[i+1:] {
+			result.Steps = append(result.Steps, StepResult{Step: skipped.name, Status: StatusSkipped})
+		}
+
+		// Nothing to roll back if the repository itself wasn't created, it can
+		// exist even though creating it failed
+		if result.Repository == nil {
+			return result, err
+		}
+
+		// Roll back even if the request was cancelled
+		if rbErr := client.DeleteRepo(context.WithoutCancel(ctx), opts.Name); rbErr != nil {
+			return result, fmt.Errorf("step %q failed: %w, and rolling back failed: %v", s.name, err, rbErr)
+		}
+		result.RolledBack = true
+		return result, fmt.Errorf("step %q failed: %w", s.name, err)
+	}
+
+	return result, nil
+}
+
+// steps lists the provisioning steps of the blueprint, after the repository exists
+func (b *Blueprint) steps(client *githubapi.Client, repoName string, result *Result) []step {
+	var steps []step
+
+	if b.Settings != nil {
+		settings := *b.Settings
+		steps = append(steps, step{"settings", func(ctx context.Context) error {
+			repo, err := client.UpdateRepoSettings(ctx, repoName, settings)
+			if err == nil {
+				result.Repository = repo
+			}
+			return err
+		}})
+	}
+
+	for _, file := range b.Files {
+		file := file
+		steps = append(steps, step{"file " + file.Path, func(ctx context.Context) error {
+			return client.CreateFile(ctx, repoName, file.Path, []byte(file.Content), file.Message)
+		}})
+	}
+
+	for _, label := range b.Labels {
+		label := label
+		steps = append(steps, step{"label " + label.Name, func(ctx context.Context) error {
+			_, err := client.CreateLabel(ctx, repoName, label)
+			return err
+		}})
+	}
+
+	for _, team := range b.Teams {
+		team := team
+		steps = append(steps, step{"team " + team.Team, func(ctx context.Context) error {
+			return client.AddTeamRepo(ctx, repoName, team.Team, team.Permission)
+		}})
+	}
+
+	for _, hook := range b.Webhooks {
+		hook := hook
+		steps = append(steps, step{"webhook " + hook.URL, func(ctx context.Context) error {
+			_, err := client.CreateHook(ctx, repoName, hook)
+			return err
+		}})
+	}
+
+	for _, rule := range b.BranchProtection {
+		rule := rule
+		name := "branch protection " + rule.Branch
+		if rule.Branch == "" {
+			name = "branch protection (default branch)"
+		}
+		steps = append(steps, step{name, func(ctx context.Context) error {
+			branch := rule.Branch
+			if branch == "" {
+				branch = result.Repository.GetDefaultBranch()
+			}
+			if branch == "" {
+				branch = "main"
+			}
+			_, err := client.UpdateBranchProtection(ctx, repoName, branch, rule.BranchProtection)
+			return err
+		}})
+	}
+
+	return steps
+}
@@ -0,0 +1,206 @@
+package blueprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"gopkg.in/yaml.v3"
+)
+
+// Blueprint describes how a new repository gets set up after it's created
+type Blueprint struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	AutoInit    bool                    `json:"auto_init"` // Start with a README commit
+	Settings    *githubapi.RepoSettings `json:"settings"`
+	Files       []File                  `json:"files"`
+	Labels      []githubapi.Label       `json:"labels"`
+	Teams       []TeamPermission        `json:"teams"`
+	Webhooks    []githubapi.Hook        `json:"webhooks"`
+
+	// Applied last so the other steps can still commit to the branch
+	BranchProtection []ProtectionRule `json:"branch_protection"`
+}
+
+// File is committed to the new repository, its content is either inline or
+// read from Source, relative to the blueprint directory
+type File struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+	Source  string `json:"source"`
+	Message string `json:"message"`
+}
+
+type TeamPermission struct {
+	Team       string `json:"team"`
+	Permission string `json:"permission"`
+}
+
+// ProtectionRule protects a branch, an empty branch means the default branch
+type ProtectionRule struct {
+	Branch string `json:"branch"`
+	githubapi.BranchProtection
+}
+
+// Validate checks the blueprint when it's loaded, so mistakes show up at startup
+// instead of halfway through provisioning a repo
+func (b *Blueprint) Validate() error {
+	if b.Name == "" {
+		return fmt.Errorf("blueprint name is required")
+	}
+
+	if b.Settings != nil {
+		if err := b.Settings.Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range b.Files {
+		if file.Path == "" {
+			return githubapi.ErrInvalidFilePath
+		}
+	}
+
+	for i := range b.Labels {
+		if err := b.Labels[i].Validate(); err != nil {
+			return fmt.Errorf("label %q: %w", b.Labels[i].Name, err)
+		}
+	}
+
+	for _, team := range b.Teams {
+		if team.Team == "" {
+			return fmt.Errorf("team name is required")
+		}
+		switch team.Permission {
+		case "pull", "triage", "push", "maintain", "admin":
+		default:
+			return fmt.Errorf("team %q: %w", team.Team, githubapi.ErrInvalidPermission)
+		}
+	}
+
+	for i := range b.Webhooks {
+		if err := b.Webhooks[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range b.BranchProtection {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("branch protection %q: %w", rule.Branch, err)
+		}
+	}
+
+	return nil
+}
+
+// Registry holds the blueprints available to POST /repos
+type Registry struct {
+	blueprints map[string]*Blueprint
+}
+
+func NewRegistry() *Registry {
+	return &Registry{blueprints: map[string]*Blueprint{}}
+}
+
+func (r *Registry) Add(bp *Blueprint) error {
+	if err := bp.Validate(); err != nil {
+		return err
+	}
+	if _, exists := r.blueprints[bp.Name]; exists {
+		return fmt.Errorf("duplicate blueprint %q", bp.Name)
+	}
+	r.blueprints[bp.Name] = bp
+	return nil
+}
+
+func (r *Registry) Get(name string) (*Blueprint, bool) {
+	bp, ok := r.blueprints[name]
+	return bp, ok
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.blueprints))
+	for name := range r.blueprints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadDir loads every .yaml, .yml and .json blueprint in the directory
+func LoadDir(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	registry := NewRegistry()
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+
+		bp, err := loadFile(dir, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("blueprint %s: %w", entry.Name(), err)
+		}
+		if err := registry.Add(bp); err != nil {
+			return nil, fmt.Errorf("blueprint %s: %w", entry.Name(), err)
+		}
+	}
+
+	return registry, nil
+}
+
+func loadFile(dir, name string) (*Blueprint, error) {
+	raw, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is a superset of JSON, so both go through the YAML parser and then
+	// through encoding/json to reuse the json tags
+	var doc interface{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	asJSON, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	bp := &Blueprint{}
+	decoder := json.NewDecoder(bytes.NewReader(asJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(bp); err != nil {
+		return nil, err
+	}
+
+	if bp.Name == "" {
+		bp.Name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	for i, file := range bp.Files {
+		if file.Source == "" {
+			continue
+		}
+		// Blueprints only read their own files, nothing else on the server
+		if !filepath.IsLocal(file.Source) {
+			return nil, fmt.Errorf("file %q: source must be a relative path inside the blueprint directory", file.Path)
+		}
+		content, err := os.ReadFile(filepath.Join(dir, file.Source))
+		if err != nil {
+			return nil, err
+		}
+		bp.Files[i].Content = string(content)
+	}
+
+	return bp, nil
+}
@@ -0,0 +1,247 @@
+package githubapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrInvalidPermission = Error("permission must be one of pull, triage, push, maintain or admin")
+	ErrTeamsOutsideOrg   = Error("teams are only available for organization repositories")
+	ErrInvalidInvitation = Error("invalid invitation id")
+)
+
+func validPermission(permission string) bool {
+	switch permission {
+	case "pull", "triage", "push", "maintain", "admin":
+		return true
+	}
+	return false
+}
+
+// Permissions from the strongest, GitHub reports every permission a role includes
+var permissionLevels = []string{"admin", "maintain", "push", "triage", "pull"}
+
+// normalizePermission turns GitHub's role names, which call pull read and push write, into permissions
+func normalizePermission(role string) string {
+	switch role {
+	case "read":
+		return "pull"
+	case "write":
+		return "push"
+	}
+	return role
+}
+
+// invitationPermission is the name invitations use for the permission
+func invitationPermission(permission string) string {
+	switch permission {
+	case "pull":
+		return "read"
+	case "push":
+		return "write"
+	}
+	return permission
+}
+
+// Collaborator is a user with access to the repository
+type Collaborator struct {
+	Login      string `json:"login"`
+	Permission string `json:"permission"`
+}
+
+func NewCollaborator(user *github.User) Collaborator {
+	permission := normalizePermission(user.GetRoleName())
+	if permission == "" {
+		for _, level := range permissionLevels {
+			if user.Permissions[level] {
+				permission = level
+				break
+			}
+		}
+	}
+	return Collaborator{Login: user.GetLogin(), Permission: permission}
+}
+
+// Invitation is a pending invitation to collaborate on the repository
+type Invitation struct {
+	ID         int64      `json:"id"`
+	Invitee    string     `json:"invitee"`
+	Inviter    string     `json:"inviter"`
+	Permission string     `json:"permission"`
+	CreatedAt  *time.Time `json:"created_at"`
+	Expired    bool       `json:"expired"`
+	HTMLURL    string     `json:"html_url"`
+}
+
+func NewInvitation(invitation *github.RepositoryInvitation) Invitation {
+	return Invitation{
+		ID:         invitation.GetID(),
+		Invitee:    invitation.GetInvitee().GetLogin(),
+		Inviter:    invitation.GetInviter().GetLogin(),
+		Permission: normalizePermission(invitation.GetPermissions()),
+		CreatedAt:  timestampPtr(invitation.CreatedAt),
+		Expired:    invitation.GetExpired(),
+		HTMLURL:    invitation.GetHTMLURL(),
+	}
+}
+
+// TeamAccess is an organization team's access to the repository
+type TeamAccess struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	Permission string `json:"permission"`
+}
+
+func NewTeamAccess(team *github.Team) TeamAccess {
+	return TeamAccess{Slug: team.GetSlug(), Name: team.GetName(), Permission: normalizePermission(team.GetPermission())}
+}
+
+func (r *RealGitHubClient) ListCollaboratorsForOwner(ctx context.Context, owner, repoName string) ([]*github.User, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.User, *github.Response, error) {
+		return r.gh.Repositories.ListCollaborators(ctx, owner, repoName, &github.ListCollaboratorsOptions{ListOptions: *opts})
+	})
+}
+
+// AddCollaboratorForOwner invites the user, or changes the permission of an existing
+// collaborator in which case there's no invitation
+func (r *RealGitHubClient) AddCollaboratorForOwner(ctx context.Context, owner, repoName, user, permission string) (*github.RepositoryInvitation, error) {
+	invitation, resp, err := r.gh.Repositories.AddCollaborator(ctx, owner, repoName, user, &github.RepositoryAddCollaboratorOptions{Permission: permission})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if resp.StatusCode == http.StatusNoContent {
+		return nil, nil
+	}
+
+	return &github.RepositoryInvitation{
+		ID:          invitation.ID,
+		Invitee:     invitation.Invitee,
+		Inviter:     invitation.Inviter,
+		Permissions: invitation.Permissions,
+		CreatedAt:   invitation.CreatedAt,
+		HTMLURL:     invitation.HTMLURL,
+	}, nil
+}
+
+func (r *RealGitHubClient) RemoveCollaboratorForOwner(ctx context.Context, owner, repoName, user string) error {
+	_, err := r.gh.Repositories.RemoveCollaborator(ctx, owner, repoName, user)
+	return wrapError(err)
+}
+
+func (r *RealGitHubClient) ListInvitationsForOwner(ctx context.Context, owner, repoName string) ([]*github.RepositoryInvitation, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.RepositoryInvitation, *github.Response, error) {
+		return r.gh.Repositories.ListInvitations(ctx, owner, repoName, opts)
+	})
+}
+
+func (r *RealGitHubClient) UpdateInvitationForOwner(ctx context.Context, owner, repoName string, id int64, permission string) (*github.RepositoryInvitation, error) {
+	invitation, _, err := r.gh.Repositories.UpdateInvitation(ctx, owner, repoName, id, invitationPermission(permission))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return invitation, nil
+}
+
+func (r *RealGitHubClient) DeleteInvitationForOwner(ctx context.Context, owner, repoName string, id int64) error {
+	_, err := r.gh.Repositories.DeleteInvitation(ctx, owner, repoName, id)
+	return wrapError(err)
+}
+
+func (r *RealGitHubClient) ListTeamsForOwner(ctx context.Context, owner, repoName string) ([]*github.Team, error) {
+	isOrg, err := r.isOrganization(ctx, owner)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if !isOrg {
+		return nil, ErrTeamsOutsideOrg
+	}
+
+	return listAll(func(opts *github.ListOptions) ([]*github.Team, *github.Response, error) {
+		return r.gh.Repositories.ListTeams(ctx, owner, repoName, opts)
+	})
+}
+
+func (r *RealGitHubClient) AddTeamRepoForOwner(ctx context.Context, owner, repoName, teamSlug, permission string) error {
+	isOrg, err := r.isOrganization(ctx, owner)
+	if err != nil {
+		return wrapError(err)
+	}
+	if !isOrg {
+		return ErrTeamsOutsideOrg
+	}
+
+	opts := &github.TeamAddTeamRepoOptions{Permission: permission}
+	_, err = r.gh.Teams.AddTeamRepoBySlug(ctx, owner, teamSlug, owner, repoName, opts)
+	return wrapError(err)
+}
+
+func (r *RealGitHubClient) RemoveTeamRepoForOwner(ctx context.Context, owner, repoName, teamSlug string) error {
+	isOrg, err := r.isOrganization(ctx, owner)
+	if err != nil {
+		return wrapError(err)
+	}
+	if !isOrg {
+		return ErrTeamsOutsideOrg
+	}
+
+	_, err = r.gh.Teams.RemoveTeamRepoBySlug(ctx, owner, teamSlug, owner, repoName)
+	return wrapError(err)
+}
+
+func (c *Client) ListCollaborators(ctx context.Context, repoName string) ([]*github.User, error) {
+	return c.gh.ListCollaboratorsForOwner(ctx, c.owner, repoName)
+}
+
+// SetCollaborator invites the user with the permission, or updates the permission
+// of an existing collaborator. The invitation is nil in the latter case
+func (c *Client) SetCollaborator(ctx context.Context, repoName, user, permission string) (*github.RepositoryInvitation, error) {
+	if !validPermission(permission) {
+		return nil, ErrInvalidPermission
+	}
+	return c.gh.AddCollaboratorForOwner(ctx, c.owner, repoName, user, permission)
+}
+
+func (c *Client) RemoveCollaborator(ctx context.Context, repoName, user string) error {
+	return c.gh.RemoveCollaboratorForOwner(ctx, c.owner, repoName, user)
+}
+
+func (c *Client) ListInvitations(ctx context.Context, repoName string) ([]*github.RepositoryInvitation, error) {
+	return c.gh.ListInvitationsForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) UpdateInvitation(ctx context.Context, repoName string, id int64, permission string) (*github.RepositoryInvitation, error) {
+	if id < 1 {
+		return nil, ErrInvalidInvitation
+	}
+	if !validPermission(permission) {
+		return nil, ErrInvalidPermission
+	}
+	return c.gh.UpdateInvitationForOwner(ctx, c.owner, repoName, id, permission)
+}
+
+func (c *Client) DeleteInvitation(ctx context.Context, repoName string, id int64) error {
+	if id < 1 {
+		return ErrInvalidInvitation
+	}
+	return c.gh.DeleteInvitationForOwner(ctx, c.owner, repoName, id)
+}
+
+func (c *Client) ListTeams(ctx context.Context, repoName string) ([]*github.Team, error) {
+	return c.gh.ListTeamsForOwner(ctx, c.owner, repoName)
+}
+
+// AddTeamRepo grants an organization team access to the repository
+func (c *Client) AddTeamRepo(ctx context.Context, repoName, teamSlug, permission string) error {
+	if !validPermission(permission) {
+		return ErrInvalidPermission
+	}
+	return c.gh.AddTeamRepoForOwner(ctx, c.owner, repoName, teamSlug, permission)
+}
+
+func (c *Client) RemoveTeamRepo(ctx context.Context, repoName, teamSlug string) error {
+	return c.gh.RemoveTeamRepoForOwner(ctx, c.owner, repoName, teamSlug)
+}
@@ -0,0 +1,143 @@
+package githubapi
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"golang.org/x/oauth2"
+)
+
+const (
+	// Installation tokens last an hour, they're refreshed this long before they expire
+	AppTokenRefreshMargin = 5 * time.Minute
+
+	appJWTLifetime   = 9 * time.Minute // GitHub rejects JWTs valid for more than 10 minutes
+	appJWTClockSkew  = time.Minute     // Issued in the past, in case GitHub's clock is behind
+	appTokenTimeout  = 30 * time.Second
+	defaultGitHubURL = "https://api.github.com/"
+)
+
+var (
+	ErrInvalidAppKey  = Error("invalid GitHub App private key, expected an RSA key in PEM format")
+	ErrAppTokenFailed = Error("failed to get a GitHub App installation token")
+)
+
+// AppTokenSource issues installation tokens of a GitHub App, signing a JWT with
+// the app's private key to ask for each one
+type AppTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	baseURL        string
+	client         *http.Client
+}
+
+// NewAppTokenSource asks the API at baseURL for tokens of the installation,
+// privateKey is the PEM key downloaded from the app's settings
+func NewAppTokenSource(appID, installationID int64, privateKey []byte, baseURL string) (*AppTokenSource, error) {
+	key, err := parseAppKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &AppTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		baseURL:        baseURL,
+		client:         &http.Client{Timeout: appTokenTimeout},
+	}, nil
+}
+
+// parseAppKey reads GitHub's PKCS #1 keys, or PKCS #8 ones once converted
+func parseAppKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, ErrInvalidAppKey
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, ErrInvalidAppKey
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, ErrInvalidAppKey
+	}
+	return rsaKey, nil
+}
+
+// JWT authenticates as the app itself, which is only good for getting installation tokens
+func (s *AppTokenSource) JWT(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Token gets a new installation token, wrap the source with
+// oauth2.ReuseTokenSourceWithExpiry to only do so when needed
+func (s *AppTokenSource) Token() (*oauth2.Token, error) {
+	jwt, err := s.JWT(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), appTokenTimeout)
+	defer cancel()
+	url := fmt.Sprintf("%sapp/installations/%d/access_tokens", s.baseURL, s.installationID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+jwt)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAppTokenFailed, err)
+	}
+	defer resp.Body.Close()
+
+	if err := github.CheckResponse(resp); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAppTokenFailed, wrapError(err))
+	}
+	var token github.InstallationToken
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrAppTokenFailed, err)
+	}
+	return &oauth2.Token{AccessToken: token.GetToken(), TokenType: "token", Expiry: token.GetExpiresAt().Time}, nil
+}
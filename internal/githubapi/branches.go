@@ -0,0 +1,276 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrInvalidReviewCount = Error("approving_review_count must be between 0 and 6")
+	ErrInvalidBranchName  = Error("invalid branch name")
+)
+
+// Branch is a branch and the commit it points to
+type Branch struct {
+	Name      string `json:"name"`
+	SHA       string `json:"sha"`
+	Protected bool   `json:"protected"`
+}
+
+func NewBranch(branch *github.Branch) Branch {
+	return Branch{
+		Name:      branch.GetName(),
+		SHA:       branch.GetCommit().GetSHA(),
+		Protected: branch.GetProtected(),
+	}
+}
+
+// CreateBranchOptions creates Branch from From, a branch, tag or commit SHA,
+// or the default branch when empty
+type CreateBranchOptions struct {
+	Branch string `json:"branch"`
+	From   string `json:"from"`
+}
+
+func (o CreateBranchOptions) Validate() error {
+	return ValidateBranchName(o.Branch)
+}
+
+// ValidateBranchName applies git's rules for ref names
+func ValidateBranchName(name string) error {
+	if name == "" || name == "@" || strings.HasPrefix(name, "-") || strings.HasPrefix(name, "/") ||
+		strings.HasSuffix(name, "/") || strings.HasSuffix(name, ".") || strings.HasSuffix(name, ".lock") ||
+		strings.Contains(name, "..") || strings.Contains(name, "//") || strings.Contains(name, "@{") ||
+		strings.ContainsAny(name, " ~^:?*[\\") {
+		return ErrInvalidBranchName
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return ErrInvalidBranchName
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return ErrInvalidBranchName
+		}
+	}
+	return nil
+}
+
+// BranchProtection holds the protection rules of a branch, nil sections are disabled
+type BranchProtection struct {
+	RequiredReviews      *RequiredReviews      `json:"required_reviews"`
+	RequiredStatusChecks *RequiredStatusChecks `json:"required_status_checks"`
+	EnforceAdmins        bool                  `json:"enforce_admins"`
+	RequireLinearHistory bool                  `json:"require_linear_history"`
+	PushRestrictions     *PushRestrictions     `json:"push_restrictions"`
+}
+
+type RequiredReviews struct {
+	ApprovingReviewCount    int  `json:"approving_review_count"`
+	DismissStaleReviews     bool `json:"dismiss_stale_reviews"`
+	RequireCodeOwnerReviews bool `json:"require_code_owner_reviews"`
+}
+
+type RequiredStatusChecks struct {
+	Strict   bool     `json:"strict"` // Branches must be up to date before merging
+	Contexts []string `json:"contexts"`
+}
+
+// PushRestrictions limits who can push to the branch, only available in organizations
+type PushRestrictions struct {
+	Users []string `json:"users"`
+	Teams []string `json:"teams"`
+	Apps  []string `json:"apps"`
+}
+
+func (p BranchProtection) Validate() error {
+	if p.RequiredReviews != nil && (p.RequiredReviews.ApprovingReviewCount < 0 || p.RequiredReviews.ApprovingReviewCount > 6) {
+		return ErrInvalidReviewCount
+	}
+	return nil
+}
+
+// NewBranchProtection converts GitHub's protection of a branch, dropping the rules BranchProtection doesn't cover
+func NewBranchProtection(protection *github.Protection) BranchProtection {
+	var converted BranchProtection
+	if admins := protection.GetEnforceAdmins(); admins != nil {
+		converted.EnforceAdmins = admins.Enabled
+	}
+	if linear := protection.GetRequireLinearHistory(); linear != nil {
+		converted.RequireLinearHistory = linear.Enabled
+	}
+
+	if reviews := protection.GetRequiredPullRequestReviews(); reviews != nil {
+		converted.RequiredReviews = &RequiredReviews{
+			ApprovingReviewCount:    reviews.RequiredApprovingReviewCount,
+			DismissStaleReviews:     reviews.DismissStaleReviews,
+			RequireCodeOwnerReviews: reviews.RequireCodeOwnerReviews,
+		}
+	}
+
+	if checks := protection.GetRequiredStatusChecks(); checks != nil {
+		converted.RequiredStatusChecks = &RequiredStatusChecks{Strict: checks.Strict, Contexts: []string{}}
+		if checks.Contexts != nil {
+			converted.RequiredStatusChecks.Contexts = *checks.Contexts
+		}
+	}
+
+	if restrictions := protection.GetRestrictions(); restrictions != nil {
+		converted.PushRestrictions = &PushRestrictions{Users: []string{}, Teams: []string{}, Apps: []string{}}
+		for _, user := range restrictions.Users {
+			converted.PushRestrictions.Users = append(converted.PushRestrictions.Users, user.GetLogin())
+		}
+		for _, team := range restrictions.Teams {
+			converted.PushRestrictions.Teams = append(converted.PushRestrictions.Teams, team.GetSlug())
+		}
+		for _, app := range restrictions.Apps {
+			converted.PushRestrictions.Apps = append(converted.PushRestrictions.Apps, app.GetSlug())
+		}
+	}
+
+	return converted
+}
+
+func (p BranchProtection) toGitHub() *github.ProtectionRequest {
+	req := &github.ProtectionRequest{
+		EnforceAdmins:        p.EnforceAdmins,
+		RequireLinearHistory: github.Bool(p.RequireLinearHistory),
+	}
+
+	if p.RequiredReviews != nil {
+		req.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcementRequest{
+			RequiredApprovingReviewCount: p.RequiredReviews.ApprovingReviewCount,
+			DismissStaleReviews:          p.RequiredReviews.DismissStaleReviews,
+			RequireCodeOwnerReviews:      p.RequiredReviews.RequireCodeOwnerReviews,
+		}
+	}
+
+	if p.RequiredStatusChecks != nil {
+		// GitHub wants an empty list rather than a missing one
+		contexts := p.RequiredStatusChecks.Contexts
+		if contexts == nil {
+			contexts = []string{}
+		}
+		req.RequiredStatusChecks = &github.RequiredStatusChecks{
+			Strict:   p.RequiredStatusChecks.Strict,
+			Contexts: &contexts,
+		}
+	}
+
+	if p.PushRestrictions != nil {
+		req.Restrictions = &github.BranchRestrictionsRequest{
+			Users: nonNil(p.PushRestrictions.Users),
+			Teams: nonNil(p.PushRestrictions.Teams),
+			Apps:  nonNil(p.PushRestrictions.Apps),
+		}
+	}
+
+	return req
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func (r *RealGitHubClient) UpdateBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string, protection BranchProtection) (*github.Protection, error) {
+	updated, _, err := r.gh.Repositories.UpdateBranchProtection(ctx, owner, repoName, branch, protection.toGitHub())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return updated, nil
+}
+
+// GetBranchProtectionForOwner fails with ErrNotFound when the branch isn't protected
+func (r *RealGitHubClient) GetBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string) (*github.Protection, error) {
+	protection, _, err := r.gh.Repositories.GetBranchProtection(ctx, owner, repoName, branch)
+	if errors.Is(err, github.ErrBranchNotProtected) {
+		return nil, &apiError{kind: ErrNotFound, err: err}
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return protection, nil
+}
+
+func (r *RealGitHubClient) RemoveBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string) error {
+	_, err := r.gh.Repositories.RemoveBranchProtection(ctx, owner, repoName, branch)
+	return wrapError(err)
+}
+
+func (r *RealGitHubClient) ListBranchesForOwner(ctx context.Context, owner, repoName string) ([]*github.Branch, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.Branch, *github.Response, error) {
+		return r.gh.Repositories.ListBranches(ctx, owner, repoName, &github.BranchListOptions{ListOptions: *opts})
+	})
+}
+
+// CreateBranchForOwner resolves from, a branch, tag or commit SHA, to a commit
+// and creates the branch there. An empty from is the default branch
+func (r *RealGitHubClient) CreateBranchForOwner(ctx context.Context, owner, repoName, branch, from string) (*github.Branch, error) {
+	if from == "" {
+		repo, _, err := r.gh.Repositories.Get(ctx, owner, repoName)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		from = repo.GetDefaultBranch()
+	}
+
+	sha, _, err := r.gh.Repositories.GetCommitSHA1(ctx, owner, repoName, from, "")
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	_, _, err = r.gh.Git.CreateRef(ctx, owner, repoName, &github.Reference{
+		Ref:    github.String("refs/heads/" + branch),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	return &github.Branch{
+		Name:   github.String(branch),
+		Commit: &github.RepositoryCommit{SHA: github.String(sha)},
+	}, nil
+}
+
+func (r *RealGitHubClient) DeleteBranchForOwner(ctx context.Context, owner, repoName, branch string) error {
+	_, err := r.gh.Git.DeleteRef(ctx, owner, repoName, "heads/"+branch)
+	return wrapError(err)
+}
+
+func (c *Client) UpdateBranchProtection(ctx context.Context, repoName, branch string, protection BranchProtection) (*github.Protection, error) {
+	if err := protection.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.UpdateBranchProtectionForOwner(ctx, c.owner, repoName, branch, protection)
+}
+
+func (c *Client) GetBranchProtection(ctx context.Context, repoName, branch string) (*github.Protection, error) {
+	return c.gh.GetBranchProtectionForOwner(ctx, c.owner, repoName, branch)
+}
+
+func (c *Client) RemoveBranchProtection(ctx context.Context, repoName, branch string) error {
+	return c.gh.RemoveBranchProtectionForOwner(ctx, c.owner, repoName, branch)
+}
+
+func (c *Client) ListBranches(ctx context.Context, repoName string) ([]*github.Branch, error) {
+	return c.gh.ListBranchesForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) CreateBranch(ctx context.Context, repoName string, opts CreateBranchOptions) (*github.Branch, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreateBranchForOwner(ctx, c.owner, repoName, opts.Branch, opts.From)
+}
+
+func (c *Client) DeleteBranch(ctx context.Context, repoName, branch string) error {
+	return c.gh.DeleteBranchForOwner(ctx, c.owner, repoName, branch)
+}
@@ -0,0 +1,94 @@
+package githubapi
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/httpcache"
+)
+
+// CachingClient is a RealGitHubClient whose GET requests go through an HTTP
+// cache. Writes to a repository invalidate its cached responses in the cache
+// itself, the client also invalidates the listings and searches they change
+type CachingClient struct {
+	*RealGitHubClient
+	cache   *httpcache.Cache
+	baseURL *url.URL
+}
+
+func NewCachingClient(gh *github.Client, cache *httpcache.Cache) *CachingClient {
+	httpClient := gh.Client()
+	httpClient.Transport = cache.Transport(httpClient.Transport)
+
+	cached := github.NewClient(httpClient)
+	cached.BaseURL, cached.UploadURL = gh.BaseURL, gh.UploadURL
+	return &CachingClient{RealGitHubClient: NewRealGitHubClient(cached), cache: cache, baseURL: gh.BaseURL}
+}
+
+// NewAppCachingClient is NewCachingClient for a GitHub App installation
+func NewAppCachingClient(gh *github.Client, cache *httpcache.Cache) *CachingClient {
+	c := NewCachingClient(gh, cache)
+	c.appAuth = true
+	return c
+}
+
+// invalidateRepoListings drops the cached listings of the owner's repositories,
+// including the ones granted to an app installation
+func (c *CachingClient) invalidateRepoListings(ctx context.Context, owner string) {
+	for _, path := range []string{"orgs/" + owner + "/repos", "users/" + owner + "/repos", "user/repos", "installation/repositories"} {
+		c.cache.Invalidate(ctx, c.baseURL.ResolveReference(&url.URL{Path: path}))
+	}
+}
+
+// invalidateSearch drops the cached pull request searches
+func (c *CachingClient) invalidateSearch(ctx context.Context) {
+	c.cache.Invalidate(ctx, c.baseURL.ResolveReference(&url.URL{Path: "search/issues"}))
+}
+
+// Repositories are invalidated even on errors, a create can fail after the repo was made
+
+func (c *CachingClient) CreateRepoForOwner(ctx context.Context, owner string, opts CreateRepoOptions) (*github.Repository, error) {
+	defer c.invalidateRepoListings(ctx, owner)
+	return c.RealGitHubClient.CreateRepoForOwner(ctx, owner, opts)
+}
+
+func (c *CachingClient) CreateRepoFromTemplateForOwner(ctx context.Context, owner string, opts TemplateRepoOptions) (*github.Repository, error) {
+	defer c.invalidateRepoListings(ctx, owner)
+	return c.RealGitHubClient.CreateRepoFromTemplateForOwner(ctx, owner, opts)
+}
+
+func (c *CachingClient) DeleteRepoForOwner(ctx context.Context, owner, repoName string) error {
+	defer c.invalidateRepoListings(ctx, owner)
+	return c.RealGitHubClient.DeleteRepoForOwner(ctx, owner, repoName)
+}
+
+func (c *CachingClient) UpdateRepoSettingsForOwner(ctx context.Context, owner, repoName string, settings RepoSettings) (*github.Repository, error) {
+	defer c.invalidateRepoListings(ctx, owner)
+	return c.RealGitHubClient.UpdateRepoSettingsForOwner(ctx, owner, repoName, settings)
+}
+
+func (c *CachingClient) SetRepoArchivedForOwner(ctx context.Context, owner, repoName string, archived bool) (*github.Repository, error) {
+	defer c.invalidateRepoListings(ctx, owner)
+	return c.RealGitHubClient.SetRepoArchivedForOwner(ctx, owner, repoName, archived)
+}
+
+func (c *CachingClient) ReplaceTopicsForOwner(ctx context.Context, owner, repoName string, topics []string) ([]string, error) {
+	defer c.invalidateRepoListings(ctx, owner)
+	return c.RealGitHubClient.ReplaceTopicsForOwner(ctx, owner, repoName, topics)
+}
+
+func (c *CachingClient) CreatePullRequestForOwner(ctx context.Context, owner, repoName string, opts CreatePullRequestOptions) (*github.PullRequest, error) {
+	defer c.invalidateSearch(ctx)
+	return c.RealGitHubClient.CreatePullRequestForOwner(ctx, owner, repoName, opts)
+}
+
+func (c *CachingClient) UpdatePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts UpdatePullRequestOptions) (*github.PullRequest, error) {
+	defer c.invalidateSearch(ctx)
+	return c.RealGitHubClient.UpdatePullRequestForOwner(ctx, owner, repoName, number, opts)
+}
+
+func (c *CachingClient) MergePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts MergePullRequestOptions) (*github.PullRequestMergeResult, error) {
+	defer c.invalidateSearch(ctx)
+	return c.RealGitHubClient.MergePullRequestForOwner(ctx, owner, repoName, number, opts)
+}
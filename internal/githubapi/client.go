@@ -3,137 +3,420 @@ package githubapi
 import (
 	"context"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/httpcache"
 	"golang.org/x/oauth2"
 )
 
 type GitHubClient interface {
 	ListReposForOwner(ctx context.Context, owner string) ([]*github.Repository, error)
-	CreateRepoForOwner(ctx context.Context, owner, repoName string) (*github.Repository, error)
+	GetRepoForOwner(ctx context.Context, owner, repoName string) (*github.Repository, error)
+	CreateRepoForOwner(ctx context.Context, owner string, opts CreateRepoOptions) (*github.Repository, error)
+	CreateRepoFromTemplateForOwner(ctx context.Context, owner string, opts TemplateRepoOptions) (*github.Repository, error)
 	DeleteRepoForOwner(ctx context.Context, owner, repoName string) error
-	ListPullRequestsForOwner(ctx context.Context, owner, repoName string, n int) ([]*github.PullRequest, error)
+	ListPullRequestsForOwner(ctx context.Context, owner, repoName string, filter PullRequestFilter, n int) ([]*github.PullRequest, error)
+	SearchPullRequestsForOwner(ctx context.Context, owner string, filter PullRequestFilter) ([]*github.Issue, error)
+
+	// Pull request details
+	GetPullRequestForOwner(ctx context.Context, owner, repoName string, number int) (*github.PullRequest, error)
+	ListReviewsForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.PullRequestReview, error)
+	ListPullRequestFilesForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.CommitFile, error)
+	GetCombinedStatusForOwner(ctx context.Context, owner, repoName, ref string) (*github.CombinedStatus, error)
+	ListCheckRunsForOwner(ctx context.Context, owner, repoName, ref string) ([]*github.CheckRun, error)
+
+	// Pull request changes
+	CreatePullRequestForOwner(ctx context.Context, owner, repoName string, opts CreatePullRequestOptions) (*github.PullRequest, error)
+	UpdatePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts UpdatePullRequestOptions) (*github.PullRequest, error)
+	MergePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts MergePullRequestOptions) (*github.PullRequestMergeResult, error)
+
+	// Repository setup
+	UpdateRepoSettingsForOwner(ctx context.Context, owner, repoName string, settings RepoSettings) (*github.Repository, error)
+	CreateLabelForOwner(ctx context.Context, owner, repoName string, label Label) (*github.Label, error)
+	UpdateLabelForOwner(ctx context.Context, owner, repoName, name string, label Label) (*github.Label, error)
+	DeleteLabelForOwner(ctx context.Context, owner, repoName, name string) error
+	CreateMilestoneForOwner(ctx context.Context, owner, repoName string, milestone Milestone) (*github.Milestone, error)
+	UpdateMilestoneForOwner(ctx context.Context, owner, repoName string, number int, milestone Milestone) (*github.Milestone, error)
+	DeleteMilestoneForOwner(ctx context.Context, owner, repoName string, number int) error
+	UpdateBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string, protection BranchProtection) (*github.Protection, error)
+	GetBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string) (*github.Protection, error)
+	RemoveBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string) error
+	AddTeamRepoForOwner(ctx context.Context, owner, repoName, teamSlug, permission string) error
+	CreateFileForOwner(ctx context.Context, owner, repoName, path string, content []byte, message string) error
+	CreateHookForOwner(ctx context.Context, owner, repoName string, hook Hook) (*github.Hook, error)
+	UpdateHookForOwner(ctx context.Context, owner, repoName string, id int64, update HookUpdate) (*github.Hook, error)
+	DeleteHookForOwner(ctx context.Context, owner, repoName string, id int64) error
+	PingHookForOwner(ctx context.Context, owner, repoName string, id int64) error
+	RedeliverHookDeliveryForOwner(ctx context.Context, owner, repoName string, hookID, deliveryID int64) error
+	SetRepoArchivedForOwner(ctx context.Context, owner, repoName string, archived bool) (*github.Repository, error)
+	ReplaceTopicsForOwner(ctx context.Context, owner, repoName string, topics []string) ([]string, error)
+
+	// Repository data
+	ListIssuesForOwner(ctx context.Context, owner, repoName string) ([]*github.Issue, error)
+	ListLabelsForOwner(ctx context.Context, owner, repoName string) ([]*github.Label, error)
+	ListMilestonesForOwner(ctx context.Context, owner, repoName string) ([]*github.Milestone, error)
+	ListHooksForOwner(ctx context.Context, owner, repoName string) ([]*github.Hook, error)
+	GetHookForOwner(ctx context.Context, owner, repoName string, id int64) (*github.Hook, error)
+	ListHookDeliveriesForOwner(ctx context.Context, owner, repoName string, id int64, cursor string, perPage int) ([]*github.HookDelivery, string, error)
+	ListReleasesForOwner(ctx context.Context, owner, repoName string) ([]*github.RepositoryRelease, error)
+	DownloadArchiveForOwner(ctx context.Context, owner, repoName, format string) (io.ReadCloser, error)
+
+	// Repository statistics, the computed ones return ErrStatsPending until GitHub has them
+	ListLanguagesForOwner(ctx context.Context, owner, repoName string) (map[string]int, error)
+	ListContributorStatsForOwner(ctx context.Context, owner, repoName string) ([]*github.ContributorStats, error)
+	ListCommitActivityForOwner(ctx context.Context, owner, repoName string) ([]*github.WeeklyCommitActivity, error)
+	ListCodeFrequencyForOwner(ctx context.Context, owner, repoName string) ([]*github.WeeklyStats, error)
+	ListTrafficViewsForOwner(ctx context.Context, owner, repoName string) (*github.TrafficViews, error)
+	ListTrafficClonesForOwner(ctx context.Context, owner, repoName string) (*github.TrafficClones, error)
+
+	// Access
+	ListCollaboratorsForOwner(ctx context.Context, owner, repoName string) ([]*github.User, error)
+	AddCollaboratorForOwner(ctx context.Context, owner, repoName, user, permission string) (*github.RepositoryInvitation, error)
+	RemoveCollaboratorForOwner(ctx context.Context, owner, repoName, user string) error
+	ListInvitationsForOwner(ctx context.Context, owner, repoName string) ([]*github.RepositoryInvitation, error)
+	UpdateInvitationForOwner(ctx context.Context, owner, repoName string, id int64, permission string) (*github.RepositoryInvitation, error)
+	DeleteInvitationForOwner(ctx context.Context, owner, repoName string, id int64) error
+	ListTeamsForOwner(ctx context.Context, owner, repoName string) ([]*github.Team, error)
+	RemoveTeamRepoForOwner(ctx context.Context, owner, repoName, teamSlug string) error
+
+	// Branches
+	ListBranchesForOwner(ctx context.Context, owner, repoName string) ([]*github.Branch, error)
+	CreateBranchForOwner(ctx context.Context, owner, repoName, branch, from string) (*github.Branch, error)
+	DeleteBranchForOwner(ctx context.Context, owner, repoName, branch string) error
+
+	// Rate limit of the authenticated client
+	GetRateLimits(ctx context.Context) (*github.RateLimits, error)
+
+	// Single page listings, they return the next page number or 0 on the last page
+	ListReposPageForOwner(ctx context.Context, owner string, page, perPage int) ([]*github.Repository, int, error)
+	ListPullRequestsPageForOwner(ctx context.Context, owner, repoName string, filter PullRequestFilter, page, perPage int) ([]*github.PullRequest, int, error)
 }
 
 // Real implementation of the GitHubClient interface
 type RealGitHubClient struct {
 	gh *github.Client
+
+	// Caches whether an owner is an organization, account types don't change
+	orgOwners sync.Map
+
+	// Login of the token's user, fetched once
+	loginMu   sync.Mutex
+	authLogin string
+	appAuth   bool // Authenticated as a GitHub App installation, which has no user
+}
+
+func NewRealGitHubClient(gh *github.Client) *RealGitHubClient {
+	return &RealGitHubClient{gh: gh}
+}
+
+// NewAppGitHubClient is for gh authenticated as a GitHub App installation,
+// listing the repositories the app was given instead of the user's
+func NewAppGitHubClient(gh *github.Client) *RealGitHubClient {
+	return &RealGitHubClient{gh: gh, appAuth: true}
 }
 
 // todo log errors?
-func (r *RealGitHubClient) CreateRepoForOwner(ctx context.Context, owner, repoName string) (*github.Repository, error) {
-	newRepo := &github.Repository{Name: github.String(repoName)}
-	repo, _, err := r.gh.Repositories.Create(ctx, owner, newRepo)
+
+// CreateRepoForOwner returns the repository along with the error when it was
+// created but setting it up afterwards failed
+func (r *RealGitHubClient) CreateRepoForOwner(ctx context.Context, owner string, opts CreateRepoOptions) (*github.Repository, error) {
+	isOrg, err := r.isOrganization(ctx, owner)
 	if err != nil {
 		return nil, err
 	}
-	return repo, nil
-}
 
-func (r *RealGitHubClient) DeleteRepoForOwner(ctx context.Context, owner, repoName string) error {
-	repos, _, err := r.gh.Repositories.ListByAuthenticatedUser(ctx, nil)
+	// An empty org creates the repo for the authenticated user
+	org := ""
+	if isOrg {
+		org = owner
+	} else if opts.Visibility == "internal" {
+		return nil, ErrInternalOutsideOrg
+	}
+
+	repo, _, err := r.gh.Repositories.Create(ctx, org, opts.toGitHub())
 	if err != nil {
-		return err
+		return nil, wrapError(err)
 	}
 
-	// Check if the repo exists
-	repoFound := false
-	for _, repo := range repos {
-		if *repo.Name == repoName {
-			repoFound = true
-			break
+	// Topics and the default branch can't be set in the create request
+	repoOwner := repo.GetOwner().GetLogin()
+	if len(opts.Topics) > 0 {
+		topics, _, err := r.gh.Repositories.ReplaceAllTopics(ctx, repoOwner, repo.GetName(), opts.Topics)
+		if err != nil {
+			return repo, fmt.Errorf("repository created but setting topics failed: %w", wrapError(err))
 		}
+		repo.Topics = topics
 	}
 
-	if !repoFound {
-		return fmt.Errorf("repository not found")
+	if opts.DefaultBranch != "" && opts.DefaultBranch != repo.GetDefaultBranch() {
+		_, _, err := r.gh.Repositories.RenameBranch(ctx, repoOwner, repo.GetName(), repo.GetDefaultBranch(), opts.DefaultBranch)
+		if err != nil {
+			return repo, fmt.Errorf("repository created but renaming the default branch failed: %w", wrapError(err))
+		}
+		repo.DefaultBranch = github.String(opts.DefaultBranch)
 	}
 
-	_, err = r.gh.Repositories.Delete(ctx, owner, repoName)
+	return repo, nil
+}
+
+func (r *RealGitHubClient) DeleteRepoForOwner(ctx context.Context, owner, repoName string) error {
+	// Look the repository up directly, it may be on any page of the listing or in an org
+	_, resp, err := r.gh.Repositories.Get(ctx, owner, repoName)
+	if isStatus(resp, http.StatusNotFound) {
+		return fmt.Errorf("repository %w", ErrNotFound)
+	}
 	if err != nil {
-		return err
+		return wrapError(err)
 	}
 
-	return nil
+	_, err = r.gh.Repositories.Delete(ctx, owner, repoName)
+	return wrapError(err)
 }
 
 func (r *RealGitHubClient) ListReposForOwner(ctx context.Context, owner string) ([]*github.Repository, error) {
-	repos, _, err := r.gh.Repositories.ListByAuthenticatedUser(ctx, nil)
-	if err != nil {
-		return nil, err
+	var allRepos []*github.Repository
+	page := 1
+	for {
+		repos, nextPage, err := r.ListReposPageForOwner(ctx, owner, page, 100)
+		if err != nil {
+			return nil, err
+		}
+
+		allRepos = append(allRepos, repos...)
+		if nextPage == 0 {
+			break
+		}
+		page = nextPage
 	}
-	return repos, nil
+
+	return allRepos, nil
 }
 
-func (r *RealGitHubClient) ListPullRequestsForOwner(ctx context.Context, owner, repoName string, n int) ([]*github.PullRequest, error) {
-	opts := &github.PullRequestListOptions{
-		State: "open",
-		ListOptions: github.ListOptions{
-			PerPage: 100,
-		},
+func (r *RealGitHubClient) ListReposPageForOwner(ctx context.Context, owner string, page, perPage int) ([]*github.Repository, int, error) {
+	listOpts := github.ListOptions{Page: page, PerPage: perPage}
+
+	isOrg, err := r.isOrganization(ctx, owner)
+	if err != nil {
+		return nil, 0, err
+	}
+	if isOrg {
+		opts := &github.RepositoryListByOrgOptions{Type: "all", ListOptions: listOpts}
+		repos, resp, err := r.gh.Repositories.ListByOrg(ctx, owner, opts)
+		if err != nil {
+			return nil, 0, err
+		}
+		return repos, resp.NextPage, nil
 	}
 
-	var allPRs []*github.PullRequest
-	for {
-		prs, resp, err := r.gh.PullRequests.List(ctx, owner, repoName, opts)
+	login, err := r.authenticatedLogin(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// An app installed on a user account lists the repos it was granted
+	if login == "" {
+		list, resp, err := r.gh.Apps.ListRepos(ctx, &listOpts)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
+		repos := make([]*github.Repository, 0, len(list.Repositories))
+		for _, repo := range list.Repositories {
+			if strings.EqualFold(repo.GetOwner().GetLogin(), owner) {
+				repos = append(repos, repo)
+			}
+		}
+		return repos, resp.NextPage, nil
+	}
 
-		allPRs = append(allPRs, prs...)
-		if resp.NextPage == 0 {
-			break
+	// Other users' repos only show up through the public listing
+	if !strings.EqualFold(login, owner) {
+		opts := &github.RepositoryListByUserOptions{Type: "owner", ListOptions: listOpts}
+		repos, resp, err := r.gh.Repositories.ListByUser(ctx, owner, opts)
+		if err != nil {
+			return nil, 0, err
 		}
-		opts.Page = resp.NextPage
+		return repos, resp.NextPage, nil
 	}
 
-	// Limit the number of PRs if n is specified
-	if n != -1 && len(allPRs) > n {
-		allPRs = allPRs[:n]
+	// The authenticated listing includes private repos, but only keep the ones owned by the user
+	opts := &github.RepositoryListByAuthenticatedUserOptions{Affiliation: "owner", ListOptions: listOpts}
+	repos, resp, err := r.gh.Repositories.ListByAuthenticatedUser(ctx, opts)
+	if err != nil {
+		return nil, 0, err
 	}
+	return repos, resp.NextPage, nil
+}
 
-	return allPRs, nil
+// isOrganization checks if the owner is an organization or a user account
+func (r *RealGitHubClient) isOrganization(ctx context.Context, owner string) (bool, error) {
+	if cached, ok := r.orgOwners.Load(owner); ok {
+		return cached.(bool), nil
+	}
+
+	user, _, err := r.gh.Users.Get(ctx, owner)
+	if err != nil {
+		return false, err
+	}
+
+	isOrg := user.GetType() == "Organization"
+	r.orgOwners.Store(owner, isOrg)
+	return isOrg, nil
+}
+
+// authenticatedLogin is empty for GitHub App installations
+func (r *RealGitHubClient) authenticatedLogin(ctx context.Context) (string, error) {
+	r.loginMu.Lock()
+	defer r.loginMu.Unlock()
+
+	if r.authLogin != "" || r.appAuth {
+		return r.authLogin, nil
+	}
+
+	user, _, err := r.gh.Users.Get(ctx, "")
+	if err != nil {
+		return "", err
+	}
+
+	r.authLogin = user.GetLogin()
+	return r.authLogin, nil
 }
 
 type Client struct {
-	gh    GitHubClient
-	owner string
+	gh      GitHubClient
+	owner   string
+	limiter *RateLimiter       // Nil when requests aren't rate limited
+	tokens  oauth2.TokenSource // Nil for test clients
 }
 
 func NewClient() (*Client, error) {
-	token := os.Getenv("GITHUB_TOKEN")
-	owner := os.Getenv("GITHUB_OWNER")
+	ghClient, limiter, tokens, owner, err := newGitHubFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	gh := NewRealGitHubClient(ghClient)
+	if appFromEnv() {
+		gh = NewAppGitHubClient(ghClient)
+	}
+
+	return &Client{
+		gh:      gh,
+		owner:   owner,
+		limiter: limiter,
+		tokens:  tokens,
+	}, nil
+}
 
-	if token == "" {
-		log.Println(ErrMissingToken)
-		return nil, ErrMissingToken
+// NewCachedClient is NewClient with GET requests going through cache
+func NewCachedClient(cache *httpcache.Cache) (*Client, error) {
+	ghClient, limiter, tokens, owner, err := newGitHubFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	gh := NewCachingClient(ghClient, cache)
+	if appFromEnv() {
+		gh = NewAppCachingClient(ghClient, cache)
 	}
+
+	// Cache hits don't go through the rate limiter
+	return &Client{
+		gh:      gh,
+		owner:   owner,
+		limiter: limiter,
+		tokens:  tokens,
+	}, nil
+}
+
+// newGitHubFromEnv authenticates as the GitHub App set in GITHUB_APP_*, or
+// with GITHUB_TOKEN. GITHUB_OWNER is the owner to manage
+func newGitHubFromEnv() (*github.Client, *RateLimiter, oauth2.TokenSource, string, error) {
+	owner := os.Getenv("GITHUB_OWNER")
 	if owner == "" {
 		log.Println(ErrMissingOwner)
-		return nil, ErrMissingOwner
+		return nil, nil, nil, "", ErrMissingOwner
 	}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
+	ts, err := tokenSourceFromEnv()
+	if err != nil {
+		log.Println(err)
+		return nil, nil, nil, "", err
+	}
 	tc := oauth2.NewClient(context.Background(), ts)
 
-	ghClient := github.NewClient(tc)
-	real := &RealGitHubClient{gh: ghClient}
+	limiter := NewRateLimiter(DefaultMaxRetries, DefaultBackoff)
+	tc.Transport = limiter.Transport(tc.Transport)
+	return github.NewClient(tc), limiter, ts, owner, nil
+}
 
-	return &Client{
-		gh:    real,
-		owner: owner,
-	}, nil
+// appFromEnv tells whether any of GITHUB_APP_* is set, the client then
+// authenticates as the app's installation
+func appFromEnv() bool {
+	return os.Getenv("GITHUB_APP_ID") != "" || os.Getenv("GITHUB_APP_INSTALLATION_ID") != "" ||
+		os.Getenv("GITHUB_APP_PRIVATE_KEY") != "" || os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE") != ""
+}
 
+func tokenSourceFromEnv() (oauth2.TokenSource, error) {
+	appID := os.Getenv("GITHUB_APP_ID")
+	installationID := os.Getenv("GITHUB_APP_INSTALLATION_ID")
+	key := os.Getenv("GITHUB_APP_PRIVATE_KEY")
+	keyFile := os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE")
+
+	if !appFromEnv() {
+		token := os.Getenv("GITHUB_TOKEN")
+		if token == "" {
+			return nil, ErrMissingToken
+		}
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}), nil
+	}
+
+	app, err := strconv.ParseInt(appID, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidAppConfig
+	}
+	installation, err := strconv.ParseInt(installationID, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidAppConfig
+	}
+	pemKey := []byte(key)
+	if keyFile != "" {
+		if pemKey, err = os.ReadFile(keyFile); err != nil {
+			return nil, fmt.Errorf("reading GITHUB_APP_PRIVATE_KEY_FILE: %w", err)
+		}
+	}
+
+	source, err := NewAppTokenSource(app, installation, pemKey, defaultGitHubURL)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.ReuseTokenSourceWithExpiry(nil, source, AppTokenRefreshMargin), nil
+}
+
+// Token is the current access token, for callers going to GitHub without the
+// client such as git. Empty when the client has none
+func (c *Client) Token() (string, error) {
+	if c.tokens == nil {
+		return "", nil
+	}
+	token, err := c.tokens.Token()
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
 }
 
 // Define custom error types
 var (
-	ErrMissingToken = Error("GITHUB_TOKEN not set")
-	ErrMissingOwner = Error("GITHUB_OWNER not set")
+	ErrMissingToken     = Error("GITHUB_TOKEN not set")
+	ErrMissingOwner     = Error("GITHUB_OWNER not set")
+	ErrInvalidAppConfig = Error("GITHUB_APP_ID and GITHUB_APP_INSTALLATION_ID must be set to numeric IDs")
 )
 
 // Create a custom error type to implement the error interface
@@ -142,7 +425,7 @@ type Error string
 func (e Error) Error() string { return string(e) }
 
 func (c *Client) CreateRepo(ctx context.Context, repoName string) (*github.Repository, error) {
-	return c.gh.CreateRepoForOwner(ctx, c.owner, repoName)
+	return c.CreateRepoWithOptions(ctx, CreateRepoOptions{Name: repoName})
 }
 
 func (c *Client) DeleteRepo(ctx context.Context, repoName string) error {
@@ -153,8 +436,9 @@ func (c *Client) ListRepos(ctx context.Context) ([]*github.Repository, error) {
 	return c.gh.ListReposForOwner(ctx, c.owner)
 }
 
+// ListPullRequests lists up to n open pull requests, -1 means no limit
 func (c *Client) ListPullRequests(ctx context.Context, repoName string, n int) ([]*github.PullRequest, error) {
-	return c.gh.ListPullRequestsForOwner(ctx, c.owner, repoName, n)
+	return c.ListPullRequestsWithFilter(ctx, repoName, PullRequestFilter{}, n)
 }
 
 func NewTestClient(mockClient GitHubClient, owner string) *Client {
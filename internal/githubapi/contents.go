@@ -0,0 +1,65 @@
+package githubapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var ErrInvalidFilePath = Error("file path is required")
+
+func (r *RealGitHubClient) CreateFileForOwner(ctx context.Context, owner, repoName, path string, content []byte, message string) error {
+	opts := &github.RepositoryContentFileOptions{
+		Message: github.String(message),
+		Content: content,
+	}
+
+	_, _, err := r.gh.Repositories.CreateFile(ctx, owner, repoName, path, opts)
+	return err
+}
+
+// CreateFile commits a new file to the default branch, on an empty repository it becomes the first commit
+func (c *Client) CreateFile(ctx context.Context, repoName, path string, content []byte, message string) error {
+	if path == "" {
+		return ErrInvalidFilePath
+	}
+	if message == "" {
+		message = "Add " + path
+	}
+	return c.gh.CreateFileForOwner(ctx, c.owner, repoName, path, content, message)
+}
+
+var ErrInvalidArchiveFormat = Error("archive format must be tarball or zipball")
+
+// DownloadArchiveForOwner streams a tarball or zipball of the default branch, the caller closes it
+func (r *RealGitHubClient) DownloadArchiveForOwner(ctx context.Context, owner, repoName, format string) (io.ReadCloser, error) {
+	link, _, err := r.gh.Repositories.GetArchiveLink(ctx, owner, repoName, github.ArchiveFormat(format), nil, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.gh.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("downloading archive: unexpected status %s", resp.Status)
+	}
+	return resp.Body, nil
+}
+
+func (c *Client) DownloadArchive(ctx context.Context, repoName, format string) (io.ReadCloser, error) {
+	if format != string(github.Tarball) && format != string(github.Zipball) {
+		return nil, ErrInvalidArchiveFormat
+	}
+	return c.gh.DownloadArchiveForOwner(ctx, c.owner, repoName, format)
+}
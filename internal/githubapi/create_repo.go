@@ -0,0 +1,136 @@
+package githubapi
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// GitHub allows up to 20 topics per repository
+const MaxTopics = 20
+
+var (
+	ErrInvalidRepoName      = Error("invalid repository name")
+	ErrInvalidHomepage      = Error("invalid value for homepage")
+	ErrInvalidTopic         = Error("invalid topic, use up to 20 lowercase topics of letters, numbers and hyphens")
+	ErrNoMergeStrategy      = Error("at least one merge strategy must be allowed")
+	ErrDefaultBranchNoInit  = Error("default_branch requires auto_init")
+	ErrInternalOutsideOrg   = Error("internal visibility is only available for organization repositories")
+	ErrInvalidDefaultBranch = Error("invalid value for default_branch")
+)
+
+var (
+	repoNamePattern   = regexp.MustCompile(`^[A-Za-z0-9._-]{1,100}$`)
+	topicPattern      = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+	branchNamePattern = regexp.MustCompile(`^[A-Za-z0-9._/-]{1,255}$`)
+)
+
+// CreateRepoOptions holds the settings of a new repository, unset fields keep GitHub's defaults
+type CreateRepoOptions struct {
+	Name              string   `json:"name"`
+	Description       string   `json:"description"`
+	Homepage          string   `json:"homepage"`
+	Visibility        string   `json:"visibility"` // public, private or internal
+	Topics            []string `json:"topics"`
+	AutoInit          bool     `json:"auto_init"`
+	GitignoreTemplate string   `json:"gitignore_template"`
+	LicenseTemplate   string   `json:"license_template"`
+	DefaultBranch     string   `json:"default_branch"`
+
+	// Merge settings
+	AllowSquashMerge    *bool `json:"allow_squash_merge"`
+	AllowRebaseMerge    *bool `json:"allow_rebase_merge"`
+	AllowMergeCommit    *bool `json:"allow_merge_commit"`
+	DeleteBranchOnMerge *bool `json:"delete_branch_on_merge"`
+}
+
+// Validate checks the options before anything is sent to GitHub
+func (o CreateRepoOptions) Validate() error {
+	if !repoNamePattern.MatchString(o.Name) || o.Name == "." || o.Name == ".." {
+		return ErrInvalidRepoName
+	}
+
+	switch o.Visibility {
+	case "", "public", "private", "internal":
+	default:
+		return ErrInvalidVisibility
+	}
+
+	if o.Homepage != "" {
+		u, err := url.Parse(o.Homepage)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return ErrInvalidHomepage
+		}
+	}
+
+	if len(o.Topics) > MaxTopics {
+		return ErrInvalidTopic
+	}
+	for _, topic := range o.Topics {
+		if !topicPattern.MatchString(topic) {
+			return ErrInvalidTopic
+		}
+	}
+
+	// GitHub needs a way to merge pull requests
+	if isFalse(o.AllowSquashMerge) && isFalse(o.AllowRebaseMerge) && isFalse(o.AllowMergeCommit) {
+		return ErrNoMergeStrategy
+	}
+
+	// An empty repository has no branch to rename
+	if o.DefaultBranch != "" {
+		if !branchNamePattern.MatchString(o.DefaultBranch) {
+			return ErrInvalidDefaultBranch
+		}
+		if !o.AutoInit {
+			return ErrDefaultBranchNoInit
+		}
+	}
+
+	return nil
+}
+
+func isFalse(b *bool) bool {
+	return b != nil && !*b
+}
+
+// toGitHub builds the go-github repository used in the create request
+func (o CreateRepoOptions) toGitHub() *github.Repository {
+	repo := &github.Repository{
+		Name:                github.String(o.Name),
+		AllowSquashMerge:    o.AllowSquashMerge,
+		AllowRebaseMerge:    o.AllowRebaseMerge,
+		AllowMergeCommit:    o.AllowMergeCommit,
+		DeleteBranchOnMerge: o.DeleteBranchOnMerge,
+	}
+	if o.Description != "" {
+		repo.Description = github.String(o.Description)
+	}
+	if o.Homepage != "" {
+		repo.Homepage = github.String(o.Homepage)
+	}
+	if o.Visibility != "" {
+		repo.Visibility = github.String(o.Visibility)
+		repo.Private = github.Bool(o.Visibility != "public")
+	}
+	if o.AutoInit {
+		repo.AutoInit = github.Bool(true)
+	}
+	if o.GitignoreTemplate != "" {
+		repo.GitignoreTemplate = github.String(o.GitignoreTemplate)
+	}
+	if o.LicenseTemplate != "" {
+		repo.LicenseTemplate = github.String(o.LicenseTemplate)
+	}
+	return repo
+}
+
+// CreateRepoWithOptions validates the options and creates the repository
+func (c *Client) CreateRepoWithOptions(ctx context.Context, opts CreateRepoOptions) (*github.Repository, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreateRepoForOwner(ctx, c.owner, opts)
+}
@@ -0,0 +1,87 @@
+package githubapi
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// Typed errors for GitHub API failures, match them with errors.Is
+var (
+	ErrNotFound    = Error("not found")
+	ErrForbidden   = Error("forbidden")
+	ErrRateLimited = Error("rate limit exceeded")
+	ErrConflict    = Error("conflict")
+	ErrValidation  = Error("validation failed")
+
+	// The server can't do what was asked, not an error of GitHub's
+	ErrNotImplemented = Error("not implemented")
+)
+
+// apiError keeps GitHub's own error while matching one of the typed errors
+type apiError struct {
+	kind Error
+	err  error
+}
+
+func (e *apiError) Error() string        { return e.err.Error() }
+func (e *apiError) Unwrap() error        { return e.err }
+func (e *apiError) Is(target error) bool { return target == e.kind }
+
+// wrapError turns go-github errors into typed errors, anything else is returned as is
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range []Error{ErrNotFound, ErrForbidden, ErrRateLimited, ErrConflict, ErrValidation} {
+		if errors.Is(err, kind) {
+			return err
+		}
+	}
+
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &rateErr) || errors.As(err, &abuseErr) {
+		return &apiError{kind: ErrRateLimited, err: err}
+	}
+
+	var respErr *github.ErrorResponse
+	if !errors.As(err, &respErr) || respErr.Response == nil {
+		return err
+	}
+	switch respErr.Response.StatusCode {
+	case http.StatusNotFound:
+		return &apiError{kind: ErrNotFound, err: err}
+	case http.StatusForbidden:
+		return &apiError{kind: ErrForbidden, err: err}
+	case http.StatusTooManyRequests:
+		return &apiError{kind: ErrRateLimited, err: err}
+	case http.StatusConflict, http.StatusMethodNotAllowed: // GitHub answers 405 when a pull request can't be merged
+		return &apiError{kind: ErrConflict, err: err}
+	case http.StatusUnprocessableEntity:
+		return &apiError{kind: ErrValidation, err: err}
+	}
+	return err
+}
+
+// HTTPStatus is the status code our API answers with for the error, 500 when it isn't a typed error
+func HTTPStatus(err error) int {
+	err = wrapError(err)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrValidation):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, ErrNotImplemented):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,292 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrInvalidHookURL         = Error("webhook url must be an http or https url")
+	ErrInvalidHookContentType = Error("webhook content_type must be json or form")
+	ErrInvalidHookEvents      = Error("webhook events can't be empty")
+	ErrInvalidHookID          = Error("invalid webhook id")
+	ErrInvalidDeliveryID      = Error("invalid webhook delivery id")
+)
+
+type Hook struct {
+	URL         string   `json:"url"`
+	ContentType string   `json:"content_type"` // json or form
+	Secret      string   `json:"secret"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+// Validate checks the hook and fills in GitHub's defaults
+func (h *Hook) Validate() error {
+	if !validHookURL(h.URL) {
+		return ErrInvalidHookURL
+	}
+
+	switch h.ContentType {
+	case "":
+		h.ContentType = "json"
+	case "json", "form":
+	default:
+		return ErrInvalidHookContentType
+	}
+
+	if len(h.Events) == 0 {
+		h.Events = []string{"push"}
+	}
+	if h.Active == nil {
+		h.Active = github.Bool(true)
+	}
+	return nil
+}
+
+func validHookURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// HookUpdate changes the fields that are set
+type HookUpdate struct {
+	URL         *string  `json:"url"`
+	ContentType *string  `json:"content_type"`
+	Secret      *string  `json:"secret"` // An empty secret removes it
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+func (u HookUpdate) Validate() error {
+	if u.URL != nil && !validHookURL(*u.URL) {
+		return ErrInvalidHookURL
+	}
+	if u.ContentType != nil && *u.ContentType != "json" && *u.ContentType != "form" {
+		return ErrInvalidHookContentType
+	}
+	if u.Events != nil && len(u.Events) == 0 {
+		return ErrInvalidHookEvents
+	}
+	return nil
+}
+
+func (u HookUpdate) changesConfig() bool {
+	return u.URL != nil || u.ContentType != nil || u.Secret != nil
+}
+
+// RepoHook is a webhook as returned by the API, its secret is never returned
+type RepoHook struct {
+	ID           int64     `json:"id"`
+	URL          string    `json:"url"`
+	ContentType  string    `json:"content_type"`
+	HasSecret    bool      `json:"has_secret"`
+	Events       []string  `json:"events"`
+	Active       bool      `json:"active"`
+	LastResponse string    `json:"last_response"` // Status of the last delivery, empty before any
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func NewRepoHook(hook *github.Hook) RepoHook {
+	converted := RepoHook{
+		ID:          hook.GetID(),
+		URL:         hook.GetConfig().GetURL(),
+		ContentType: hook.GetConfig().GetContentType(),
+		HasSecret:   hook.GetConfig().GetSecret() != "",
+		Events:      hook.Events,
+		Active:      hook.GetActive(),
+		CreatedAt:   hook.GetCreatedAt().Time,
+		UpdatedAt:   hook.GetUpdatedAt().Time,
+	}
+	if status, ok := hook.LastResponse["status"].(string); ok && status != "unused" {
+		converted.LastResponse = status
+	}
+	if converted.Events == nil {
+		converted.Events = []string{}
+	}
+	return converted
+}
+
+type HookDelivery struct {
+	ID          int64     `json:"id"`
+	GUID        string    `json:"guid"` // X-GitHub-Delivery of the delivery
+	Event       string    `json:"event"`
+	Action      string    `json:"action"`
+	Status      string    `json:"status"`
+	StatusCode  int       `json:"status_code"`
+	Duration    float64   `json:"duration"` // In seconds
+	Redelivery  bool      `json:"redelivery"`
+	DeliveredAt time.Time `json:"delivered_at"`
+}
+
+func NewHookDelivery(delivery *github.HookDelivery) HookDelivery {
+	converted := HookDelivery{
+		ID:          delivery.GetID(),
+		GUID:        delivery.GetGUID(),
+		Event:       delivery.GetEvent(),
+		Action:      delivery.GetAction(),
+		Status:      delivery.GetStatus(),
+		StatusCode:  delivery.GetStatusCode(),
+		Redelivery:  delivery.GetRedelivery(),
+		DeliveredAt: delivery.GetDeliveredAt().Time,
+	}
+	if delivery.Duration != nil {
+		converted.Duration = *delivery.Duration
+	}
+	return converted
+}
+
+func (h Hook) toGitHub() *github.Hook {
+	config := &github.HookConfig{
+		URL:         github.String(h.URL),
+		ContentType: github.String(h.ContentType),
+	}
+	if h.Secret != "" {
+		config.Secret = github.String(h.Secret)
+	}
+
+	return &github.Hook{
+		Config: config,
+		Events: h.Events,
+		Active: h.Active,
+	}
+}
+
+func (r *RealGitHubClient) CreateHookForOwner(ctx context.Context, owner, repoName string, hook Hook) (*github.Hook, error) {
+	created, _, err := r.gh.Repositories.CreateHook(ctx, owner, repoName, hook.toGitHub())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return created, nil
+}
+
+func (r *RealGitHubClient) ListHooksForOwner(ctx context.Context, owner, repoName string) ([]*github.Hook, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.Hook, *github.Response, error) {
+		return r.gh.Repositories.ListHooks(ctx, owner, repoName, opts)
+	})
+}
+
+func (r *RealGitHubClient) GetHookForOwner(ctx context.Context, owner, repoName string, id int64) (*github.Hook, error) {
+	hook, _, err := r.gh.Repositories.GetHook(ctx, owner, repoName, id)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return hook, nil
+}
+
+// UpdateHookForOwner edits the configuration apart from the events and active
+// flag, so that the secret is kept unless it's set
+func (r *RealGitHubClient) UpdateHookForOwner(ctx context.Context, owner, repoName string, id int64, update HookUpdate) (*github.Hook, error) {
+	if update.changesConfig() {
+		config := &github.HookConfig{URL: update.URL, ContentType: update.ContentType, Secret: update.Secret}
+		if _, _, err := r.gh.Repositories.EditHookConfiguration(ctx, owner, repoName, id, config); err != nil {
+			return nil, wrapError(err)
+		}
+	}
+
+	if update.Events == nil && update.Active == nil {
+		return r.GetHookForOwner(ctx, owner, repoName, id)
+	}
+	updated, _, err := r.gh.Repositories.EditHook(ctx, owner, repoName, id, &github.Hook{Events: update.Events, Active: update.Active})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return updated, nil
+}
+
+func (r *RealGitHubClient) DeleteHookForOwner(ctx context.Context, owner, repoName string, id int64) error {
+	_, err := r.gh.Repositories.DeleteHook(ctx, owner, repoName, id)
+	return wrapError(err)
+}
+
+// PingHookForOwner sends a ping event to the hook
+func (r *RealGitHubClient) PingHookForOwner(ctx context.Context, owner, repoName string, id int64) error {
+	_, err := r.gh.Repositories.PingHook(ctx, owner, repoName, id)
+	return wrapError(err)
+}
+
+// ListHookDeliveriesForOwner lists a page of deliveries, most recent first. The
+// cursor is GitHub's, empty for the first page, next is empty on the last page
+func (r *RealGitHubClient) ListHookDeliveriesForOwner(ctx context.Context, owner, repoName string, id int64, cursor string, perPage int) ([]*github.HookDelivery, string, error) {
+	deliveries, resp, err := r.gh.Repositories.ListHookDeliveries(ctx, owner, repoName, id, &github.ListCursorOptions{Cursor: cursor, PerPage: perPage})
+	if err != nil {
+		return nil, "", wrapError(err)
+	}
+	return deliveries, resp.Cursor, nil
+}
+
+func (r *RealGitHubClient) RedeliverHookDeliveryForOwner(ctx context.Context, owner, repoName string, hookID, deliveryID int64) error {
+	_, _, err := r.gh.Repositories.RedeliverHookDelivery(ctx, owner, repoName, hookID, deliveryID)
+	var accepted *github.AcceptedError
+	if errors.As(err, &accepted) {
+		return nil
+	}
+	return wrapError(err)
+}
+
+func (c *Client) CreateHook(ctx context.Context, repoName string, hook Hook) (*github.Hook, error) {
+	if err := hook.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreateHookForOwner(ctx, c.owner, repoName, hook)
+}
+
+func (c *Client) ListHooks(ctx context.Context, repoName string) ([]*github.Hook, error) {
+	return c.gh.ListHooksForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) GetHook(ctx context.Context, repoName string, id int64) (*github.Hook, error) {
+	if id < 1 {
+		return nil, ErrInvalidHookID
+	}
+	return c.gh.GetHookForOwner(ctx, c.owner, repoName, id)
+}
+
+func (c *Client) UpdateHook(ctx context.Context, repoName string, id int64, update HookUpdate) (*github.Hook, error) {
+	if id < 1 {
+		return nil, ErrInvalidHookID
+	}
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.UpdateHookForOwner(ctx, c.owner, repoName, id, update)
+}
+
+func (c *Client) DeleteHook(ctx context.Context, repoName string, id int64) error {
+	if id < 1 {
+		return ErrInvalidHookID
+	}
+	return c.gh.DeleteHookForOwner(ctx, c.owner, repoName, id)
+}
+
+func (c *Client) PingHook(ctx context.Context, repoName string, id int64) error {
+	if id < 1 {
+		return ErrInvalidHookID
+	}
+	return c.gh.PingHookForOwner(ctx, c.owner, repoName, id)
+}
+
+func (c *Client) ListHookDeliveries(ctx context.Context, repoName string, id int64, cursor string, perPage int) ([]*github.HookDelivery, string, error) {
+	if id < 1 {
+		return nil, "", ErrInvalidHookID
+	}
+	if perPage < 1 || perPage > MaxPageSize {
+		return nil, "", ErrInvalidPageSize
+	}
+	return c.gh.ListHookDeliveriesForOwner(ctx, c.owner, repoName, id, cursor, perPage)
+}
+
+func (c *Client) RedeliverHookDelivery(ctx context.Context, repoName string, hookID, deliveryID int64) error {
+	if hookID < 1 {
+		return ErrInvalidHookID
+	}
+	if deliveryID < 1 {
+		return ErrInvalidDeliveryID
+	}
+	return c.gh.RedeliverHookDeliveryForOwner(ctx, c.owner, repoName, hookID, deliveryID)
+}
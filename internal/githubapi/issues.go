@@ -0,0 +1,38 @@
+package githubapi
+
+import (
+	"context"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// ListIssuesForOwner lists every issue of the repository in any state. GitHub
+// returns pull requests as issues too, they have PullRequestLinks set.
+func (r *RealGitHubClient) ListIssuesForOwner(ctx context.Context, owner, repoName string) ([]*github.Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State: "all",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var allIssues []*github.Issue
+	for {
+		issues, resp, err := r.gh.Issues.ListByRepo(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allIssues = append(allIssues, issues...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
+func (c *Client) ListIssues(ctx context.Context, repoName string) ([]*github.Issue, error) {
+	return c.gh.ListIssuesForOwner(ctx, c.owner, repoName)
+}
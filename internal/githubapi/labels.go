@@ -0,0 +1,112 @@
+package githubapi
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrInvalidLabelName  = Error("label name is required")
+	ErrInvalidLabelColor = Error("label color must be a 6 digit hex code")
+)
+
+var labelColorPattern = regexp.MustCompile(`^[0-9a-fA-F]{6}$`)
+
+type Label struct {
+	Name        string `json:"name"`
+	Color       string `json:"color"` // Hex code without the leading #
+	Description string `json:"description"`
+}
+
+// Validate checks the label, a leading # on the color is dropped
+func (l *Label) Validate() error {
+	if strings.TrimSpace(l.Name) == "" {
+		return ErrInvalidLabelName
+	}
+	l.Color = strings.TrimPrefix(l.Color, "#")
+	if !labelColorPattern.MatchString(l.Color) {
+		return ErrInvalidLabelColor
+	}
+	return nil
+}
+
+func (r *RealGitHubClient) CreateLabelForOwner(ctx context.Context, owner, repoName string, label Label) (*github.Label, error) {
+	newLabel := &github.Label{
+		Name:        github.String(label.Name),
+		Color:       github.String(label.Color),
+		Description: github.String(label.Description),
+	}
+
+	created, _, err := r.gh.Issues.CreateLabel(ctx, owner, repoName, newLabel)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return created, nil
+}
+
+// UpdateLabelForOwner replaces the label currently called name, which can rename it
+func (r *RealGitHubClient) UpdateLabelForOwner(ctx context.Context, owner, repoName, name string, label Label) (*github.Label, error) {
+	updated, _, err := r.gh.Issues.EditLabel(ctx, owner, repoName, name, &github.Label{
+		Name:        github.String(label.Name),
+		Color:       github.String(label.Color),
+		Description: github.String(label.Description),
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return updated, nil
+}
+
+func (r *RealGitHubClient) DeleteLabelForOwner(ctx context.Context, owner, repoName, name string) error {
+	_, err := r.gh.Issues.DeleteLabel(ctx, owner, repoName, name)
+	return wrapError(err)
+}
+
+func NewLabel(label *github.Label) Label {
+	return Label{Name: label.GetName(), Color: label.GetColor(), Description: label.GetDescription()}
+}
+
+func (c *Client) CreateLabel(ctx context.Context, repoName string, label Label) (*github.Label, error) {
+	if err := label.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreateLabelForOwner(ctx, c.owner, repoName, label)
+}
+
+func (r *RealGitHubClient) ListLabelsForOwner(ctx context.Context, owner, repoName string) ([]*github.Label, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var allLabels []*github.Label
+	for {
+		labels, resp, err := r.gh.Issues.ListLabels(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		allLabels = append(allLabels, labels...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allLabels, nil
+}
+
+func (c *Client) ListLabels(ctx context.Context, repoName string) ([]*github.Label, error) {
+	return c.gh.ListLabelsForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) UpdateLabel(ctx context.Context, repoName, name string, label Label) (*github.Label, error) {
+	if err := label.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.UpdateLabelForOwner(ctx, c.owner, repoName, name, label)
+}
+
+func (c *Client) DeleteLabel(ctx context.Context, repoName, name string) error {
+	return c.gh.DeleteLabelForOwner(ctx, c.owner, repoName, name)
+}
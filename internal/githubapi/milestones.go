@@ -0,0 +1,107 @@
+package githubapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrInvalidMilestoneTitle = Error("milestone title is required")
+	ErrInvalidMilestoneState = Error("milestone state must be open or closed")
+)
+
+type Milestone struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	State       string     `json:"state"` // open or closed, open by default
+	DueOn       *time.Time `json:"due_on"`
+}
+
+// Validate checks the milestone, an empty state becomes open
+func (m *Milestone) Validate() error {
+	if strings.TrimSpace(m.Title) == "" {
+		return ErrInvalidMilestoneTitle
+	}
+	switch m.State {
+	case "":
+		m.State = "open"
+	case "open", "closed":
+	default:
+		return ErrInvalidMilestoneState
+	}
+	return nil
+}
+
+func NewMilestone(milestone *github.Milestone) Milestone {
+	return Milestone{
+		Title:       milestone.GetTitle(),
+		Description: milestone.GetDescription(),
+		State:       milestone.GetState(),
+		DueOn:       timestampPtr(milestone.DueOn),
+	}
+}
+
+func (m Milestone) toGitHub() *github.Milestone {
+	milestone := &github.Milestone{
+		Title:       github.String(m.Title),
+		Description: github.String(m.Description),
+		State:       github.String(m.State),
+	}
+	if m.DueOn != nil {
+		milestone.DueOn = &github.Timestamp{Time: *m.DueOn}
+	}
+	return milestone
+}
+
+// ListMilestonesForOwner lists open and closed milestones
+func (r *RealGitHubClient) ListMilestonesForOwner(ctx context.Context, owner, repoName string) ([]*github.Milestone, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.Milestone, *github.Response, error) {
+		return r.gh.Issues.ListMilestones(ctx, owner, repoName, &github.MilestoneListOptions{State: "all", ListOptions: *opts})
+	})
+}
+
+func (r *RealGitHubClient) CreateMilestoneForOwner(ctx context.Context, owner, repoName string, milestone Milestone) (*github.Milestone, error) {
+	created, _, err := r.gh.Issues.CreateMilestone(ctx, owner, repoName, milestone.toGitHub())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return created, nil
+}
+
+func (r *RealGitHubClient) UpdateMilestoneForOwner(ctx context.Context, owner, repoName string, number int, milestone Milestone) (*github.Milestone, error) {
+	updated, _, err := r.gh.Issues.EditMilestone(ctx, owner, repoName, number, milestone.toGitHub())
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return updated, nil
+}
+
+func (r *RealGitHubClient) DeleteMilestoneForOwner(ctx context.Context, owner, repoName string, number int) error {
+	_, err := r.gh.Issues.DeleteMilestone(ctx, owner, repoName, number)
+	return wrapError(err)
+}
+
+func (c *Client) ListMilestones(ctx context.Context, repoName string) ([]*github.Milestone, error) {
+	return c.gh.ListMilestonesForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) CreateMilestone(ctx context.Context, repoName string, milestone Milestone) (*github.Milestone, error) {
+	if err := milestone.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreateMilestoneForOwner(ctx, c.owner, repoName, milestone)
+}
+
+func (c *Client) UpdateMilestone(ctx context.Context, repoName string, number int, milestone Milestone) (*github.Milestone, error) {
+	if err := milestone.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.UpdateMilestoneForOwner(ctx, c.owner, repoName, number, milestone)
+}
+
+func (c *Client) DeleteMilestone(ctx context.Context, repoName string, number int) error {
+	return c.gh.DeleteMilestoneForOwner(ctx, c.owner, repoName, number)
+}
@@ -0,0 +1,237 @@
+package githubapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/google/go-github/v67/github"
+)
+
+const (
+	DefaultPageSize = 30
+	MaxPageSize     = 100
+
+	// Page size used when fetching from GitHub, cursors point into pages of this size
+	githubPageSize = 100
+)
+
+var (
+	ErrInvalidPageSize = Error("invalid value for page_size")
+	ErrInvalidCursor   = Error("invalid value for cursor")
+)
+
+// Cursor is the position of the next item to return, it's handed to API
+// users as an opaque string
+type Cursor struct {
+	Page   int    `json:"p"`           // GitHub page, 0 when paging through a sorted listing
+	Offset int    `json:"o"`           // Index in the GitHub page or in the sorted listing
+	Sort   string `json:"s,omitempty"` // Sort the cursor was created for
+}
+
+func (c Cursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.Page < 0 || c.Offset < 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// PageRequest holds the page_size and cursor query parameters of a list endpoint
+type PageRequest struct {
+	Size   int
+	Cursor Cursor
+}
+
+// ParsePageRequest reads page_size and cursor, ok is false when neither is set
+func ParsePageRequest(query url.Values) (req PageRequest, ok bool, err error) {
+	sizeStr, cursorStr := query.Get("page_size"), query.Get("cursor")
+	if sizeStr == "" && cursorStr == "" {
+		return PageRequest{}, false, nil
+	}
+
+	req.Size = DefaultPageSize
+	if sizeStr != "" {
+		req.Size, err = strconv.Atoi(sizeStr)
+		if err != nil || req.Size < 1 || req.Size > MaxPageSize {
+			return PageRequest{}, false, ErrInvalidPageSize
+		}
+	}
+
+	if cursorStr != "" {
+		req.Cursor, err = DecodeCursor(cursorStr)
+		if err != nil {
+			return PageRequest{}, false, err
+		}
+	}
+
+	return req, true, nil
+}
+
+// NextLink builds an RFC 5988 Link header pointing to the next page of the request URL
+func NextLink(requestURL *url.URL, nextCursor string) string {
+	next := *requestURL
+	query := next.Query()
+	query.Set("cursor", nextCursor)
+	next.RawQuery = query.Encode()
+	return fmt.Sprintf(`<%s>; rel="next"`, next.String())
+}
+
+// Iterator walks through a paginated GitHub listing, fetching a page only when it's needed
+type Iterator[T any] struct {
+	fetch func(ctx context.Context, page, perPage int) ([]T, int, error)
+
+	buf  []T
+	pos  int  // Next item in buf
+	page int  // Page held in buf, 0 before the first fetch
+	next int  // Next page to fetch
+	skip int  // Items to skip in the first fetched page
+	done bool // No pages left after buf
+}
+
+func newIterator[T any](cursor Cursor, fetch func(ctx context.Context, page, perPage int) ([]T, int, error)) *Iterator[T] {
+	page := cursor.Page
+	if page < 1 {
+		page = 1
+	}
+	return &Iterator[T]{fetch: fetch, next: page, skip: cursor.Offset}
+}
+
+// Next returns the next item, ok is false once the listing is exhausted
+func (it *Iterator[T]) Next(ctx context.Context) (item T, ok bool, err error) {
+	for it.pos >= len(it.buf) {
+		if it.done {
+			return item, false, nil
+		}
+
+		items, nextPage, err := it.fetch(ctx, it.next, githubPageSize)
+		if err != nil {
+			return item, false, err
+		}
+
+		it.buf, it.pos, it.page = items, it.skip, it.next
+		it.skip = 0
+		if nextPage == 0 {
+			it.done = true
+		} else {
+			it.next = nextPage
+		}
+	}
+
+	item = it.buf[it.pos]
+	it.pos++
+	return item, true, nil
+}
+
+// Cursor returns where the iterator stopped, or an empty string if nothing is left
+func (it *Iterator[T]) Cursor() string {
+	switch {
+	case it.page == 0:
+		return Cursor{Page: it.next, Offset: it.skip}.Encode()
+	case it.pos < len(it.buf):
+		return Cursor{Page: it.page, Offset: it.pos}.Encode()
+	case it.done:
+		return ""
+	default:
+		return Cursor{Page: it.next}.Encode()
+	}
+}
+
+// RepoIterator iterates over the owner's repositories starting at the cursor
+func (c *Client) RepoIterator(cursor Cursor) *Iterator[*github.Repository] {
+	return newIterator(cursor, func(ctx context.Context, page, perPage int) ([]*github.Repository, int, error) {
+		return c.gh.ListReposPageForOwner(ctx, c.owner, page, perPage)
+	})
+}
+
+// PullRequestIterator iterates over a repository's pull requests starting at the
+// cursor, only the filters GitHub applies itself are used
+func (c *Client) PullRequestIterator(repoName string, filter PullRequestFilter, cursor Cursor) *Iterator[*github.PullRequest] {
+	return newIterator(cursor, func(ctx context.Context, page, perPage int) ([]*github.PullRequest, int, error) {
+		return c.gh.ListPullRequestsPageForOwner(ctx, c.owner, repoName, filter, page, perPage)
+	})
+}
+
+// ListRepositoriesPage returns one page of the owner's repositories matching the
+// filter, along with the cursor of the next page
+func (c *Client) ListRepositoriesPage(ctx context.Context, filter RepoFilter, req PageRequest) ([]Repository, string, error) {
+	// A cursor only makes sense for the sort order it was created with
+	if req.Cursor != (Cursor{}) && req.Cursor.Sort != filter.Sort {
+		return nil, "", ErrInvalidCursor
+	}
+
+	// Sorting needs every repository, so page through the sorted listing instead
+	if filter.Sort != "" {
+		repos, err := c.ListRepositories(ctx, filter)
+		if err != nil {
+			return nil, "", err
+		}
+		if req.Cursor.Offset > len(repos) {
+			return nil, "", ErrInvalidCursor
+		}
+
+		end := req.Cursor.Offset + req.Size
+		if end >= len(repos) {
+			return repos[req.Cursor.Offset:], "", nil
+		}
+		next := Cursor{Offset: end, Sort: filter.Sort}
+		return repos[req.Cursor.Offset:end], next.Encode(), nil
+	}
+
+	it := c.RepoIterator(req.Cursor)
+	repos := []Repository{}
+	for len(repos) < req.Size {
+		repo, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		if !ok {
+			break
+		}
+
+		converted := NewRepository(repo)
+		if filter.Matches(converted) {
+			repos = append(repos, converted)
+		}
+	}
+
+	return repos, it.Cursor(), nil
+}
+
+// ListPullRequestsPage returns one page of a repository's pull requests matching
+// the filter, along with the cursor of the next page
+func (c *Client) ListPullRequestsPage(ctx context.Context, repoName string, filter PullRequestFilter, req PageRequest) ([]*github.PullRequest, string, error) {
+	if req.Cursor.Sort != "" {
+		return nil, "", ErrInvalidCursor
+	}
+
+	it := c.PullRequestIterator(repoName, filter, req.Cursor)
+	prs := []*github.PullRequest{}
+	for len(prs) < req.Size {
+		pr, ok, err := it.Next(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		if !ok {
+			break
+		}
+		if filter.Matches(pr) {
+			prs = append(prs, pr)
+		}
+	}
+
+	return prs, it.Cursor(), nil
+}
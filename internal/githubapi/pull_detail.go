@@ -0,0 +1,240 @@
+package githubapi
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var ErrInvalidPullNumber = Error("invalid pull request number")
+
+// PullRequestDetail is a pull request with its reviews, checks and changed files
+type PullRequestDetail struct {
+	PullRequest
+	Body           string `json:"body"`
+	Mergeable      *bool  `json:"mergeable"` // Unknown while GitHub is still computing it
+	Commits        int    `json:"commits"`
+	Additions      int    `json:"additions"`
+	Deletions      int    `json:"deletions"`
+	ChangedFiles   int    `json:"changed_files"`
+	Comments       int    `json:"comments"`
+	ReviewComments int    `json:"review_comments"`
+
+	Reviews   []ReviewState  `json:"reviews"`
+	Status    CombinedStatus `json:"status"`
+	CheckRuns []CheckRun     `json:"check_runs"`
+	Files     []ChangedFile  `json:"files"`
+}
+
+// ReviewState is a reviewer's latest verdict on the pull request
+type ReviewState struct {
+	Reviewer    string     `json:"reviewer"`
+	State       string     `json:"state"` // APPROVED, CHANGES_REQUESTED, COMMENTED or DISMISSED
+	SubmittedAt *time.Time `json:"submitted_at"`
+}
+
+// CombinedStatus is the commit status of the head commit, across every context
+type CombinedStatus struct {
+	State    string          `json:"state"` // success, failure or pending
+	Contexts []StatusContext `json:"contexts"`
+}
+
+type StatusContext struct {
+	Context     string `json:"context"`
+	State       string `json:"state"`
+	Description string `json:"description"`
+	TargetURL   string `json:"target_url"`
+}
+
+type CheckRun struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`     // queued, in_progress or completed
+	Conclusion string `json:"conclusion"` // Set once completed
+	HTMLURL    string `json:"html_url"`
+}
+
+type ChangedFile struct {
+	Filename  string `json:"filename"`
+	Status    string `json:"status"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Changes   int    `json:"changes"`
+}
+
+// latestReviews keeps each reviewer's last review. A comment doesn't replace an
+// earlier approval or change request, it's only kept when there's nothing else
+func latestReviews(reviews []*github.PullRequestReview) []ReviewState {
+	byReviewer := map[string]ReviewState{}
+	for _, review := range reviews {
+		reviewer := review.GetUser().GetLogin()
+		state := review.GetState()
+		if _, seen := byReviewer[reviewer]; seen && state == "COMMENTED" {
+			continue
+		}
+		if state == "PENDING" {
+			continue
+		}
+		byReviewer[reviewer] = ReviewState{Reviewer: reviewer, State: state, SubmittedAt: timestampPtr(review.SubmittedAt)}
+	}
+
+	states := make([]ReviewState, 0, len(byReviewer))
+	for _, state := range byReviewer {
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Reviewer < states[j].Reviewer })
+	return states
+}
+
+// listAll collects every page of a go-github listing
+func listAll[T any](fetch func(opts *github.ListOptions) ([]T, *github.Response, error)) ([]T, error) {
+	opts := &github.ListOptions{PerPage: githubPageSize}
+
+	var all []T
+	for {
+		items, resp, err := fetch(opts)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		all = append(all, items...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+func (r *RealGitHubClient) GetPullRequestForOwner(ctx context.Context, owner, repoName string, number int) (*github.PullRequest, error) {
+	pr, _, err := r.gh.PullRequests.Get(ctx, owner, repoName, number)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return pr, nil
+}
+
+func (r *RealGitHubClient) ListReviewsForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.PullRequestReview, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+		return r.gh.PullRequests.ListReviews(ctx, owner, repoName, number, opts)
+	})
+}
+
+func (r *RealGitHubClient) ListPullRequestFilesForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.CommitFile, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+		return r.gh.PullRequests.ListFiles(ctx, owner, repoName, number, opts)
+	})
+}
+
+func (r *RealGitHubClient) GetCombinedStatusForOwner(ctx context.Context, owner, repoName, ref string) (*github.CombinedStatus, error) {
+	status, _, err := r.gh.Repositories.GetCombinedStatus(ctx, owner, repoName, ref, &github.ListOptions{PerPage: githubPageSize})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return status, nil
+}
+
+func (r *RealGitHubClient) ListCheckRunsForOwner(ctx context.Context, owner, repoName, ref string) ([]*github.CheckRun, error) {
+	return listAll(func(opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+		results, resp, err := r.gh.Checks.ListCheckRunsForRef(ctx, owner, repoName, ref, &github.ListCheckRunsOptions{ListOptions: *opts})
+		if err != nil {
+			return nil, resp, err
+		}
+		return results.CheckRuns, resp, nil
+	})
+}
+
+// ReviewStates returns each reviewer's latest review state on the pull request
+func (c *Client) ReviewStates(ctx context.Context, repoName string, number int) ([]ReviewState, error) {
+	reviews, err := c.gh.ListReviewsForOwner(ctx, c.owner, repoName, number)
+	if err != nil {
+		return nil, err
+	}
+	return latestReviews(reviews), nil
+}
+
+// GetPullRequestDetail fetches the pull request, then its reviews, statuses,
+// check runs and files concurrently
+func (c *Client) GetPullRequestDetail(ctx context.Context, repoName string, number int) (PullRequestDetail, error) {
+	if number < 1 {
+		return PullRequestDetail{}, ErrInvalidPullNumber
+	}
+
+	// Everything else hangs off the head commit
+	pr, err := c.gh.GetPullRequestForOwner(ctx, c.owner, repoName, number)
+	if err != nil {
+		return PullRequestDetail{}, err
+	}
+	sha := pr.GetHead().GetSHA()
+
+	var (
+		reviews []*github.PullRequestReview
+		status  *github.CombinedStatus
+		checks  []*github.CheckRun
+		files   []*github.CommitFile
+	)
+	err = FanOut(ctx, MaxConcurrentRequests,
+		func(ctx context.Context) (err error) {
+			reviews, err = c.gh.ListReviewsForOwner(ctx, c.owner, repoName, number)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			status, err = c.gh.GetCombinedStatusForOwner(ctx, c.owner, repoName, sha)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			checks, err = c.gh.ListCheckRunsForOwner(ctx, c.owner, repoName, sha)
+			return err
+		},
+		func(ctx context.Context) (err error) {
+			files, err = c.gh.ListPullRequestFilesForOwner(ctx, c.owner, repoName, number)
+			return err
+		},
+	)
+	if err != nil {
+		return PullRequestDetail{}, err
+	}
+
+	detail := PullRequestDetail{
+		PullRequest:    NewPullRequest(pr),
+		Body:           pr.GetBody(),
+		Mergeable:      pr.Mergeable,
+		Commits:        pr.GetCommits(),
+		Additions:      pr.GetAdditions(),
+		Deletions:      pr.GetDeletions(),
+		ChangedFiles:   pr.GetChangedFiles(),
+		Comments:       pr.GetComments(),
+		ReviewComments: pr.GetReviewComments(),
+		Reviews:        latestReviews(reviews),
+		Status:         CombinedStatus{State: status.GetState(), Contexts: []StatusContext{}},
+		CheckRuns:      make([]CheckRun, 0, len(checks)),
+		Files:          make([]ChangedFile, 0, len(files)),
+	}
+	for _, s := range status.Statuses {
+		detail.Status.Contexts = append(detail.Status.Contexts, StatusContext{
+			Context:     s.GetContext(),
+			State:       s.GetState(),
+			Description: s.GetDescription(),
+			TargetURL:   s.GetTargetURL(),
+		})
+	}
+	for _, check := range checks {
+		detail.CheckRuns = append(detail.CheckRuns, CheckRun{
+			Name:       check.GetName(),
+			Status:     check.GetStatus(),
+			Conclusion: check.GetConclusion(),
+			HTMLURL:    check.GetHTMLURL(),
+		})
+	}
+	for _, file := range files {
+		detail.Files = append(detail.Files, ChangedFile{
+			Filename:  file.GetFilename(),
+			Status:    file.GetStatus(),
+			Additions: file.GetAdditions(),
+			Deletions: file.GetDeletions(),
+			Changes:   file.GetChanges(),
+		})
+	}
+
+	return detail, nil
+}
@@ -0,0 +1,248 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// The search API returns at most this many results for a query
+const searchLimit = 1000
+
+var (
+	ErrSearchUnsupported = Error("filter can't be expressed as a search query")
+	ErrSearchIncomplete  = Error("search results are incomplete")
+	ErrOwnerPullsSort    = Error("pull requests across repositories can only be sorted by updated")
+)
+
+// RepoPullRequest is a pull request summary along with its repository
+type RepoPullRequest struct {
+	Repository string `json:"repository"`
+	PullRequest
+}
+
+// searchQuery builds the search query for the owner's pull requests matching the filter
+func (f PullRequestFilter) searchQuery(owner string) (string, error) {
+	terms := []string{"is:pr", "user:" + owner}
+
+	switch f.State {
+	case "", "open":
+		terms = append(terms, "is:open")
+	case "closed":
+		terms = append(terms, "is:closed")
+	case "merged":
+		terms = append(terms, "is:merged")
+	}
+
+	if f.Base != "" {
+		terms = append(terms, "base:"+f.Base)
+	}
+	if f.Head != "" {
+		branch := f.Head
+		if user, name, ok := strings.Cut(branch, ":"); ok {
+			// Search only knows branch names, so heads in forks have to be listed
+			if !strings.EqualFold(user, owner) {
+				return "", ErrSearchUnsupported
+			}
+			branch = name
+		}
+		terms = append(terms, "head:"+branch)
+	}
+	if f.Author != "" {
+		terms = append(terms, "author:"+f.Author)
+	}
+	if f.Label != "" {
+		terms = append(terms, `label:"`+f.Label+`"`)
+	}
+	if f.Draft != nil {
+		if *f.Draft {
+			terms = append(terms, "draft:true")
+		} else {
+			terms = append(terms, "draft:false")
+		}
+	}
+	if term := searchRange("created", f.CreatedAfter, f.CreatedBefore); term != "" {
+		terms = append(terms, term)
+	}
+	if term := searchRange("updated", f.UpdatedAfter, f.UpdatedBefore); term != "" {
+		terms = append(terms, term)
+	}
+
+	return strings.Join(terms, " "), nil
+}
+
+func searchRange(qualifier string, after, before *time.Time) string {
+	switch {
+	case after != nil && before != nil:
+		return qualifier + ":" + after.UTC().Format(time.RFC3339) + ".." + before.UTC().Format(time.RFC3339)
+	case after != nil:
+		return qualifier + ":>=" + after.UTC().Format(time.RFC3339)
+	case before != nil:
+		return qualifier + ":<=" + before.UTC().Format(time.RFC3339)
+	}
+	return ""
+}
+
+// SearchPullRequestsForOwner finds the owner's pull requests matching the filter
+// through the search API, ordered by update time
+func (r *RealGitHubClient) SearchPullRequestsForOwner(ctx context.Context, owner string, filter PullRequestFilter) ([]*github.Issue, error) {
+	query, err := filter.searchQuery(owner)
+	if err != nil {
+		return nil, err
+	}
+
+	order := "desc"
+	if filter.Direction == "asc" {
+		order = "asc"
+	}
+	opts := &github.SearchOptions{Sort: "updated", Order: order, ListOptions: github.ListOptions{PerPage: 100}}
+
+	var allIssues []*github.Issue
+	for {
+		result, resp, err := r.gh.Search.Issues(ctx, query, opts)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if result.GetIncompleteResults() || result.GetTotal() > searchLimit {
+			return nil, ErrSearchIncomplete
+		}
+
+		allIssues = append(allIssues, result.Issues...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allIssues, nil
+}
+
+// newSearchedPullRequest converts a search result, which unlike a listed pull
+// request has no branches, reviewers or mergeable state
+func newSearchedPullRequest(issue *github.Issue) RepoPullRequest {
+	state := issue.GetState()
+	if !issue.GetPullRequestLinks().GetMergedAt().IsZero() {
+		state = "merged"
+	}
+
+	labels := []string{}
+	for _, label := range issue.Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	converted := PullRequest{
+		Number:        issue.GetNumber(),
+		Title:         issue.GetTitle(),
+		Author:        issue.GetUser().GetLogin(),
+		State:         state,
+		Draft:         issue.GetDraft(),
+		Labels:        labels,
+		Reviewers:     []string{},
+		TeamReviewers: []string{},
+		CreatedAt:     timestampPtr(issue.CreatedAt),
+		UpdatedAt:     timestampPtr(issue.UpdatedAt),
+		HTMLURL:       issue.GetHTMLURL(),
+	}
+	if converted.CreatedAt != nil {
+		converted.AgeDays = int(time.Since(*converted.CreatedAt).Hours() / 24)
+	}
+	return RepoPullRequest{Repository: path.Base(issue.GetRepositoryURL()), PullRequest: converted}
+}
+
+// selectsAll reports whether the filter keeps every repository
+func (f RepoFilter) selectsAll() bool {
+	return f.Visibility == "" && f.Archived == nil && f.Fork == nil && f.Language == "" &&
+		f.Topic == "" && f.NamePrefix == "" && f.NameRegex == nil
+}
+
+// ListOwnerPullRequests lists the pull requests matching the filter across the
+// owner's repositories selected by repoFilter, most recently updated first
+// unless the filter's direction is asc. It searches when every repository is
+// selected and the filter fits a search query, and lists each repository otherwise.
+// searched tells which way was taken. Any sort but updated is ErrOwnerPullsSort
+func (c *Client) ListOwnerPullRequests(ctx context.Context, repoFilter RepoFilter, filter PullRequestFilter) (prs []RepoPullRequest, searched bool, err error) {
+	if filter.Sort != "" && filter.Sort != "updated" {
+		return nil, false, ErrOwnerPullsSort
+	}
+	filter.Sort = "updated"
+	if filter.Direction == "" {
+		// GitHub would list oldest update first
+		filter.Direction = "desc"
+	}
+
+	if repoFilter.selectsAll() {
+		// Only what search can't answer falls back to listing, a rate limit or
+		// a denied token would fail every repository's listing too
+		issues, err := c.gh.SearchPullRequestsForOwner(ctx, c.owner, filter)
+		if err == nil {
+			prs = make([]RepoPullRequest, 0, len(issues))
+			for _, issue := range issues {
+				prs = append(prs, newSearchedPullRequest(issue))
+			}
+			sortByUpdated(prs, filter.Direction)
+			return prs, true, nil
+		}
+		if !errors.Is(err, ErrSearchUnsupported) && !errors.Is(err, ErrSearchIncomplete) {
+			return nil, false, err
+		}
+	}
+
+	repos, err := c.ListRepositories(ctx, repoFilter)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var mu sync.Mutex
+	prs = []RepoPullRequest{}
+	tasks := make([]func(ctx context.Context) error, 0, len(repos))
+	for _, repo := range repos {
+		tasks = append(tasks, func(ctx context.Context) error {
+			repoPRs, err := c.ListPullRequestsWithFilter(ctx, repo.Name, filter, -1)
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, pr := range repoPRs {
+				prs = append(prs, RepoPullRequest{Repository: repo.Name, PullRequest: NewPullRequest(pr)})
+			}
+			return nil
+		})
+	}
+	if err := FanOut(ctx, MaxConcurrentRequests, tasks...); err != nil {
+		return nil, false, err
+	}
+
+	sortByUpdated(prs, filter.Direction)
+	return prs, false, nil
+}
+
+// sortByUpdated sorts by update time, pull requests without one go last
+func sortByUpdated(prs []RepoPullRequest, direction string) {
+	sort.SliceStable(prs, func(i, j int) bool {
+		a, b := prs[i].UpdatedAt, prs[j].UpdatedAt
+		if a == nil || b == nil {
+			return b == nil && a != nil
+		}
+		if direction == "asc" {
+			return a.Before(*b)
+		}
+		return a.After(*b)
+	})
+}
+
+// CountByRepository counts the pull requests of each repository
+func CountByRepository(prs []RepoPullRequest) map[string]int {
+	counts := map[string]int{}
+	for _, pr := range prs {
+		counts[pr.Repository]++
+	}
+	return counts
+}
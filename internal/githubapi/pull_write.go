@@ -0,0 +1,228 @@
+package githubapi
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrMissingPullFields  = Error("title, head and base are required")
+	ErrSameHeadBase       = Error("head and base must be different branches")
+	ErrEmptyTitle         = Error("title cannot be empty")
+	ErrNothingToUpdate    = Error("nothing to update")
+	ErrInvalidMergeMethod = Error("merge_method must be merge, squash or rebase")
+	ErrInvalidSHA         = Error("invalid value for sha, use the full commit SHA")
+)
+
+var shaPattern = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// CreatePullRequestOptions opens a pull request merging head into base
+type CreatePullRequestOptions struct {
+	Title               string `json:"title"`
+	Head                string `json:"head"` // branch name, or user:branch for forks
+	Base                string `json:"base"`
+	Body                string `json:"body"`
+	Draft               bool   `json:"draft"`
+	MaintainerCanModify *bool  `json:"maintainer_can_modify"`
+}
+
+func (o CreatePullRequestOptions) Validate() error {
+	if strings.TrimSpace(o.Title) == "" || o.Head == "" || o.Base == "" {
+		return ErrMissingPullFields
+	}
+	if o.Head == o.Base {
+		return ErrSameHeadBase
+	}
+	return nil
+}
+
+// UpdatePullRequestOptions holds the fields to change, unset ones are left as they are
+type UpdatePullRequestOptions struct {
+	Title  *string   `json:"title"`
+	Body   *string   `json:"body"`
+	Base   *string   `json:"base"`
+	State  *string   `json:"state"` // open or closed
+	Draft  *bool     `json:"draft"`
+	Labels *[]string `json:"labels"` // Replaces every label, an empty list removes them all
+}
+
+func (o UpdatePullRequestOptions) Validate() error {
+	if o.Title == nil && o.Body == nil && o.Base == nil && o.State == nil && o.Draft == nil && o.Labels == nil {
+		return ErrNothingToUpdate
+	}
+	if o.Title != nil && strings.TrimSpace(*o.Title) == "" {
+		return ErrEmptyTitle
+	}
+	if o.Base != nil && *o.Base == "" {
+		return ErrMissingPullFields
+	}
+	if o.State != nil && *o.State != "open" && *o.State != "closed" {
+		return ErrInvalidState
+	}
+	return nil
+}
+
+// MergePullRequestOptions picks how the pull request is merged. With SHA set
+// the merge only goes through if the head is still at that commit
+type MergePullRequestOptions struct {
+	Method        string `json:"merge_method"` // merge, squash or rebase, defaults to merge
+	SHA           string `json:"sha"`
+	CommitTitle   string `json:"commit_title"`
+	CommitMessage string `json:"commit_message"`
+}
+
+func (o MergePullRequestOptions) Validate() error {
+	switch o.Method {
+	case "", "merge", "squash", "rebase":
+	default:
+		return ErrInvalidMergeMethod
+	}
+	if o.SHA != "" && !shaPattern.MatchString(o.SHA) {
+		return ErrInvalidSHA
+	}
+	return nil
+}
+
+func (r *RealGitHubClient) CreatePullRequestForOwner(ctx context.Context, owner, repoName string, opts CreatePullRequestOptions) (*github.PullRequest, error) {
+	pr, _, err := r.gh.PullRequests.Create(ctx, owner, repoName, &github.NewPullRequest{
+		Title:               github.String(opts.Title),
+		Head:                github.String(opts.Head),
+		Base:                github.String(opts.Base),
+		Body:                github.String(opts.Body),
+		Draft:               github.Bool(opts.Draft),
+		MaintainerCanModify: opts.MaintainerCanModify,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return pr, nil
+}
+
+// UpdatePullRequestForOwner edits the pull request. Labels go through the issues
+// API and the draft status through GraphQL, the REST API can't change it
+func (r *RealGitHubClient) UpdatePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts UpdatePullRequestOptions) (*github.PullRequest, error) {
+	if opts.Title != nil || opts.Body != nil || opts.Base != nil || opts.State != nil {
+		edit := &github.PullRequest{Title: opts.Title, Body: opts.Body, State: opts.State}
+		if opts.Base != nil {
+			edit.Base = &github.PullRequestBranch{Ref: opts.Base}
+		}
+		if _, _, err := r.gh.PullRequests.Edit(ctx, owner, repoName, number, edit); err != nil {
+			return nil, wrapError(err)
+		}
+	}
+
+	if opts.Labels != nil {
+		if _, _, err := r.gh.Issues.ReplaceLabelsForIssue(ctx, owner, repoName, number, *opts.Labels); err != nil {
+			return nil, fmt.Errorf("replacing labels: %w", wrapError(err))
+		}
+	}
+
+	if opts.Draft != nil {
+		pr, _, err := r.gh.PullRequests.Get(ctx, owner, repoName, number)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+		if pr.GetDraft() != *opts.Draft {
+			if err := r.setDraft(ctx, pr.GetNodeID(), *opts.Draft); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	pr, _, err := r.gh.PullRequests.Get(ctx, owner, repoName, number)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return pr, nil
+}
+
+// setDraft converts the pull request to a draft or marks it ready for review
+func (r *RealGitHubClient) setDraft(ctx context.Context, nodeID string, draft bool) error {
+	mutation := "markPullRequestReadyForReview"
+	if draft {
+		mutation = "convertPullRequestToDraft"
+	}
+
+	req, err := r.gh.NewRequest("POST", graphQLURL(r.gh.BaseURL), map[string]interface{}{
+		"query":     fmt.Sprintf(`mutation($id: ID!) { %s(input: {pullRequestId: $id}) { clientMutationId } }`, mutation),
+		"variables": map[string]string{"id": nodeID},
+	})
+	if err != nil {
+		return err
+	}
+
+	// GraphQL reports failures in the body with a 200 status
+	var result struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if _, err := r.gh.Do(ctx, req, &result); err != nil {
+		return wrapError(err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("changing draft status: %s", result.Errors[0].Message)
+	}
+	return nil
+}
+
+// graphQLURL is the GraphQL endpoint of the REST API at baseURL, GitHub Enterprise
+// serves it at /api/graphql next to /api/v3/
+func graphQLURL(baseURL *url.URL) string {
+	if prefix, ok := strings.CutSuffix(baseURL.Path, "/api/v3/"); ok {
+		return baseURL.ResolveReference(&url.URL{Path: prefix + "/api/graphql"}).String()
+	}
+	return baseURL.ResolveReference(&url.URL{Path: "graphql"}).String()
+}
+
+func (r *RealGitHubClient) MergePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts MergePullRequestOptions) (*github.PullRequestMergeResult, error) {
+	result, _, err := r.gh.PullRequests.Merge(ctx, owner, repoName, number, opts.CommitMessage, &github.PullRequestOptions{
+		CommitTitle: opts.CommitTitle,
+		SHA:         opts.SHA,
+		MergeMethod: opts.Method,
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return result, nil
+}
+
+func (c *Client) CreatePullRequest(ctx context.Context, repoName string, opts CreatePullRequestOptions) (*github.PullRequest, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreatePullRequestForOwner(ctx, c.owner, repoName, opts)
+}
+
+func (c *Client) UpdatePullRequest(ctx context.Context, repoName string, number int, opts UpdatePullRequestOptions) (*github.PullRequest, error) {
+	if number < 1 {
+		return nil, ErrInvalidPullNumber
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.UpdatePullRequestForOwner(ctx, c.owner, repoName, number, opts)
+}
+
+func (c *Client) MergePullRequest(ctx context.Context, repoName string, number int, opts MergePullRequestOptions) (*github.PullRequestMergeResult, error) {
+	if number < 1 {
+		return nil, ErrInvalidPullNumber
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.MergePullRequestForOwner(ctx, c.owner, repoName, number, opts)
+}
+
+func (c *Client) ClosePullRequest(ctx context.Context, repoName string, number int) (*github.PullRequest, error) {
+	return c.UpdatePullRequest(ctx, repoName, number, UpdatePullRequestOptions{State: github.String("closed")})
+}
+
+func (c *Client) ReopenPullRequest(ctx context.Context, repoName string, number int) (*github.PullRequest, error) {
+	return c.UpdatePullRequest(ctx, repoName, number, UpdatePullRequestOptions{State: github.String("open")})
+}
@@ -0,0 +1,388 @@
+package githubapi
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// PullRequestSchemaVersion is bumped whenever a PullRequest field changes meaning or goes away
+const PullRequestSchemaVersion = 1
+
+// PullRequest is our own summary of a GitHub pull request, so the API
+// response stays small and doesn't change whenever go-github does
+type PullRequest struct {
+	Number         int        `json:"number"`
+	Title          string     `json:"title"`
+	Author         string     `json:"author"`
+	State          string     `json:"state"` // open, closed or merged
+	Draft          bool       `json:"draft"`
+	Labels         []string   `json:"labels"`
+	Reviewers      []string   `json:"reviewers"`      // Requested users
+	TeamReviewers  []string   `json:"team_reviewers"` // Requested teams
+	Base           string     `json:"base"`
+	Head           string     `json:"head"`
+	MergeableState string     `json:"mergeable_state"` // Only set when fetching a single pull request
+	CreatedAt      *time.Time `json:"created_at"`
+	UpdatedAt      *time.Time `json:"updated_at"`
+	AgeDays        int        `json:"age_days"`
+	HTMLURL        string     `json:"html_url"`
+}
+
+// NewPullRequest converts a go-github pull request into our own PullRequest
+func NewPullRequest(pr *github.PullRequest) PullRequest {
+	state := pr.GetState()
+	if pr.MergedAt != nil || pr.GetMerged() {
+		state = "merged"
+	}
+
+	labels := []string{}
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+	reviewers := []string{}
+	for _, user := range pr.RequestedReviewers {
+		reviewers = append(reviewers, user.GetLogin())
+	}
+	teams := []string{}
+	for _, team := range pr.RequestedTeams {
+		teams = append(teams, team.GetSlug())
+	}
+
+	converted := PullRequest{
+		Number:         pr.GetNumber(),
+		Title:          pr.GetTitle(),
+		Author:         pr.GetUser().GetLogin(),
+		State:          state,
+		Draft:          pr.GetDraft(),
+		Labels:         labels,
+		Reviewers:      reviewers,
+		TeamReviewers:  teams,
+		Base:           pr.GetBase().GetRef(),
+		Head:           pr.GetHead().GetRef(),
+		MergeableState: pr.GetMergeableState(),
+		CreatedAt:      timestampPtr(pr.CreatedAt),
+		UpdatedAt:      timestampPtr(pr.UpdatedAt),
+		HTMLURL:        pr.GetHTMLURL(),
+	}
+	if converted.CreatedAt != nil {
+		converted.AgeDays = int(time.Since(*converted.CreatedAt).Hours() / 24)
+	}
+	return converted
+}
+
+func NewPullRequests(prs []*github.PullRequest) []PullRequest {
+	converted := make([]PullRequest, 0, len(prs))
+	for _, pr := range prs {
+		converted = append(converted, NewPullRequest(pr))
+	}
+	return converted
+}
+
+var ErrInvalidView = Error("invalid value for view, use summary or full")
+
+// PullRequestView is how pull requests are rendered in responses
+type PullRequestView string
+
+const (
+	ViewSummary PullRequestView = "summary"
+	ViewFull    PullRequestView = "full" // The raw go-github objects
+)
+
+func ParsePullRequestView(value string) (PullRequestView, error) {
+	switch PullRequestView(value) {
+	case "", ViewSummary:
+		return ViewSummary, nil
+	case ViewFull:
+		return ViewFull, nil
+	default:
+		return "", ErrInvalidView
+	}
+}
+
+func (v PullRequestView) Render(prs []*github.PullRequest) interface{} {
+	if v == ViewFull {
+		return prs
+	}
+	return NewPullRequests(prs)
+}
+
+var (
+	ErrInvalidState = Error("invalid value for state")
+	ErrInvalidDate  = Error("invalid date, use RFC 3339 or YYYY-MM-DD")
+)
+
+// PullRequestFilter holds the filters and sort order for listing pull requests.
+// GitHub applies state, base, head and the sort order, the rest is filtered here
+type PullRequestFilter struct {
+	State         string // open, closed, merged or all, empty means open
+	Base          string
+	Head          string // branch name, or user:branch for forks
+	Author        string
+	Label         string
+	Draft         *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Sort          string // created, updated, popularity or long-running
+	Direction     string // asc or desc
+}
+
+// ParsePullRequestFilter builds a PullRequestFilter from the query parameters of GET /repos/:name/pulls
+func ParsePullRequestFilter(query url.Values) (PullRequestFilter, error) {
+	filter := PullRequestFilter{
+		State:     strings.ToLower(query.Get("state")),
+		Base:      query.Get("base"),
+		Head:      query.Get("head"),
+		Author:    query.Get("author"),
+		Label:     query.Get("label"),
+		Sort:      strings.ToLower(query.Get("sort")),
+		Direction: strings.ToLower(query.Get("direction")),
+	}
+
+	switch filter.State {
+	case "", "open", "closed", "merged", "all":
+	default:
+		return PullRequestFilter{}, ErrInvalidState
+	}
+
+	var err error
+	if filter.Draft, err = parseOptionalBool(query.Get("draft")); err != nil {
+		return PullRequestFilter{}, err
+	}
+
+	dates := []struct {
+		param string
+		dst   **time.Time
+		upper bool
+	}{
+		{"created_after", &filter.CreatedAfter, false},
+		{"created_before", &filter.CreatedBefore, true},
+		{"updated_after", &filter.UpdatedAfter, false},
+		{"updated_before", &filter.UpdatedBefore, true},
+	}
+	for _, date := range dates {
+		if *date.dst, err = parseOptionalDate(query.Get(date.param), date.upper); err != nil {
+			return PullRequestFilter{}, err
+		}
+	}
+
+	switch filter.Sort {
+	case "", "created", "updated", "popularity", "long-running":
+	default:
+		return PullRequestFilter{}, ErrInvalidSort
+	}
+
+	switch filter.Direction {
+	case "", "asc", "desc":
+	default:
+		return PullRequestFilter{}, ErrInvalidDirection
+	}
+
+	return filter, nil
+}
+
+// parseOptionalDate reads an RFC 3339 time or a date. Bounds are inclusive, so a
+// date is its midnight, or the last instant of the day as an upper bound
+func parseOptionalDate(value string, upper bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, ErrInvalidDate
+	}
+	if upper {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// listOptions holds the part of the filter GitHub applies itself
+func (f PullRequestFilter) listOptions(owner string, page, perPage int) *github.PullRequestListOptions {
+	opts := &github.PullRequestListOptions{
+		State:     f.State,
+		Base:      f.Base,
+		Sort:      f.Sort,
+		Direction: f.Direction,
+		ListOptions: github.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		},
+	}
+
+	switch f.State {
+	case "":
+		opts.State = "open"
+	case "merged":
+		opts.State = "closed"
+	}
+
+	// GitHub only filters on head in the user:branch form
+	if f.Head != "" {
+		opts.Head = f.Head
+		if !strings.Contains(f.Head, ":") {
+			opts.Head = owner + ":" + f.Head
+		}
+	}
+
+	return opts
+}
+
+// Matches reports whether the pull request passes every filter
+func (f PullRequestFilter) Matches(pr *github.PullRequest) bool {
+	switch f.State {
+	case "", "open":
+		if pr.GetState() == "closed" {
+			return false
+		}
+	case "closed":
+		if pr.GetState() != "closed" {
+			return false
+		}
+	case "merged":
+		if pr.MergedAt == nil && !pr.GetMerged() {
+			return false
+		}
+	}
+
+	if f.Base != "" && pr.GetBase().GetRef() != f.Base {
+		return false
+	}
+	if f.Head != "" && pr.GetHead().GetRef() != f.Head && pr.GetHead().GetLabel() != f.Head {
+		return false
+	}
+	if f.Author != "" && !strings.EqualFold(pr.GetUser().GetLogin(), f.Author) {
+		return false
+	}
+	if f.Label != "" && !hasLabel(pr, f.Label) {
+		return false
+	}
+	if f.Draft != nil && pr.GetDraft() != *f.Draft {
+		return false
+	}
+
+	created, updated := timestampPtr(pr.CreatedAt), timestampPtr(pr.UpdatedAt)
+	if !inRange(created, f.CreatedAfter, f.CreatedBefore) || !inRange(updated, f.UpdatedAfter, f.UpdatedBefore) {
+		return false
+	}
+	return true
+}
+
+func hasLabel(pr *github.PullRequest, name string) bool {
+	for _, label := range pr.Labels {
+		if strings.EqualFold(label.GetName(), name) {
+			return true
+		}
+	}
+	return false
+}
+
+// inRange reports whether t is within the bounds, a missing t only passes without bounds
+func inRange(t, after, before *time.Time) bool {
+	if after == nil && before == nil {
+		return true
+	}
+	if t == nil {
+		return false
+	}
+	if after != nil && t.Before(*after) {
+		return false
+	}
+	if before != nil && t.After(*before) {
+		return false
+	}
+	return true
+}
+
+// direction is the order GitHub lists in, its default is desc when sorting by
+// creation and asc for the other sorts
+func (f PullRequestFilter) direction() string {
+	if f.Direction != "" {
+		return f.Direction
+	}
+	if f.Sort == "" || f.Sort == "created" {
+		return "desc"
+	}
+	return "asc"
+}
+
+// pastEnd reports whether no pull request after this one can match, which
+// happens once a listing sorted by date goes past the filter's bound in that direction
+func (f PullRequestFilter) pastEnd(pr *github.PullRequest) bool {
+	var t, after, before *time.Time
+	switch f.Sort {
+	case "", "created":
+		t, after, before = timestampPtr(pr.CreatedAt), f.CreatedAfter, f.CreatedBefore
+	case "updated":
+		t, after, before = timestampPtr(pr.UpdatedAt), f.UpdatedAfter, f.UpdatedBefore
+	default:
+		return false
+	}
+	if t == nil {
+		return false
+	}
+
+	if f.direction() == "asc" {
+		return before != nil && t.After(*before)
+	}
+	return after != nil && t.Before(*after)
+}
+
+// ListPullRequestsForOwner lists the pull requests matching the filter, it
+// stops fetching pages once it has n of them, -1 means no limit
+func (r *RealGitHubClient) ListPullRequestsForOwner(ctx context.Context, owner, repoName string, filter PullRequestFilter, n int) ([]*github.PullRequest, error) {
+	allPRs := []*github.PullRequest{}
+	if n == 0 {
+		return allPRs, nil
+	}
+
+	opts := filter.listOptions(owner, 1, githubPageSize)
+	for {
+		prs, resp, err := r.gh.PullRequests.List(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, wrapError(err)
+		}
+
+		for _, pr := range prs {
+			if filter.pastEnd(pr) {
+				return allPRs, nil
+			}
+			if !filter.Matches(pr) {
+				continue
+			}
+
+			allPRs = append(allPRs, pr)
+			if n != -1 && len(allPRs) >= n {
+				return allPRs, nil
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allPRs, nil
+}
+
+func (r *RealGitHubClient) ListPullRequestsPageForOwner(ctx context.Context, owner, repoName string, filter PullRequestFilter, page, perPage int) ([]*github.PullRequest, int, error) {
+	prs, resp, err := r.gh.PullRequests.List(ctx, owner, repoName, filter.listOptions(owner, page, perPage))
+	if err != nil {
+		return nil, 0, wrapError(err)
+	}
+	return prs, resp.NextPage, nil
+}
+
+// ListPullRequestsWithFilter lists up to n pull requests matching the filter, -1 means no limit
+func (c *Client) ListPullRequestsWithFilter(ctx context.Context, repoName string, filter PullRequestFilter, n int) ([]*github.PullRequest, error) {
+	return c.gh.ListPullRequestsForOwner(ctx, c.owner, repoName, filter, n)
+}
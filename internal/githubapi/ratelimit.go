@@ -0,0 +1,44 @@
+package githubapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// GetRateLimits returns the rate limit of every API resource: core, search,
+// graphql... Checking them doesn't count against them
+func (r *RealGitHubClient) GetRateLimits(ctx context.Context) (*github.RateLimits, error) {
+	limits, _, err := r.gh.RateLimit.Get(ctx)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return limits, nil
+}
+
+// RateLimit returns the core API rate limit
+func (c *Client) RateLimit(ctx context.Context) (*github.Rate, error) {
+	limits, err := c.gh.GetRateLimits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return limits.GetCore(), nil
+}
+
+func (c *Client) RateLimits(ctx context.Context) (*github.RateLimits, error) {
+	return c.gh.GetRateLimits(ctx)
+}
+
+// Retry-After given to rate limited callers when the reset isn't known
+const defaultRetryAfter = time.Minute
+
+// RetryAfter is how long a rate limited caller should wait before trying again
+func (c *Client) RetryAfter() time.Duration {
+	if c.limiter != nil {
+		if wait := c.limiter.RetryAfter(); wait > 0 {
+			return wait
+		}
+	}
+	return defaultRetryAfter
+}
@@ -0,0 +1,31 @@
+package githubapi
+
+import (
+	"context"
+
+	"github.com/google/go-github/v67/github"
+)
+
+func (r *RealGitHubClient) ListReleasesForOwner(ctx context.Context, owner, repoName string) ([]*github.RepositoryRelease, error) {
+	opts := &github.ListOptions{PerPage: 100}
+
+	var allReleases []*github.RepositoryRelease
+	for {
+		releases, resp, err := r.gh.Repositories.ListReleases(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		allReleases = append(allReleases, releases...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return allReleases, nil
+}
+
+func (c *Client) ListReleases(ctx context.Context, repoName string) ([]*github.RepositoryRelease, error) {
+	return c.gh.ListReleasesForOwner(ctx, c.owner, repoName)
+}
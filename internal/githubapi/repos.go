@@ -0,0 +1,320 @@
+package githubapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// Repository is our own representation of a GitHub repository, so the API
+// response doesn't change whenever go-github does
+type Repository struct {
+	Name          string     `json:"name"`
+	FullName      string     `json:"full_name"`
+	Description   string     `json:"description"`
+	DefaultBranch string     `json:"default_branch"`
+	Visibility    string     `json:"visibility"`
+	Archived      bool       `json:"archived"`
+	Fork          bool       `json:"fork"`
+	Language      string     `json:"language"`
+	Topics        []string   `json:"topics"`
+	Stars         int        `json:"stars"`
+	Forks         int        `json:"forks"`
+	OpenIssues    int        `json:"open_issues"`
+	HTMLURL       string     `json:"html_url"`
+	CloneURL      string     `json:"clone_url"`
+	SSHURL        string     `json:"ssh_url"`
+	CreatedAt     *time.Time `json:"created_at"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	PushedAt      *time.Time `json:"pushed_at"`
+}
+
+// NewRepository converts a go-github repository into our own Repository
+func NewRepository(repo *github.Repository) Repository {
+	visibility := repo.GetVisibility()
+	if visibility == "" {
+		// Older API responses only carry the private flag
+		visibility = "public"
+		if repo.GetPrivate() {
+			visibility = "private"
+		}
+	}
+
+	topics := repo.Topics
+	if topics == nil {
+		topics = []string{}
+	}
+
+	return Repository{
+		Name:          repo.GetName(),
+		FullName:      repo.GetFullName(),
+		Description:   repo.GetDescription(),
+		DefaultBranch: repo.GetDefaultBranch(),
+		Visibility:    visibility,
+		Archived:      repo.GetArchived(),
+		Fork:          repo.GetFork(),
+		Language:      repo.GetLanguage(),
+		Topics:        topics,
+		Stars:         repo.GetStargazersCount(),
+		Forks:         repo.GetForksCount(),
+		OpenIssues:    repo.GetOpenIssuesCount(),
+		HTMLURL:       repo.GetHTMLURL(),
+		CloneURL:      repo.GetCloneURL(),
+		SSHURL:        repo.GetSSHURL(),
+		CreatedAt:     timestampPtr(repo.CreatedAt),
+		UpdatedAt:     timestampPtr(repo.UpdatedAt),
+		PushedAt:      timestampPtr(repo.PushedAt),
+	}
+}
+
+func timestampPtr(ts *github.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.Time
+	return &t
+}
+
+var (
+	ErrInvalidVisibility = Error("invalid value for visibility")
+	ErrInvalidBool       = Error("invalid boolean value")
+	ErrInvalidNameRegex  = Error("invalid value for name_regex")
+	ErrInvalidSort       = Error("invalid value for sort")
+	ErrInvalidDirection  = Error("invalid value for direction")
+	ErrInvalidFields     = Error("invalid value for fields")
+)
+
+// RepoFilter holds the filters and sort order for listing repositories
+type RepoFilter struct {
+	Visibility string // public, private or internal
+	Archived   *bool
+	Fork       *bool
+	Language   string
+	Topic      string
+	NamePrefix string
+	NameRegex  *regexp.Regexp
+	Sort       string // created, updated, pushed or name
+	Direction  string // asc or desc
+}
+
+// ParseRepoFilter builds a RepoFilter from the query parameters of GET /repos
+func ParseRepoFilter(query url.Values) (RepoFilter, error) {
+	filter := RepoFilter{
+		Visibility: strings.ToLower(query.Get("visibility")),
+		Language:   query.Get("language"),
+		Topic:      strings.ToLower(query.Get("topic")),
+		NamePrefix: query.Get("name_prefix"),
+		Sort:       strings.ToLower(query.Get("sort")),
+		Direction:  strings.ToLower(query.Get("direction")),
+	}
+
+	switch filter.Visibility {
+	case "", "public", "private", "internal":
+	default:
+		return RepoFilter{}, ErrInvalidVisibility
+	}
+
+	var err error
+	if filter.Archived, err = parseOptionalBool(query.Get("archived")); err != nil {
+		return RepoFilter{}, err
+	}
+	if filter.Fork, err = parseOptionalBool(query.Get("fork")); err != nil {
+		return RepoFilter{}, err
+	}
+
+	if pattern := query.Get("name_regex"); pattern != "" {
+		filter.NameRegex, err = regexp.Compile(pattern)
+		if err != nil {
+			return RepoFilter{}, ErrInvalidNameRegex
+		}
+	}
+
+	switch filter.Sort {
+	case "", "created", "updated", "pushed", "name":
+	default:
+		return RepoFilter{}, ErrInvalidSort
+	}
+
+	switch filter.Direction {
+	case "", "asc", "desc":
+	default:
+		return RepoFilter{}, ErrInvalidDirection
+	}
+
+	return filter, nil
+}
+
+func parseOptionalBool(value string) (*bool, error) {
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, ErrInvalidBool
+	}
+	return &b, nil
+}
+
+// Matches reports whether the repository passes every filter
+func (f RepoFilter) Matches(repo Repository) bool {
+	if f.Visibility != "" && repo.Visibility != f.Visibility {
+		return false
+	}
+	if f.Archived != nil && repo.Archived != *f.Archived {
+		return false
+	}
+	if f.Fork != nil && repo.Fork != *f.Fork {
+		return false
+	}
+	if f.Language != "" && !strings.EqualFold(repo.Language, f.Language) {
+		return false
+	}
+	if f.Topic != "" && !containsFold(repo.Topics, f.Topic) {
+		return false
+	}
+	if f.NamePrefix != "" && !strings.HasPrefix(repo.Name, f.NamePrefix) {
+		return false
+	}
+	if f.NameRegex != nil && !f.NameRegex.MatchString(repo.Name) {
+		return false
+	}
+	return true
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply filters and sorts the repositories
+func (f RepoFilter) Apply(repos []Repository) []Repository {
+	filtered := []Repository{}
+	for _, repo := range repos {
+		if f.Matches(repo) {
+			filtered = append(filtered, repo)
+		}
+	}
+
+	if f.Sort == "" {
+		return filtered
+	}
+
+	// Names read naturally A-Z, dates are most useful newest first
+	desc := f.Sort != "name"
+	if f.Direction != "" {
+		desc = f.Direction == "desc"
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if desc {
+			return repoLess(filtered[j], filtered[i], f.Sort)
+		}
+		return repoLess(filtered[i], filtered[j], f.Sort)
+	})
+
+	return filtered
+}
+
+func repoLess(a, b Repository, field string) bool {
+	switch field {
+	case "created":
+		return timeLess(a.CreatedAt, b.CreatedAt)
+	case "updated":
+		return timeLess(a.UpdatedAt, b.UpdatedAt)
+	case "pushed":
+		return timeLess(a.PushedAt, b.PushedAt)
+	default:
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+}
+
+// Missing times sort before any actual time
+func timeLess(a, b *time.Time) bool {
+	if a == nil {
+		return b != nil
+	}
+	if b == nil {
+		return false
+	}
+	return a.Before(*b)
+}
+
+// SelectFields keeps only the requested JSON fields of each repository
+func SelectFields(repos []Repository, fields []string) ([]map[string]interface{}, error) {
+	known, err := toMap(Repository{})
+	if err != nil {
+		return nil, err
+	}
+	for i, field := range fields {
+		fields[i] = strings.TrimSpace(field)
+		if _, ok := known[fields[i]]; !ok {
+			return nil, ErrInvalidFields
+		}
+	}
+
+	selected := make([]map[string]interface{}, 0, len(repos))
+	for _, repo := range repos {
+		all, err := toMap(repo)
+		if err != nil {
+			return nil, err
+		}
+
+		picked := make(map[string]interface{}, len(fields))
+		for _, field := range fields {
+			picked[field] = all[field]
+		}
+		selected = append(selected, picked)
+	}
+	return selected, nil
+}
+
+func toMap(repo Repository) (map[string]interface{}, error) {
+	raw, err := json.Marshal(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ListRepositories lists the owner's repositories matching the filter
+func (c *Client) ListRepositories(ctx context.Context, filter RepoFilter) ([]Repository, error) {
+	repos, err := c.gh.ListReposForOwner(ctx, c.owner)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Repository, 0, len(repos))
+	for _, repo := range repos {
+		converted = append(converted, NewRepository(repo))
+	}
+
+	return filter.Apply(converted), nil
+}
+
+func (r *RealGitHubClient) GetRepoForOwner(ctx context.Context, owner, repoName string) (*github.Repository, error) {
+	repo, _, err := r.gh.Repositories.Get(ctx, owner, repoName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return repo, nil
+}
+
+func (c *Client) GetRepo(ctx context.Context, repoName string) (*github.Repository, error) {
+	return c.gh.GetRepoForOwner(ctx, c.owner, repoName)
+}
@@ -0,0 +1,91 @@
+package githubapi
+
+import (
+	"context"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// RepoSettings holds the editable settings of an existing repository, nil fields are left unchanged
+type RepoSettings struct {
+	Description         *string `json:"description"`
+	Homepage            *string `json:"homepage"`
+	HasIssues           *bool   `json:"has_issues"`
+	HasWiki             *bool   `json:"has_wiki"`
+	HasProjects         *bool   `json:"has_projects"`
+	HasDiscussions      *bool   `json:"has_discussions"`
+	AllowSquashMerge    *bool   `json:"allow_squash_merge"`
+	AllowRebaseMerge    *bool   `json:"allow_rebase_merge"`
+	AllowMergeCommit    *bool   `json:"allow_merge_commit"`
+	AllowAutoMerge      *bool   `json:"allow_auto_merge"`
+	DeleteBranchOnMerge *bool   `json:"delete_branch_on_merge"`
+}
+
+func (s RepoSettings) Validate() error {
+	if isFalse(s.AllowSquashMerge) && isFalse(s.AllowRebaseMerge) && isFalse(s.AllowMergeCommit) {
+		return ErrNoMergeStrategy
+	}
+	return nil
+}
+
+func (r *RealGitHubClient) UpdateRepoSettingsForOwner(ctx context.Context, owner, repoName string, settings RepoSettings) (*github.Repository, error) {
+	edit := &github.Repository{
+		Description:         settings.Description,
+		Homepage:            settings.Homepage,
+		HasIssues:           settings.HasIssues,
+		HasWiki:             settings.HasWiki,
+		HasProjects:         settings.HasProjects,
+		HasDiscussions:      settings.HasDiscussions,
+		AllowSquashMerge:    settings.AllowSquashMerge,
+		AllowRebaseMerge:    settings.AllowRebaseMerge,
+		AllowMergeCommit:    settings.AllowMergeCommit,
+		AllowAutoMerge:      settings.AllowAutoMerge,
+		DeleteBranchOnMerge: settings.DeleteBranchOnMerge,
+	}
+
+	repo, _, err := r.gh.Repositories.Edit(ctx, owner, repoName, edit)
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (c *Client) UpdateRepoSettings(ctx context.Context, repoName string, settings RepoSettings) (*github.Repository, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.UpdateRepoSettingsForOwner(ctx, c.owner, repoName, settings)
+}
+
+func (r *RealGitHubClient) SetRepoArchivedForOwner(ctx context.Context, owner, repoName string, archived bool) (*github.Repository, error) {
+	repo, _, err := r.gh.Repositories.Edit(ctx, owner, repoName, &github.Repository{Archived: github.Bool(archived)})
+	if err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+func (r *RealGitHubClient) ReplaceTopicsForOwner(ctx context.Context, owner, repoName string, topics []string) ([]string, error) {
+	replaced, _, err := r.gh.Repositories.ReplaceAllTopics(ctx, owner, repoName, topics)
+	if err != nil {
+		return nil, err
+	}
+	return replaced, nil
+}
+
+// SetRepoArchived archives or unarchives the repository, archived repos are read-only
+func (c *Client) SetRepoArchived(ctx context.Context, repoName string, archived bool) (*github.Repository, error) {
+	return c.gh.SetRepoArchivedForOwner(ctx, c.owner, repoName, archived)
+}
+
+func (c *Client) ReplaceTopics(ctx context.Context, repoName string, topics []string) ([]string, error) {
+	if len(topics) > MaxTopics {
+		return nil, ErrInvalidTopic
+	}
+	for _, topic := range topics {
+		if !topicPattern.MatchString(topic) {
+			return nil, ErrInvalidTopic
+		}
+	}
+	return c.gh.ReplaceTopicsForOwner(ctx, c.owner, repoName, topics)
+}
@@ -0,0 +1,96 @@
+package githubapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/go-github/v67/github"
+)
+
+// ErrStatsPending means GitHub answered 202 and is still computing the statistics
+var ErrStatsPending = Error("statistics are being computed")
+
+// wrapStatsError tells statistics still being computed apart from other errors
+func wrapStatsError(err error) error {
+	var accepted *github.AcceptedError
+	if errors.As(err, &accepted) {
+		return ErrStatsPending
+	}
+	return wrapError(err)
+}
+
+func (r *RealGitHubClient) ListLanguagesForOwner(ctx context.Context, owner, repoName string) (map[string]int, error) {
+	languages, _, err := r.gh.Repositories.ListLanguages(ctx, owner, repoName)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return languages, nil
+}
+
+func (r *RealGitHubClient) ListContributorStatsForOwner(ctx context.Context, owner, repoName string) ([]*github.ContributorStats, error) {
+	stats, _, err := r.gh.Repositories.ListContributorsStats(ctx, owner, repoName)
+	if err != nil {
+		return nil, wrapStatsError(err)
+	}
+	return stats, nil
+}
+
+// ListCommitActivityForOwner returns the commits of each day over the last year, by week
+func (r *RealGitHubClient) ListCommitActivityForOwner(ctx context.Context, owner, repoName string) ([]*github.WeeklyCommitActivity, error) {
+	activity, _, err := r.gh.Repositories.ListCommitActivity(ctx, owner, repoName)
+	if err != nil {
+		return nil, wrapStatsError(err)
+	}
+	return activity, nil
+}
+
+// ListCodeFrequencyForOwner returns the additions and deletions of each week
+func (r *RealGitHubClient) ListCodeFrequencyForOwner(ctx context.Context, owner, repoName string) ([]*github.WeeklyStats, error) {
+	frequency, _, err := r.gh.Repositories.ListCodeFrequency(ctx, owner, repoName)
+	if err != nil {
+		return nil, wrapStatsError(err)
+	}
+	return frequency, nil
+}
+
+// ListTrafficViewsForOwner returns the daily views of the last 14 days, it needs push access
+func (r *RealGitHubClient) ListTrafficViewsForOwner(ctx context.Context, owner, repoName string) (*github.TrafficViews, error) {
+	views, _, err := r.gh.Repositories.ListTrafficViews(ctx, owner, repoName, &github.TrafficBreakdownOptions{Per: "day"})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return views, nil
+}
+
+// ListTrafficClonesForOwner returns the daily clones of the last 14 days, it needs push access
+func (r *RealGitHubClient) ListTrafficClonesForOwner(ctx context.Context, owner, repoName string) (*github.TrafficClones, error) {
+	clones, _, err := r.gh.Repositories.ListTrafficClones(ctx, owner, repoName, &github.TrafficBreakdownOptions{Per: "day"})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	return clones, nil
+}
+
+func (c *Client) ListLanguages(ctx context.Context, repoName string) (map[string]int, error) {
+	return c.gh.ListLanguagesForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) ListContributorStats(ctx context.Context, repoName string) ([]*github.ContributorStats, error) {
+	return c.gh.ListContributorStatsForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) ListCommitActivity(ctx context.Context, repoName string) ([]*github.WeeklyCommitActivity, error) {
+	return c.gh.ListCommitActivityForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) ListCodeFrequency(ctx context.Context, repoName string) ([]*github.WeeklyStats, error) {
+	return c.gh.ListCodeFrequencyForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) ListTrafficViews(ctx context.Context, repoName string) (*github.TrafficViews, error) {
+	return c.gh.ListTrafficViewsForOwner(ctx, c.owner, repoName)
+}
+
+func (c *Client) ListTrafficClones(ctx context.Context, repoName string) (*github.TrafficClones, error) {
+	return c.gh.ListTrafficClonesForOwner(ctx, c.owner, repoName)
+}
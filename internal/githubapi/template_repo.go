@@ -0,0 +1,126 @@
+package githubapi
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+var (
+	ErrMissingTemplate    = Error("template_owner and template_repo are required")
+	ErrTemplateVisibility = Error("repositories generated from a template can only be public or private")
+	ErrTemplateNotReady   = Error("repository created but its default branch wasn't populated in time")
+)
+
+const (
+	// How long to wait for GitHub to copy the template's contents
+	templateReadyTimeout = 60 * time.Second
+	templatePollDelay    = 250 * time.Millisecond
+	templatePollMaxDelay = 5 * time.Second
+)
+
+// TemplateRepoOptions holds the settings of a repository generated from a template repository
+type TemplateRepoOptions struct {
+	Name               string `json:"name"`
+	Description        string `json:"description"`
+	Visibility         string `json:"visibility"` // public or private
+	TemplateOwner      string `json:"template_owner"`
+	TemplateRepo       string `json:"template_repo"`
+	IncludeAllBranches bool   `json:"include_all_branches"`
+}
+
+// Validate checks the options before anything is sent to GitHub
+func (o TemplateRepoOptions) Validate() error {
+	if !repoNamePattern.MatchString(o.Name) || o.Name == "." || o.Name == ".." {
+		return ErrInvalidRepoName
+	}
+	if o.TemplateOwner == "" || o.TemplateRepo == "" {
+		return ErrMissingTemplate
+	}
+
+	switch o.Visibility {
+	case "", "public", "private":
+	default:
+		return ErrTemplateVisibility
+	}
+
+	return nil
+}
+
+// CreateRepoFromTemplateForOwner returns the repository along with the error when
+// it was generated but waiting for its contents failed, ErrTemplateNotReady when
+// GitHub took too long
+func (r *RealGitHubClient) CreateRepoFromTemplateForOwner(ctx context.Context, owner string, opts TemplateRepoOptions) (*github.Repository, error) {
+	req := &github.TemplateRepoRequest{
+		Name:               github.String(opts.Name),
+		Owner:              github.String(owner),
+		IncludeAllBranches: github.Bool(opts.IncludeAllBranches),
+		Private:            github.Bool(opts.Visibility == "private"),
+	}
+	if opts.Description != "" {
+		req.Description = github.String(opts.Description)
+	}
+
+	created, _, err := r.gh.Repositories.CreateFromTemplate(ctx, opts.TemplateOwner, opts.TemplateRepo, req)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	repo, err := r.waitForDefaultBranch(ctx, owner, opts.Name)
+	if err != nil {
+		return created, err
+	}
+	return repo, nil
+}
+
+// waitForDefaultBranch polls until GitHub has copied the template's commits,
+// generation is asynchronous so the new repo starts out empty
+func (r *RealGitHubClient) waitForDefaultBranch(ctx context.Context, owner, repoName string) (*github.Repository, error) {
+	ctx, cancel := context.WithTimeout(ctx, templateReadyTimeout)
+	defer cancel()
+
+	delay := templatePollDelay
+	for {
+		repo, resp, err := r.gh.Repositories.Get(ctx, owner, repoName)
+		if err == nil && repo.GetDefaultBranch() != "" {
+			_, resp, err = r.gh.Repositories.GetBranch(ctx, owner, repoName, repo.GetDefaultBranch(), 1)
+			if err == nil {
+				return repo, nil
+			}
+		}
+
+		// Not found only means GitHub isn't done yet
+		if err != nil && !isStatus(resp, http.StatusNotFound) {
+			if ctx.Err() != nil {
+				return nil, ErrTemplateNotReady
+			}
+			return nil, wrapError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ErrTemplateNotReady
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+		if delay > templatePollMaxDelay {
+			delay = templatePollMaxDelay
+		}
+	}
+}
+
+// isStatus checks the status code of a GitHub response, which may be missing on network errors
+func isStatus(resp *github.Response, status int) bool {
+	return resp != nil && resp.StatusCode == status
+}
+
+// CreateRepoFromTemplate validates the options and generates the repository from the template
+func (c *Client) CreateRepoFromTemplate(ctx context.Context, opts TemplateRepoOptions) (*github.Repository, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	return c.gh.CreateRepoFromTemplateForOwner(ctx, c.owner, opts)
+}
@@ -0,0 +1,230 @@
+package githubapi
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+)
+
+const (
+	DefaultMaxRetries = 3
+	DefaultBackoff    = time.Second // First retry delay, doubled on each retry
+
+	// Longest wait before a request or a retry, longer ones get GitHub's rate limit error
+	maxRateLimitWait = time.Minute
+	// Slowest pace of requests near the limit
+	maxThrottleDelay = 10 * time.Second
+	// Requests slow down once less than 1/throttleReserve of the quota is left
+	throttleReserve = 10
+)
+
+// RateLimiter is an HTTP transport keeping track of GitHub's rate limits. Near
+// the limit it spreads the remaining requests until the reset, and it retries
+// secondary rate limits and server errors with a jittered exponential backoff
+type RateLimiter struct {
+	maxRetries int
+	backoff    time.Duration
+
+	mu           sync.Mutex
+	rates        map[string]github.Rate // By resource: core, search, graphql...
+	blockedUntil time.Time              // End of the last secondary rate limit
+}
+
+// NewRateLimiter retries failed requests up to maxRetries times, waiting
+// backoff before the first retry
+func NewRateLimiter(maxRetries int, backoff time.Duration) *RateLimiter {
+	return &RateLimiter{maxRetries: maxRetries, backoff: backoff, rates: map[string]github.Rate{}}
+}
+
+// Transport limits the requests made through base, http.DefaultTransport when nil
+func (l *RateLimiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{limiter: l, base: base}
+}
+
+// RetryAfter is how long until GitHub accepts requests again, zero when it does
+func (l *RateLimiter) RetryAfter() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	wait := l.blockedUntil.Sub(now)
+	for _, rate := range l.rates {
+		if rate.Remaining == 0 {
+			wait = max(wait, rate.Reset.Sub(now))
+		}
+	}
+	return max(wait, 0)
+}
+
+// delay is how long to wait before a request to resource
+func (l *RateLimiter) delay(resource string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	wait := l.blockedUntil.Sub(now)
+
+	rate, ok := l.rates[resource]
+	untilReset := rate.Reset.Sub(now)
+	if ok && untilReset > 0 && rate.Remaining < rate.Limit/throttleReserve {
+		if rate.Remaining == 0 {
+			wait = max(wait, untilReset)
+		} else {
+			wait = max(wait, min(untilReset/time.Duration(rate.Remaining+1), maxThrottleDelay))
+		}
+	}
+	return max(wait, 0)
+}
+
+// update records the rate limit GitHub returned with resp
+func (l *RateLimiter) update(resp *http.Response) {
+	limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit"))
+	if err != nil {
+		return
+	}
+	remaining, _ := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	reset, _ := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	resource := resp.Header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rates[resource] = github.Rate{Limit: limit, Remaining: remaining, Reset: github.Timestamp{Time: time.Unix(reset, 0)}}
+}
+
+func (l *RateLimiter) block(until time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.blockedUntil) {
+		l.blockedUntil = until
+	}
+}
+
+type rateLimitTransport struct {
+	limiter *RateLimiter
+	base    http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	// Checking the quota doesn't count against it, so it's never held back
+	if !strings.HasSuffix(req.URL.Path, "/rate_limit") {
+		if wait := t.limiter.delay(rateResource(req.URL.Path)); wait > 0 && wait <= maxRateLimitWait {
+			// Longer waits go through, GitHub answers with the rate limit error
+			if err := sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		t.limiter.update(resp)
+
+		wait, retry := t.retryDelay(req, resp, attempt)
+		if !retry || attempt >= t.limiter.maxRetries || wait > maxRateLimitWait {
+			return resp, nil
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay tells whether the request should be retried and after how long
+func (t *rateLimitTransport) retryDelay(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	secondary := t.isSecondaryRateLimit(resp)
+	switch {
+	case secondary:
+	case resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented:
+		// Server errors may happen after the change was made, only retry what can be repeated
+		if req.Method == http.MethodPost || req.Method == http.MethodPatch {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	wait := time.Duration(rand.Int64N(int64(t.limiter.backoff<<attempt) + 1))
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	}
+	if secondary {
+		t.limiter.block(time.Now().Add(wait))
+	}
+	return wait, true
+}
+
+// isSecondaryRateLimit tells apart secondary rate limits, which end within
+// minutes, from an exhausted quota
+func (t *rateLimitTransport) isSecondaryRateLimit(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		return false
+	}
+	if resp.Header.Get("Retry-After") != "" {
+		return true
+	}
+
+	// Otherwise only the message says so, the body is put back for go-github
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && strings.Contains(string(body), "secondary rate limit")
+}
+
+// rateResource is the rate limit resource a request to path counts against
+func rateResource(path string) string {
+	switch {
+	case strings.Contains(path, "/search/code"):
+		return "code_search"
+	case strings.Contains(path, "/search/"):
+		return "search"
+	case strings.HasSuffix(path, "/graphql"):
+		return "graphql"
+	}
+	return "core"
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
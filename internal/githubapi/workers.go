@@ -0,0 +1,52 @@
+package githubapi
+
+import (
+	"context"
+	"sync"
+)
+
+// Most GitHub requests fanned out at once, GitHub penalizes bursts of concurrent requests
+const MaxConcurrentRequests = 4
+
+// FanOut runs the tasks with at most limit of them at a time. The first error
+// cancels the context of the others and is returned once they've all stopped
+func FanOut(ctx context.Context, limit int, tasks ...func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, limit)
+
+	for _, task := range tasks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(task func(ctx context.Context) error) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := task(ctx); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}(task)
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
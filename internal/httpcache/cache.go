@@ -0,0 +1,184 @@
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// CacheHeader tells whether a response came from the cache: hit when GitHub
+// wasn't asked, revalidated when GitHub answered 304
+const CacheHeader = "X-Cache"
+
+// Cache stores GET responses with their ETags. Within the TTL they're returned
+// without asking GitHub, afterwards they're revalidated with If-None-Match,
+// and GitHub doesn't count 304 responses against the rate limit
+type Cache struct {
+	store Store
+	ttl   time.Duration
+}
+
+// New caches in store, a zero ttl revalidates every response
+func New(store Store, ttl time.Duration) *Cache {
+	return &Cache{store: store, ttl: ttl}
+}
+
+// Key identifies a request. Only the scheme and host are case insensitive, paths
+// can hold case sensitive parts such as branches and file names
+func Key(u *url.URL) string {
+	key := strings.ToLower(u.Scheme+"://"+u.Host) + u.EscapedPath()
+	if u.RawQuery != "" {
+		key += "?" + u.RawQuery
+	}
+	return key
+}
+
+// Invalidate drops the entries of the resource at u and everything below it
+func (c *Cache) Invalidate(ctx context.Context, u *url.URL) {
+	resource := &url.URL{Scheme: u.Scheme, Host: u.Host, Path: strings.TrimSuffix(u.Path, "/")}
+	key := Key(resource)
+
+	err := c.store.Delete(ctx, key)
+	if err == nil {
+		err = c.store.DeletePrefix(ctx, key+"?")
+	}
+	if err == nil {
+		err = c.store.DeletePrefix(ctx, key+"/")
+	}
+	if err != nil {
+		log.Printf("Failed to invalidate the cache of %s: %v", key, err)
+	}
+}
+
+// Transport caches the GET responses of base, http.DefaultTransport when nil.
+// Successful writes to a repository invalidate what's cached for it
+func (c *Cache) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{cache: c, base: base}
+}
+
+type transport struct {
+	cache *Cache
+	base  http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil && req.Method != http.MethodHead && resp.StatusCode < 400 {
+			if repo := repositoryURL(req.URL); repo != nil {
+				t.cache.Invalidate(req.Context(), repo)
+			}
+		}
+		return resp, err
+	}
+	// Conditional or partial requests made by the caller are theirs
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("Range") != "" {
+		return t.base.RoundTrip(req)
+	}
+
+	ctx := req.Context()
+	key := Key(req.URL)
+	entry, ok, err := t.cache.store.Get(ctx, key)
+	if err != nil {
+		// The cache is only an optimization, GitHub still answers
+		log.Printf("Failed to read the cache of %s: %v", key, err)
+	}
+	if ok && time.Since(entry.StoredAt) < t.cache.ttl && !hasDirective(entry.Header, "no-cache") {
+		return entry.response(req, "hit"), nil
+	}
+
+	if ok {
+		req = req.Clone(ctx)
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+
+		// The 304 carries the current rate limit and maybe a new ETag
+		revalidated := &Entry{ETag: entry.ETag, Header: entry.Header.Clone(), Body: entry.Body, StoredAt: time.Now()}
+		for name, values := range resp.Header {
+			revalidated.Header[name] = values
+		}
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			revalidated.ETag = etag
+		}
+		t.set(ctx, key, revalidated)
+		return revalidated.response(req, "revalidated"), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" || !strings.Contains(resp.Header.Get("Content-Type"), "json") || hasDirective(resp.Header, "no-store") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.set(ctx, key, &Entry{ETag: etag, Header: resp.Header.Clone(), Body: body, StoredAt: time.Now()})
+	return resp, nil
+}
+
+func (t *transport) set(ctx context.Context, key string, entry *Entry) {
+	if err := t.cache.store.Set(ctx, key, entry); err != nil {
+		log.Printf("Failed to write the cache of %s: %v", key, err)
+	}
+}
+
+func (e *Entry) response(req *http.Request, cacheStatus string) *http.Response {
+	header := e.Header.Clone()
+	header.Set(CacheHeader, cacheStatus)
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// hasDirective tells whether the Cache-Control header holds directive. no-cache
+// responses, as the rate limit, are always revalidated
+func hasDirective(header http.Header, directive string) bool {
+	for _, value := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(value), directive) {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryURL returns the repository a request to /repos/:owner/:repo/... is about
+func repositoryURL(u *url.URL) *url.URL {
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	for i, part := range parts {
+		// The API can be served under a path, as GitHub Enterprise's /api/v3/
+		if part != "repos" {
+			continue
+		}
+		if len(parts) < i+3 {
+			return nil
+		}
+		return &url.URL{Scheme: u.Scheme, Host: u.Host, Path: "/" + strings.Join(parts[:i+3], "/")}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package httpcache
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Entries stay useful for revalidation long after the TTL, unused ones still go eventually
+	redisExpiry = 24 * time.Hour
+
+	redisTimeout   = 5 * time.Second
+	redisScanCount = 500
+)
+
+// RedisClient is the part of a Redis client the store needs. DialRedis
+// connects to a server, tests can use a local stand-in
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, expiry time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+	ScanPrefix(ctx context.Context, prefix string) ([]string, error)
+}
+
+// RedisStore keeps entries in Redis, so that they're shared by every replica
+// and survive restarts
+type RedisStore struct {
+	client RedisClient
+	prefix string // Namespace of the keys
+}
+
+func NewRedisStore(client RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	raw, ok, err := s.client.Get(ctx, s.prefix+key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, false, err
+	}
+	return &entry, true, nil
+}
+
+func (s *RedisStore) Set(ctx context.Context, key string, entry *Entry) error {
+	raw, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, s.prefix+key, raw, redisExpiry)
+}
+
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.prefix+key)
+}
+
+func (s *RedisStore) DeletePrefix(ctx context.Context, prefix string) error {
+	keys, err := s.client.ScanPrefix(ctx, s.prefix+prefix)
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	return s.client.Del(ctx, keys...)
+}
+
+type redisError string
+
+func (e redisError) Error() string { return "redis: " + string(e) }
+
+// redisConn talks RESP to a Redis server over a single connection, which is
+// redialed after network errors
+type redisConn struct {
+	addr     string
+	password string
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// DialRedis connects to the Redis server at addr, authenticating when a password is given
+func DialRedis(addr, password string) (RedisClient, error) {
+	c := &redisConn{addr: addr, password: password}
+	if _, err := c.do(context.Background(), "PING"); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *redisConn) connect(ctx context.Context) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	c.conn, c.reader = conn, bufio.NewReader(conn)
+
+	if c.password != "" {
+		if _, err := c.roundTrip(ctx, "AUTH", c.password); err != nil {
+			c.close()
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *redisConn) close() {
+	c.conn.Close()
+	c.conn, c.reader = nil, nil
+}
+
+func (c *redisConn) do(ctx context.Context, args ...string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.roundTrip(ctx, args...)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		// The connection is in an unknown state
+		c.close()
+	}
+	return reply, err
+}
+
+func (c *redisConn) roundTrip(ctx context.Context, args ...string) (interface{}, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(redisTimeout)
+	}
+	c.conn.SetDeadline(deadline)
+
+	var command strings.Builder
+	fmt.Fprintf(&command, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&command, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, command.String()); err != nil {
+		return nil, err
+	}
+	return readReply(c.reader)
+}
+
+// readReply reads a RESP reply, bulk strings are returned as []byte and nil when missing
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, redisError("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(line[1:])
+		if err != nil || count < 0 {
+			return nil, err
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, redisError("unexpected reply " + strconv.Quote(line))
+}
+
+func (c *redisConn) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	reply, err := c.do(ctx, "GET", key)
+	if err != nil || reply == nil {
+		return nil, false, err
+	}
+	value, ok := reply.([]byte)
+	if !ok {
+		return nil, false, redisError("unexpected GET reply")
+	}
+	return value, true, nil
+}
+
+func (c *redisConn) Set(ctx context.Context, key string, value []byte, expiry time.Duration) error {
+	_, err := c.do(ctx, "SET", key, string(value), "PX", strconv.FormatInt(expiry.Milliseconds(), 10))
+	return err
+}
+
+func (c *redisConn) Del(ctx context.Context, keys ...string) error {
+	_, err := c.do(ctx, append([]string{"DEL"}, keys...)...)
+	return err
+}
+
+// ScanPrefix lists the keys starting with prefix, without blocking the server like KEYS would
+func (c *redisConn) ScanPrefix(ctx context.Context, prefix string) ([]string, error) {
+	pattern := escapePattern(prefix) + "*"
+
+	var keys []string
+	cursor := "0"
+	for {
+		reply, err := c.do(ctx, "SCAN", cursor, "MATCH", pattern, "COUNT", strconv.Itoa(redisScanCount))
+		if err != nil {
+			return nil, err
+		}
+		parts, ok := reply.([]interface{})
+		if !ok || len(parts) != 2 {
+			return nil, redisError("unexpected SCAN reply")
+		}
+		next, _ := parts[0].([]byte)
+		found, _ := parts[1].([]interface{})
+		for _, key := range found {
+			if key, ok := key.([]byte); ok {
+				keys = append(keys, string(key))
+			}
+		}
+
+		cursor = string(next)
+		if cursor == "0" || cursor == "" {
+			return keys, nil
+		}
+	}
+}
+
+// escapePattern escapes the glob characters of a SCAN MATCH pattern
+func escapePattern(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		if strings.ContainsRune(`*?[]\`, r) {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
+	return escaped.String()
+}
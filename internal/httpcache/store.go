@@ -0,0 +1,112 @@
+package httpcache
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultMemorySize = 1000
+
+// Entry is a cached response
+type Entry struct {
+	ETag     string      `json:"etag"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"` // Last time GitHub returned or confirmed it
+}
+
+// Store keeps entries by key. Entries are never modified once stored, so they
+// can be shared
+type Store interface {
+	Get(ctx context.Context, key string) (*Entry, bool, error)
+	Set(ctx context.Context, key string, entry *Entry) error
+	Delete(ctx context.Context, key string) error
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+type memoryItem struct {
+	key   string
+	entry *Entry
+}
+
+// MemoryStore keeps the most recently used entries in memory
+type MemoryStore struct {
+	size int
+
+	mu    sync.Mutex
+	order *list.List // Most recently used first
+	items map[string]*list.Element
+}
+
+func NewMemoryStore(size int) *MemoryStore {
+	if size < 1 {
+		size = DefaultMemorySize
+	}
+	return &MemoryStore{size: size, order: list.New(), items: map[string]*list.Element{}}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*memoryItem).entry, true, nil
+}
+
+// Set stores the entry, evicting the least recently used one when full
+func (s *MemoryStore) Set(ctx context.Context, key string, entry *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		element.Value.(*memoryItem).entry = entry
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&memoryItem{key: key, entry: entry})
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*memoryItem).key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		s.order.Remove(element)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeletePrefix(ctx context.Context, prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, element := range s.items {
+		if strings.HasPrefix(key, prefix) {
+			s.order.Remove(element)
+			delete(s.items, key)
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
@@ -0,0 +1,290 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const (
+	DefaultCacheTTL = time.Hour
+	topContributors = 10
+)
+
+// DefaultPollDelays are the waits between requests while GitHub computes statistics
+var DefaultPollDelays = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}
+
+type Language struct {
+	Name    string  `json:"name"`
+	Bytes   int     `json:"bytes"`
+	Percent float64 `json:"percent"`
+}
+
+type Contributor struct {
+	Login     string `json:"login"`
+	Commits   int    `json:"commits"`
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+}
+
+// WeekActivity holds the commits of a week, Days starting on Sunday
+type WeekActivity struct {
+	Week    time.Time `json:"week"`
+	Commits int       `json:"commits"`
+	Days    []int     `json:"days"`
+}
+
+type WeekFrequency struct {
+	Week      time.Time `json:"week"`
+	Additions int       `json:"additions"`
+	Deletions int       `json:"deletions"` // Positive, unlike GitHub's
+}
+
+type DayTraffic struct {
+	Day     time.Time `json:"day"`
+	Count   int       `json:"count"`
+	Uniques int       `json:"uniques"`
+}
+
+// TrafficCount covers the last 14 days
+type TrafficCount struct {
+	Count   int          `json:"count"`
+	Uniques int          `json:"uniques"`
+	Daily   []DayTraffic `json:"daily"`
+}
+
+type Traffic struct {
+	Views  TrafficCount `json:"views"`
+	Clones TrafficCount `json:"clones"`
+}
+
+// Insights sums up a repository's statistics. Traffic is nil without push access to the repository
+type Insights struct {
+	Repository      string          `json:"repository"`
+	Languages       []Language      `json:"languages"`
+	TopContributors []Contributor   `json:"top_contributors"`
+	CommitActivity  []WeekActivity  `json:"commit_activity"`
+	CodeFrequency   []WeekFrequency `json:"code_frequency"`
+	Traffic         *Traffic        `json:"traffic"`
+	GeneratedAt     time.Time       `json:"generated_at"`
+}
+
+type cached struct {
+	insights  Insights
+	expiresAt time.Time
+}
+
+// Service gathers repository insights and caches them, GitHub only recomputes
+// statistics after pushes anyway
+type Service struct {
+	client *githubapi.Client
+	ttl    time.Duration
+
+	// PollDelays are the waits between requests while GitHub computes statistics,
+	// ErrStatsPending is returned once they're exhausted
+	PollDelays []time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cached
+}
+
+// NewService caches insights for ttl, a zero ttl doesn't cache
+func NewService(client *githubapi.Client, ttl time.Duration) *Service {
+	return &Service{
+		client:     client,
+		ttl:        ttl,
+		PollDelays: DefaultPollDelays,
+		cache:      map[string]cached{},
+	}
+}
+
+// Get returns the repository's insights, from the cache unless refresh is set
+func (s *Service) Get(ctx context.Context, repoName string, refresh bool) (Insights, error) {
+	if !refresh {
+		s.mu.Lock()
+		entry, ok := s.cache[repoName]
+		s.mu.Unlock()
+		if ok && time.Now().Before(entry.expiresAt) {
+			return entry.insights, nil
+		}
+	}
+
+	insights, err := s.fetch(ctx, repoName)
+	if err != nil {
+		return Insights{}, err
+	}
+
+	if s.ttl > 0 {
+		s.mu.Lock()
+		s.cache[repoName] = cached{insights: insights, expiresAt: time.Now().Add(s.ttl)}
+		s.mu.Unlock()
+	}
+	return insights, nil
+}
+
+// Invalidate drops the repository's cached insights
+func (s *Service) Invalidate(repoName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, repoName)
+}
+
+func (s *Service) fetch(ctx context.Context, repoName string) (Insights, error) {
+	insights := Insights{Repository: repoName, GeneratedAt: time.Now().UTC()}
+
+	var (
+		views  *github.TrafficViews
+		clones *github.TrafficClones
+	)
+	err := githubapi.FanOut(ctx, githubapi.MaxConcurrentRequests,
+		func(ctx context.Context) error {
+			languages, err := s.client.ListLanguages(ctx, repoName)
+			insights.Languages = newLanguages(languages)
+			return err
+		},
+		func(ctx context.Context) error {
+			return s.poll(ctx, func(ctx context.Context) error {
+				stats, err := s.client.ListContributorStats(ctx, repoName)
+				insights.TopContributors = newTopContributors(stats)
+				return err
+			})
+		},
+		func(ctx context.Context) error {
+			return s.poll(ctx, func(ctx context.Context) error {
+				activity, err := s.client.ListCommitActivity(ctx, repoName)
+				insights.CommitActivity = newCommitActivity(activity)
+				return err
+			})
+		},
+		func(ctx context.Context) error {
+			return s.poll(ctx, func(ctx context.Context) error {
+				frequency, err := s.client.ListCodeFrequency(ctx, repoName)
+				insights.CodeFrequency = newCodeFrequency(frequency)
+				return err
+			})
+		},
+		func(ctx context.Context) error {
+			var err error
+			views, err = s.client.ListTrafficViews(ctx, repoName)
+			return trafficError(err)
+		},
+		func(ctx context.Context) error {
+			var err error
+			clones, err = s.client.ListTrafficClones(ctx, repoName)
+			return trafficError(err)
+		},
+	)
+	if err != nil {
+		return Insights{}, err
+	}
+
+	if views != nil && clones != nil {
+		insights.Traffic = &Traffic{
+			Views:  newTrafficCount(views.GetCount(), views.GetUniques(), views.Views),
+			Clones: newTrafficCount(clones.GetCount(), clones.GetUniques(), clones.Clones),
+		}
+	}
+	return insights, nil
+}
+
+// poll repeats the request while GitHub computes the statistics, waiting longer each time
+func (s *Service) poll(ctx context.Context, request func(ctx context.Context) error) error {
+	for _, delay := range s.PollDelays {
+		err := request(ctx)
+		if !errors.Is(err, githubapi.ErrStatsPending) {
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return request(ctx)
+}
+
+// trafficError ignores missing push access, the rest of the insights don't need it
+func trafficError(err error) error {
+	if errors.Is(err, githubapi.ErrForbidden) {
+		return nil
+	}
+	return err
+}
+
+// newLanguages sorts the languages by size
+func newLanguages(languages map[string]int) []Language {
+	total := 0
+	for _, bytes := range languages {
+		total += bytes
+	}
+
+	converted := make([]Language, 0, len(languages))
+	for name, bytes := range languages {
+		converted = append(converted, Language{Name: name, Bytes: bytes, Percent: float64(bytes) * 100 / float64(total)})
+	}
+	sort.Slice(converted, func(i, j int) bool {
+		if converted[i].Bytes != converted[j].Bytes {
+			return converted[i].Bytes > converted[j].Bytes
+		}
+		return converted[i].Name < converted[j].Name
+	})
+	return converted
+}
+
+// newTopContributors keeps the contributors with the most commits
+func newTopContributors(stats []*github.ContributorStats) []Contributor {
+	contributors := make([]Contributor, 0, len(stats))
+	for _, stat := range stats {
+		contributor := Contributor{Login: stat.GetAuthor().GetLogin(), Commits: stat.GetTotal()}
+		for _, week := range stat.Weeks {
+			contributor.Additions += week.GetAdditions()
+			contributor.Deletions += week.GetDeletions()
+		}
+		contributors = append(contributors, contributor)
+	}
+
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Commits != contributors[j].Commits {
+			return contributors[i].Commits > contributors[j].Commits
+		}
+		return contributors[i].Login < contributors[j].Login
+	})
+	if len(contributors) > topContributors {
+		contributors = contributors[:topContributors]
+	}
+	return contributors
+}
+
+func newCommitActivity(activity []*github.WeeklyCommitActivity) []WeekActivity {
+	converted := make([]WeekActivity, 0, len(activity))
+	for _, week := range activity {
+		converted = append(converted, WeekActivity{Week: week.GetWeek().UTC(), Commits: week.GetTotal(), Days: week.Days})
+	}
+	return converted
+}
+
+func newCodeFrequency(frequency []*github.WeeklyStats) []WeekFrequency {
+	converted := make([]WeekFrequency, 0, len(frequency))
+	for _, week := range frequency {
+		deletions := week.GetDeletions()
+		if deletions < 0 {
+			deletions = -deletions
+		}
+		converted = append(converted, WeekFrequency{Week: week.GetWeek().UTC(), Additions: week.GetAdditions(), Deletions: deletions})
+	}
+	return converted
+}
+
+func newTrafficCount(count, uniques int, days []*github.TrafficData) TrafficCount {
+	traffic := TrafficCount{Count: count, Uniques: uniques, Daily: make([]DayTraffic, 0, len(days))}
+	for _, day := range days {
+		traffic.Daily = append(traffic.Daily, DayTraffic{Day: day.GetTimestamp().UTC(), Count: day.GetCount(), Uniques: day.GetUniques()})
+	}
+	return traffic
+}
@@ -0,0 +1,254 @@
+package reports
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const (
+	DefaultStaleDays   = 14
+	DefaultParallelism = 4
+)
+
+// Review statuses, from the latest review of each reviewer
+const (
+	ReviewApproved         = "approved"
+	ReviewChangesRequested = "changes_requested"
+	ReviewCommented        = "commented"
+	ReviewRequired         = "review_required"
+	ReviewNone             = "no_reviews"
+)
+
+var ErrInvalidDays = githubapi.Error("days must be a positive number")
+
+// StalePullRequest is an open pull request that hasn't been updated in a while
+type StalePullRequest struct {
+	githubapi.PullRequest
+	IdleDays     int    `json:"idle_days"`
+	ReviewStatus string `json:"review_status"`
+}
+
+type AuthorGroup struct {
+	Author       string             `json:"author"`
+	PullRequests []StalePullRequest `json:"pull_requests"`
+}
+
+type RepoGroup struct {
+	Repository string        `json:"repository"`
+	Authors    []AuthorGroup `json:"authors"`
+}
+
+// StaleReport groups the stale pull requests by repository, then by author.
+// Repositories that couldn't be read are listed in Errors instead, and the ones
+// left unread when the rate limit ran out in Unread
+type StaleReport struct {
+	GeneratedAt  time.Time         `json:"generated_at"`
+	Days         int               `json:"days"`
+	Count        int               `json:"count"`
+	Repositories []RepoGroup       `json:"repositories"`
+	Errors       map[string]string `json:"errors,omitempty"`
+	Partial      bool              `json:"partial"`
+	Unread       []string          `json:"unread,omitempty"`
+}
+
+// Reporter builds reports across every repository of the owner
+type Reporter struct {
+	client      *githubapi.Client
+	parallelism int
+}
+
+// NewReporter reads at most parallelism repositories at a time
+func NewReporter(client *githubapi.Client, parallelism int) *Reporter {
+	if parallelism < 1 {
+		parallelism = DefaultParallelism
+	}
+	return &Reporter{client: client, parallelism: parallelism}
+}
+
+// StalePulls collects the open pull requests not updated in the last days.
+// It checks there's rate limit left for at least a request per repository up
+// front. Each stale pull request costs one more for its reviews, so the limit
+// can still run out midway: the report then stops and is returned as partial
+func (r *Reporter) StalePulls(ctx context.Context, days int) (StaleReport, error) {
+	if days < 1 {
+		return StaleReport{}, ErrInvalidDays
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -days)
+	report := StaleReport{GeneratedAt: now.UTC(), Days: days, Repositories: []RepoGroup{}}
+
+	repos, err := r.client.ListRepos(ctx)
+	if err != nil {
+		return StaleReport{}, err
+	}
+
+	rate, err := r.client.RateLimit(ctx)
+	if err != nil {
+		return StaleReport{}, err
+	}
+	if rate.Remaining < len(repos) {
+		return StaleReport{}, fmt.Errorf("%d requests left until %s, %d repositories to read: %w",
+			rate.Remaining, rate.Reset.UTC().Format(time.RFC3339), len(repos), githubapi.ErrRateLimited)
+	}
+
+	// Oldest update first, so the listing stops at the first recent pull request
+	filter := githubapi.PullRequestFilter{Sort: "updated", Direction: "asc", UpdatedBefore: &cutoff}
+
+	var mu sync.Mutex
+	stale := map[string][]StalePullRequest{}
+	failed := map[string]string{}
+	read := map[string]bool{}
+	var rateLimited error
+
+	tasks := make([]func(ctx context.Context) error, 0, len(repos))
+	for _, repo := range repos {
+		name := repo.GetName()
+		tasks = append(tasks, func(ctx context.Context) error {
+			prs, err := r.repoStalePulls(ctx, name, filter, now)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, githubapi.ErrRateLimited):
+				// Stops the other repositories
+				rateLimited = err
+				return err
+			case err != nil && ctx.Err() != nil:
+				// Stopped, left unread
+				return nil
+			case err != nil:
+				failed[name] = err.Error()
+			case len(prs) > 0:
+				stale[name] = prs
+			}
+			read[name] = true
+			return nil
+		})
+	}
+	if err := githubapi.FanOut(ctx, r.parallelism, tasks...); err != nil && rateLimited == nil {
+		return StaleReport{}, err
+	}
+	if rateLimited != nil {
+		report.Partial = true
+		for _, repo := range repos {
+			if !read[repo.GetName()] {
+				report.Unread = append(report.Unread, repo.GetName())
+			}
+		}
+	}
+
+	for name, prs := range stale {
+		report.Repositories = append(report.Repositories, RepoGroup{Repository: name, Authors: groupByAuthor(prs)})
+		report.Count += len(prs)
+	}
+	sort.Slice(report.Repositories, func(i, j int) bool {
+		return report.Repositories[i].Repository < report.Repositories[j].Repository
+	})
+	if len(failed) > 0 {
+		report.Errors = failed
+	}
+
+	return report, nil
+}
+
+func (r *Reporter) repoStalePulls(ctx context.Context, repoName string, filter githubapi.PullRequestFilter, now time.Time) ([]StalePullRequest, error) {
+	prs, err := r.client.ListPullRequestsWithFilter(ctx, repoName, filter, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := make([]StalePullRequest, 0, len(prs))
+	for _, pr := range prs {
+		reviews, err := r.client.ReviewStates(ctx, repoName, pr.GetNumber())
+		if err != nil {
+			return nil, err
+		}
+
+		converted := StalePullRequest{
+			PullRequest:  githubapi.NewPullRequest(pr),
+			ReviewStatus: reviewStatus(reviews, len(pr.RequestedReviewers)+len(pr.RequestedTeams) > 0),
+		}
+		if converted.UpdatedAt != nil {
+			converted.IdleDays = int(now.Sub(*converted.UpdatedAt).Hours() / 24)
+		}
+		stale = append(stale, converted)
+	}
+	return stale, nil
+}
+
+// reviewStatus sums up the latest reviews, requested changes outweigh approvals
+func reviewStatus(reviews []githubapi.ReviewState, requested bool) string {
+	status := ReviewNone
+	if requested {
+		status = ReviewRequired
+	}
+	for _, review := range reviews {
+		switch review.State {
+		case "CHANGES_REQUESTED":
+			return ReviewChangesRequested
+		case "APPROVED":
+			status = ReviewApproved
+		case "COMMENTED":
+			if status != ReviewApproved {
+				status = ReviewCommented
+			}
+		}
+	}
+	return status
+}
+
+// groupByAuthor keeps the pull requests oldest update first within each author
+func groupByAuthor(prs []StalePullRequest) []AuthorGroup {
+	var groups []AuthorGroup
+	index := map[string]int{}
+	for _, pr := range prs {
+		i, ok := index[pr.Author]
+		if !ok {
+			i = len(groups)
+			index[pr.Author] = i
+			groups = append(groups, AuthorGroup{Author: pr.Author})
+		}
+		groups[i].PullRequests = append(groups[i].PullRequests, pr)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Author < groups[j].Author })
+	return groups
+}
+
+// WriteCSV writes a row per pull request, in the report's order
+func (r StaleReport) WriteCSV(w io.Writer) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"repository", "author", "number", "title", "review_status", "age_days", "idle_days", "updated_at", "url"})
+	for _, repo := range r.Repositories {
+		for _, author := range repo.Authors {
+			for _, pr := range author.PullRequests {
+				updatedAt := ""
+				if pr.UpdatedAt != nil {
+					updatedAt = pr.UpdatedAt.UTC().Format(time.RFC3339)
+				}
+				out.Write([]string{
+					repo.Repository,
+					author.Author,
+					strconv.Itoa(pr.Number),
+					pr.Title,
+					pr.ReviewStatus,
+					strconv.Itoa(pr.AgeDays),
+					strconv.Itoa(pr.IdleDays),
+					updatedAt,
+					pr.HTMLURL,
+				})
+			}
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
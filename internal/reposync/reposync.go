@@ -0,0 +1,302 @@
+package reposync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+var (
+	ErrDuplicateLabel     = githubapi.Error("duplicate label name")
+	ErrDuplicateMilestone = githubapi.Error("duplicate milestone title")
+)
+
+// Change is a difference between a repository and the canonical set
+type Change[T any] struct {
+	Action  string `json:"action"`
+	Name    string `json:"name"`
+	Current *T     `json:"current,omitempty"`
+	Desired *T     `json:"desired,omitempty"`
+}
+
+// Result lists the changes a sync made to a repository, or would make on a dry run
+type Result[T any] struct {
+	Repository string      `json:"repository"`
+	Changes    []Change[T] `json:"changes"`
+	Applied    bool        `json:"applied"`
+	Error      string      `json:"error,omitempty"`
+}
+
+type Options struct {
+	Prune  bool // Delete what isn't in the canonical set
+	DryRun bool // Only report the changes
+}
+
+// Syncer makes repositories match a canonical set of labels or milestones
+type Syncer struct {
+	client      *githubapi.Client
+	parallelism int
+}
+
+// NewSyncer syncs at most parallelism repositories at a time in bulk syncs
+func NewSyncer(client *githubapi.Client, parallelism int) *Syncer {
+	if parallelism < 1 {
+		parallelism = githubapi.MaxConcurrentRequests
+	}
+	return &Syncer{client: client, parallelism: parallelism}
+}
+
+// ValidateLabels checks and normalizes every label, label names are case
+// insensitive so they must be unique regardless of case
+func ValidateLabels(labels []githubapi.Label) error {
+	seen := map[string]bool{}
+	for i := range labels {
+		if err := labels[i].Validate(); err != nil {
+			return fmt.Errorf("label %q: %w", labels[i].Name, err)
+		}
+		key := strings.ToLower(labels[i].Name)
+		if seen[key] {
+			return fmt.Errorf("label %q: %w", labels[i].Name, ErrDuplicateLabel)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// ValidateMilestones checks and normalizes every milestone
+func ValidateMilestones(milestones []githubapi.Milestone) error {
+	seen := map[string]bool{}
+	for i := range milestones {
+		if err := milestones[i].Validate(); err != nil {
+			return fmt.Errorf("milestone %q: %w", milestones[i].Title, err)
+		}
+		if seen[milestones[i].Title] {
+			return fmt.Errorf("milestone %q: %w", milestones[i].Title, ErrDuplicateMilestone)
+		}
+		seen[milestones[i].Title] = true
+	}
+	return nil
+}
+
+// diff matches the current items with the desired ones by key. Creates and
+// updates follow the desired order, deletes the current one
+func diff[T any](current, desired []T, key, name func(T) string, equal func(a, b T) bool, prune bool) []Change[T] {
+	currentByKey := map[string]int{}
+	for i, item := range current {
+		currentByKey[key(item)] = i
+	}
+
+	changes := []Change[T]{}
+	wanted := map[string]bool{}
+	for i := range desired {
+		wanted[key(desired[i])] = true
+
+		j, ok := currentByKey[key(desired[i])]
+		switch {
+		case !ok:
+			changes = append(changes, Change[T]{Action: ActionCreate, Name: name(desired[i]), Desired: &desired[i]})
+		case !equal(current[j], desired[i]):
+			changes = append(changes, Change[T]{Action: ActionUpdate, Name: name(desired[i]), Current: &current[j], Desired: &desired[i]})
+		}
+	}
+
+	if prune {
+		for i := range current {
+			if !wanted[key(current[i])] {
+				changes = append(changes, Change[T]{Action: ActionDelete, Name: name(current[i]), Current: &current[i]})
+			}
+		}
+	}
+	return changes
+}
+
+func labelKey(label githubapi.Label) string  { return strings.ToLower(label.Name) }
+func labelName(label githubapi.Label) string { return label.Name }
+
+// labelsEqual compares the names exactly, so that a change of case is applied
+func labelsEqual(a, b githubapi.Label) bool {
+	return a.Name == b.Name && strings.EqualFold(a.Color, b.Color) && a.Description == b.Description
+}
+
+// Labels makes the repository's labels match desired
+func (s *Syncer) Labels(ctx context.Context, repoName string, desired []githubapi.Label, opts Options) (Result[githubapi.Label], error) {
+	desired = slices.Clone(desired) // Validating normalizes it, and bulk syncs share it
+	if err := ValidateLabels(desired); err != nil {
+		return Result[githubapi.Label]{}, err
+	}
+
+	listed, err := s.client.ListLabels(ctx, repoName)
+	if err != nil {
+		return Result[githubapi.Label]{}, err
+	}
+	current := make([]githubapi.Label, 0, len(listed))
+	for _, label := range listed {
+		current = append(current, githubapi.NewLabel(label))
+	}
+
+	result := Result[githubapi.Label]{
+		Repository: repoName,
+		Changes:    diff(current, desired, labelKey, labelName, labelsEqual, opts.Prune),
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, change := range result.Changes {
+		switch change.Action {
+		case ActionCreate:
+			_, err = s.client.CreateLabel(ctx, repoName, *change.Desired)
+		case ActionUpdate:
+			_, err = s.client.UpdateLabel(ctx, repoName, change.Current.Name, *change.Desired)
+		case ActionDelete:
+			err = s.client.DeleteLabel(ctx, repoName, change.Current.Name)
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s label %q: %w", change.Action, change.Name, err)
+		}
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+func milestoneKey(milestone githubapi.Milestone) string { return milestone.Title }
+
+// milestonesEqual ignores the due date when the desired milestone has none, and
+// the time of day otherwise since GitHub shifts due dates by the time zone
+func milestonesEqual(current, desired githubapi.Milestone) bool {
+	if current.Description != desired.Description || current.State != desired.State {
+		return false
+	}
+	if desired.DueOn == nil {
+		return true
+	}
+	return current.DueOn != nil && current.DueOn.UTC().Format(time.DateOnly) == desired.DueOn.UTC().Format(time.DateOnly)
+}
+
+// Milestones makes the repository's milestones match desired, milestones are matched by title
+func (s *Syncer) Milestones(ctx context.Context, repoName string, desired []githubapi.Milestone, opts Options) (Result[githubapi.Milestone], error) {
+	desired = slices.Clone(desired)
+	if err := ValidateMilestones(desired); err != nil {
+		return Result[githubapi.Milestone]{}, err
+	}
+
+	listed, err := s.client.ListMilestones(ctx, repoName)
+	if err != nil {
+		return Result[githubapi.Milestone]{}, err
+	}
+	current := make([]githubapi.Milestone, 0, len(listed))
+	numbers := map[string]int{}
+	for _, milestone := range listed {
+		current = append(current, githubapi.NewMilestone(milestone))
+		numbers[milestone.GetTitle()] = milestone.GetNumber()
+	}
+
+	result := Result[githubapi.Milestone]{
+		Repository: repoName,
+		Changes:    diff(current, desired, milestoneKey, milestoneKey, milestonesEqual, opts.Prune),
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for _, change := range result.Changes {
+		switch change.Action {
+		case ActionCreate:
+			_, err = s.client.CreateMilestone(ctx, repoName, *change.Desired)
+		case ActionUpdate:
+			_, err = s.client.UpdateMilestone(ctx, repoName, numbers[change.Name], *change.Desired)
+		case ActionDelete:
+			err = s.client.DeleteMilestone(ctx, repoName, numbers[change.Name])
+		}
+		if err != nil {
+			return result, fmt.Errorf("%s milestone %q: %w", change.Action, change.Name, err)
+		}
+	}
+
+	result.Applied = true
+	return result, nil
+}
+
+// AllLabels syncs the labels of every repository of the owner
+func (s *Syncer) AllLabels(ctx context.Context, desired []githubapi.Label, opts Options) ([]Result[githubapi.Label], error) {
+	if err := ValidateLabels(desired); err != nil {
+		return nil, err
+	}
+	return syncAll(ctx, s, func(ctx context.Context, repoName string) (Result[githubapi.Label], error) {
+		return s.Labels(ctx, repoName, desired, opts)
+	})
+}
+
+// AllMilestones syncs the milestones of every repository of the owner
+func (s *Syncer) AllMilestones(ctx context.Context, desired []githubapi.Milestone, opts Options) ([]Result[githubapi.Milestone], error) {
+	if err := ValidateMilestones(desired); err != nil {
+		return nil, err
+	}
+	return syncAll(ctx, s, func(ctx context.Context, repoName string) (Result[githubapi.Milestone], error) {
+		return s.Milestones(ctx, repoName, desired, opts)
+	})
+}
+
+// syncAll syncs every repository but archived ones, which are read only. A
+// repository failing is reported in its result, only running out of rate limit
+// stops the others
+func syncAll[T any](ctx context.Context, s *Syncer, sync func(ctx context.Context, repoName string) (Result[T], error)) ([]Result[T], error) {
+	repos, err := s.client.ListRepos(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, repo := range repos {
+		if !repo.GetArchived() {
+			names = append(names, repo.GetName())
+		}
+	}
+	sort.Strings(names)
+
+	results := make([]Result[T], len(names))
+	tasks := make([]func(ctx context.Context) error, 0, len(names))
+	for i, name := range names {
+		tasks = append(tasks, func(ctx context.Context) error {
+			result, err := sync(ctx, name)
+			if errors.Is(err, githubapi.ErrRateLimited) {
+				return err
+			}
+			if err != nil {
+				result.Repository = name
+				result.Error = err.Error()
+			}
+			results[i] = result
+			return nil
+		})
+	}
+	if err := githubapi.FanOut(ctx, s.parallelism, tasks...); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// Failed counts the repositories that couldn't be synced
+func Failed[T any](results []Result[T]) int {
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
@@ -0,0 +1,194 @@
+package safeguard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const DefaultConfirmationTTL = 5 * time.Minute
+
+var (
+	ErrProtected    = githubapi.Error("repository is protected from deletion")
+	ErrInvalidToken = githubapi.Error("invalid or expired confirmation token")
+	ErrTokenInUse   = githubapi.Error("confirmation token is already confirming a deletion")
+)
+
+// confirmation is what a token was issued for, it doesn't confirm another
+// kind of deletion of the same repository
+type confirmation struct {
+	repo      string
+	soft      bool
+	backup    string
+	expiresAt time.Time
+	inUse     bool // Claimed by a deletion in progress
+}
+
+// Guard stands between a DELETE request and the repository: it refuses
+// protected repositories and can require a second, confirmed request
+type Guard struct {
+	client     *githubapi.Client
+	protected  map[string]bool
+	patterns   []*regexp.Regexp
+	confirmTTL time.Duration
+
+	mu     sync.Mutex
+	tokens map[string]confirmation
+}
+
+// NewGuard protects the repositories named in protected or fully matching one
+// of the patterns, a zero confirmTTL deletes without confirmation
+func NewGuard(client *githubapi.Client, protected, patterns []string, confirmTTL time.Duration) (*Guard, error) {
+	g := &Guard{
+		client:     client,
+		protected:  map[string]bool{},
+		confirmTTL: confirmTTL,
+		tokens:     map[string]confirmation{},
+	}
+
+	// GitHub repository names are case insensitive
+	for _, name := range protected {
+		if name = strings.TrimSpace(name); name != "" {
+			g.protected[strings.ToLower(name)] = true
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile("(?i)^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, err
+		}
+		g.patterns = append(g.patterns, re)
+	}
+
+	return g, nil
+}
+
+func (g *Guard) Protected(repoName string) bool {
+	if g.protected[strings.ToLower(repoName)] {
+		return true
+	}
+	for _, re := range g.patterns {
+		if re.MatchString(repoName) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Guard) ConfirmationRequired() bool {
+	return g.confirmTTL > 0
+}
+
+// IssueToken returns a single use token confirming the deletion of the
+// repository, soft or not and with the given backup format
+func (g *Guard) IssueToken(repoName string, soft bool, backup string) (string, time.Time, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", time.Time{}, err
+	}
+	token := hex.EncodeToString(raw)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now()
+	for t, c := range g.tokens {
+		if now.After(c.expiresAt) && !c.inUse {
+			delete(g.tokens, t)
+		}
+	}
+
+	expiresAt := now.Add(g.confirmTTL).UTC()
+	g.tokens[token] = confirmation{repo: strings.ToLower(repoName), soft: soft, backup: backup, expiresAt: expiresAt}
+	return token, expiresAt, nil
+}
+
+// Confirm claims the token, it has to be unexpired and issued for the same
+// deletion. Once the deletion is over Consume uses it up, or Release lets a
+// failed deletion be retried with it
+func (g *Guard) Confirm(repoName, token string, soft bool, backup string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	c, ok := g.tokens[token]
+	if !ok || c.repo != strings.ToLower(repoName) || c.soft != soft || c.backup != backup {
+		return ErrInvalidToken
+	}
+	if c.inUse {
+		return ErrTokenInUse
+	}
+	if time.Now().After(c.expiresAt) {
+		delete(g.tokens, token)
+		return ErrInvalidToken
+	}
+
+	c.inUse = true
+	g.tokens[token] = c
+	return nil
+}
+
+// Consume uses up the token once the deletion is done
+func (g *Guard) Consume(token string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.tokens, token)
+}
+
+// Release gives back a claimed token, a no-op once it was consumed
+func (g *Guard) Release(token string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.tokens[token]; ok {
+		c.inUse = false
+		g.tokens[token] = c
+	}
+}
+
+// Report is what a deletion would remove, returned by dry runs
+type Report struct {
+	Repo             string     `json:"repo"`
+	Protected        bool       `json:"protected"`
+	Archived         bool       `json:"archived"`
+	OpenPullRequests int        `json:"open_pull_requests"`
+	OpenIssues       int        `json:"open_issues"`
+	Stars            int        `json:"stars"`
+	Forks            int        `json:"forks"`
+	PushedAt         *time.Time `json:"pushed_at"`
+}
+
+func (g *Guard) DryRun(ctx context.Context, repoName string) (Report, error) {
+	repo, err := g.client.GetRepo(ctx, repoName)
+	if err != nil {
+		return Report{}, err
+	}
+
+	prs, err := g.client.ListPullRequests(ctx, repoName, -1)
+	if err != nil {
+		return Report{}, err
+	}
+
+	converted := githubapi.NewRepository(repo)
+
+	// GitHub counts open pull requests as open issues
+	openIssues := converted.OpenIssues - len(prs)
+	if openIssues < 0 {
+		openIssues = 0
+	}
+
+	return Report{
+		Repo:             repoName,
+		Protected:        g.Protected(repoName),
+		Archived:         converted.Archived,
+		OpenPullRequests: len(prs),
+		OpenIssues:       openIssues,
+		Stars:            converted.Stars,
+		Forks:            converted.Forks,
+		PushedAt:         converted.PushedAt,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package softdelete
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+// Topic added to repositories waiting for their hard delete
+const PendingTopic = "pending-deletion"
+
+const DefaultGracePeriod = 7 * 24 * time.Hour
+
+var (
+	ErrAlreadyPending = githubapi.Error("repository is already pending deletion")
+	ErrNotPending     = githubapi.Error("repository is not pending deletion")
+	ErrRestoreExpired = githubapi.Error("restore window has expired")
+)
+
+// Manager archives repositories instead of deleting them, and hard-deletes
+// them once the grace period is over
+type Manager struct {
+	client *githubapi.Client
+	store  Store
+	grace  time.Duration
+}
+
+func NewManager(client *githubapi.Client, store Store, grace time.Duration) *Manager {
+	return &Manager{client: client, store: store, grace: grace}
+}
+
+// SoftDelete tags and archives the repository, then records it for the reaper
+func (m *Manager) SoftDelete(ctx context.Context, repoName string) (Entry, error) {
+	if _, pending, err := m.store.Get(repoName); err != nil {
+		return Entry{}, err
+	} else if pending {
+		return Entry{}, ErrAlreadyPending
+	}
+
+	repo, err := m.client.GetRepo(ctx, repoName)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	now := time.Now().UTC()
+	entry := Entry{
+		Repo:        repoName,
+		DeletedAt:   now,
+		PurgeAt:     now.Add(m.grace),
+		Topics:      repo.Topics,
+		WasArchived: repo.GetArchived(),
+	}
+
+	// Archived repos are read-only, so they can't be tagged. The tag is only a
+	// hint, the store tracks pending deletions, so it's skipped when there's no room
+	if !entry.WasArchived {
+		tagged := false
+		if !slices.Contains(repo.Topics, PendingTopic) && len(repo.Topics) < githubapi.MaxTopics {
+			topics := append(slices.Clone(repo.Topics), PendingTopic)
+			if _, err := m.client.ReplaceTopics(ctx, repoName, topics); err != nil {
+				return Entry{}, err
+			}
+			tagged = true
+		}
+		if _, err := m.client.SetRepoArchived(ctx, repoName, true); err != nil {
+			if tagged {
+				m.untag(ctx, repoName, entry.Topics)
+			}
+			return Entry{}, err
+		}
+	}
+
+	if err := m.store.Put(entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// untag puts the topics back after a failed soft delete
+func (m *Manager) untag(ctx context.Context, repoName string, topics []string) {
+	if topics == nil {
+		topics = []string{}
+	}
+	if _, err := m.client.ReplaceTopics(context.WithoutCancel(ctx), repoName, topics); err != nil {
+		log.Printf("Failed to remove the %s topic of %s: %v", PendingTopic, repoName, err)
+	}
+}
+
+// Restore undoes a soft delete while it's still inside the grace period
+func (m *Manager) Restore(ctx context.Context, repoName string) error {
+	entry, pending, err := m.store.Get(repoName)
+	if err != nil {
+		return err
+	}
+	if !pending {
+		return ErrNotPending
+	}
+	if !time.Now().Before(entry.PurgeAt) {
+		return ErrRestoreExpired
+	}
+
+	if !entry.WasArchived {
+		if _, err := m.client.SetRepoArchived(ctx, repoName, false); err != nil {
+			return err
+		}
+		topics := entry.Topics
+		if topics == nil {
+			topics = []string{}
+		}
+		if _, err := m.client.ReplaceTopics(ctx, repoName, topics); err != nil {
+			return err
+		}
+	}
+
+	return m.store.Delete(repoName)
+}
+
+// Forget drops a pending deletion, used when the repository is hard-deleted directly
+func (m *Manager) Forget(repoName string) error {
+	return m.store.Delete(repoName)
+}
+
+func (m *Manager) Pending() ([]Entry, error) {
+	return m.store.List()
+}
+
+// Reap hard-deletes every repository past its grace period and returns their names.
+// Failed deletions stay in the store and are retried on the next run, repositories
+// already gone are dropped.
+func (m *Manager) Reap(ctx context.Context) ([]string, error) {
+	entries, err := m.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var deleted []string
+	now := time.Now()
+	for _, entry := range entries {
+		if now.Before(entry.PurgeAt) {
+			continue
+		}
+
+		// A repository deleted outside the service is as good as reaped
+		if err := m.client.DeleteRepo(ctx, entry.Repo); err != nil && !errors.Is(err, githubapi.ErrNotFound) {
+			log.Printf("Failed to delete %s after its grace period: %v", entry.Repo, err)
+			continue
+		}
+		if err := m.store.Delete(entry.Repo); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, entry.Repo)
+	}
+
+	return deleted, nil
+}
+
+// Run reaps on every interval until the context is cancelled
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := m.Reap(ctx)
+			if err != nil {
+				log.Printf("Reaper failed: %v", err)
+			}
+			for _, repo := range deleted {
+				log.Printf("Deleted %s after its grace period", repo)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package softdelete
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Entry records a repository waiting for its hard delete
+type Entry struct {
+	Repo        string    `json:"repo"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	PurgeAt     time.Time `json:"purge_at"`
+	Topics      []string  `json:"topics"`       // Topics before it was tagged
+	WasArchived bool      `json:"was_archived"` // Archived before the soft delete
+}
+
+type Store interface {
+	Put(entry Entry) error
+	Get(repo string) (Entry, bool, error)
+	Delete(repo string) error
+	List() ([]Entry, error)
+}
+
+// MemoryStore keeps entries in memory, they're lost on restart
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]Entry
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: map[string]Entry{}}
+}
+
+func (s *MemoryStore) Put(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[entry.Repo] = entry
+	return nil
+}
+
+func (s *MemoryStore) Get(repo string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[repo]
+	return entry, ok, nil
+}
+
+func (s *MemoryStore) Delete(repo string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, repo)
+	return nil
+}
+
+func (s *MemoryStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedEntries(s.entries), nil
+}
+
+func sortedEntries(entries map[string]Entry) []Entry {
+	list := make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, entry)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].PurgeAt.Before(list[j].PurgeAt) })
+	return list
+}
+
+// FileStore keeps entries in a JSON file so pending deletions survive restarts
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileStore(path string) *FileStore {
+	return &FileStore{path: path}
+}
+
+func (s *FileStore) load() (map[string]Entry, error) {
+	entries := map[string]Entry{}
+
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// save writes to a temporary file first so a crash can't leave a truncated store
+func (s *FileStore) save(entries map[string]Entry) error {
+	raw, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+func (s *FileStore) Put(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	entries[entry.Repo] = entry
+	return s.save(entries)
+}
+
+func (s *FileStore) Get(repo string) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return Entry{}, false, err
+	}
+	entry, ok := entries[repo]
+	return entry, ok, nil
+}
+
+func (s *FileStore) Delete(repo string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[repo]; !ok {
+		return nil
+	}
+	delete(entries, repo)
+	return s.save(entries)
+}
+
+func (s *FileStore) List() ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedEntries(entries), nil
+}
@@ -0,0 +1,179 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+const (
+	// DefaultDeliveryTTL is how long delivery IDs are remembered, GitHub only
+	// redelivers recent deliveries
+	DefaultDeliveryTTL = 72 * time.Hour
+
+	maxPayloadSize = 25 << 20 // GitHub caps payloads at 25 MB
+)
+
+var (
+	ErrInvalidSignature  = githubapi.Error("invalid webhook signature")
+	ErrInvalidPayload    = githubapi.Error("invalid webhook payload")
+	ErrMissingDelivery   = githubapi.Error("missing X-GitHub-Delivery header")
+	ErrDuplicateDelivery = githubapi.Error("webhook delivery already received")
+)
+
+// Handler reacts to an event, which is the *github.XxxEvent matching its type
+type Handler func(ctx context.Context, event interface{}) error
+
+// Delivery is a received webhook
+type Delivery struct {
+	ID      string `json:"delivery"`
+	Event   string `json:"event"`
+	Handled int    `json:"handled"` // Number of handlers called
+}
+
+// Receiver verifies webhook deliveries and dispatches their events to the
+// registered handlers
+type Receiver struct {
+	secret []byte
+	ttl    time.Duration
+
+	mu         sync.Mutex
+	handlers   map[string][]Handler
+	deliveries map[string]time.Time // Received at, by delivery ID
+}
+
+// NewReceiver verifies deliveries against the webhook secret and ignores
+// deliveries received again within ttl
+func NewReceiver(secret string, ttl time.Duration) *Receiver {
+	return &Receiver{
+		secret:     []byte(secret),
+		ttl:        ttl,
+		handlers:   map[string][]Handler{},
+		deliveries: map[string]time.Time{},
+	}
+}
+
+// On registers a handler for an event type, as sent in X-GitHub-Event
+func (r *Receiver) On(eventType string, handler Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers[eventType] = append(r.handlers[eventType], handler)
+}
+
+func on[T any](r *Receiver, eventType string, handler func(ctx context.Context, event *T) error) {
+	r.On(eventType, func(ctx context.Context, event interface{}) error {
+		return handler(ctx, event.(*T))
+	})
+}
+
+func (r *Receiver) OnPullRequest(handler func(ctx context.Context, event *github.PullRequestEvent) error) {
+	on(r, "pull_request", handler)
+}
+
+func (r *Receiver) OnRepository(handler func(ctx context.Context, event *github.RepositoryEvent) error) {
+	on(r, "repository", handler)
+}
+
+func (r *Receiver) OnPush(handler func(ctx context.Context, event *github.PushEvent) error) {
+	on(r, "push", handler)
+}
+
+// Receive verifies the request's signature and dispatches its event. A handled
+// delivery is only received once, unless its handlers fail so that it can be
+// redelivered. Events without handlers are received with Handled at zero
+func (r *Receiver) Receive(ctx context.Context, req *http.Request) (Delivery, error) {
+	signature := req.Header.Get(github.SHA256SignatureHeader)
+	if signature == "" {
+		return Delivery{}, ErrInvalidSignature
+	}
+
+	contentType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return Delivery{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxPayloadSize))
+	if err != nil {
+		return Delivery{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if err := github.ValidateSignature(signature, body, r.secret); err != nil {
+		return Delivery{}, ErrInvalidSignature
+	}
+
+	// Already verified, this only takes the payload out of form deliveries
+	payload, err := github.ValidatePayloadFromBody(contentType, bytes.NewReader(body), "", nil)
+	if err != nil {
+		return Delivery{}, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	delivery := Delivery{ID: github.DeliveryID(req), Event: github.WebHookType(req)}
+	if delivery.ID == "" {
+		return delivery, ErrMissingDelivery
+	}
+
+	r.mu.Lock()
+	handlers := r.handlers[delivery.Event]
+	r.mu.Unlock()
+
+	// Webhooks can send events with no handler, some go-github can't even parse
+	if len(handlers) == 0 {
+		return delivery, nil
+	}
+
+	event, err := github.ParseWebHook(delivery.Event, payload)
+	if err != nil {
+		return delivery, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+
+	if !r.claim(delivery.ID) {
+		return delivery, ErrDuplicateDelivery
+	}
+
+	var errs []error
+	for _, handler := range handlers {
+		if err := handler(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	delivery.Handled = len(handlers)
+
+	if err := errors.Join(errs...); err != nil {
+		r.release(delivery.ID)
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+// claim records the delivery, false if it was already received. Expired
+// deliveries are dropped along the way
+func (r *Receiver) claim(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for seen, receivedAt := range r.deliveries {
+		if now.Sub(receivedAt) > r.ttl {
+			delete(r.deliveries, seen)
+		}
+	}
+
+	if _, ok := r.deliveries[id]; ok {
+		return false
+	}
+	r.deliveries[id] = now
+	return true
+}
+
+func (r *Receiver) release(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.deliveries, id)
+}
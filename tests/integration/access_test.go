@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_Collaborators(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Collaborators: map[string]map[string]string{"test-repo": {"bob": "push"}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "PUT", "/repos/test-repo/collaborators/alice", `{"permission": "maintain"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201 for a new collaborator, got %d: %v", code, response)
+	}
+	invitation := response["invitation"].(map[string]interface{})
+	if invitation["invitee"] != "alice" || invitation["permission"] != "maintain" {
+		t.Errorf("Expected an invitation for alice, got %v", invitation)
+	}
+
+	code, response = serveJSON(t, router, "PUT", "/repos/test-repo/collaborators/bob", `{"permission": "admin"}`)
+	if code != http.StatusOK || mockClient.Collaborators["test-repo"]["bob"] != "admin" {
+		t.Errorf("Expected bob to become admin, got %d: %v", code, response)
+	}
+
+	code, _ = serveJSON(t, router, "PUT", "/repos/test-repo/collaborators/bob", `{"permission": "owner"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid permission, got %d", code)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/collaborators", "")
+	if code != http.StatusOK || response["count"] != float64(1) {
+		t.Errorf("Expected 1 collaborator, got %d: %v", code, response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/invitations", "")
+	if code != http.StatusOK || response["count"] != float64(1) {
+		t.Fatalf("Expected 1 invitation, got %d: %v", code, response)
+	}
+	if id := response["invitations"].([]interface{})[0].(map[string]interface{})["id"]; id != float64(1) {
+		t.Fatalf("Expected invitation 1, got %v", id)
+	}
+
+	code, response = serveJSON(t, router, "PATCH", "/repos/test-repo/invitations/1", `{"permission": "pull"}`)
+	if code != http.StatusOK {
+		t.Errorf("Expected invitation 1 to be updated, got %d: %v", code, response)
+	}
+	code, _ = serveJSON(t, router, "PATCH", "/repos/test-repo/invitations/abc", `{"permission": "pull"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid id, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/invitations/1", "")
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/invitations/1", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a cancelled invitation, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/collaborators/bob", "")
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/collaborators/bob", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a removed collaborator, got %d", code)
+	}
+}
+
+func Test_TeamAccess(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "PUT", "/repos/test-repo/teams/platform", `{"permission": "maintain"}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/teams", "")
+	if code != http.StatusOK || response["count"] != float64(1) {
+		t.Fatalf("Expected 1 team, got %d: %v", code, response)
+	}
+	team := response["teams"].([]interface{})[0].(map[string]interface{})
+	if team["slug"] != "platform" || team["permission"] != "maintain" {
+		t.Errorf("Expected platform to maintain, got %v", team)
+	}
+
+	code, _ = serveJSON(t, router, "PUT", "/repos/test-repo/teams/platform", `{}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a permission, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/teams/platform", "")
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+
+	mockClient.MethodErrs = map[string]error{"ListTeamsForOwner": githubapi.ErrTeamsOutsideOrg}
+	code, _ = serveJSON(t, router, "GET", "/repos/test-repo/teams", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 outside an organization, got %d", code)
+	}
+}
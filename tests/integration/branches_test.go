@@ -0,0 +1,107 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_Branches(t *testing.T) {
+	mainSHA := "0123456789abcdef0123456789abcdef01234567"
+	mockClient := &mocks.MockGitHubClient{
+		Branches: map[string][]*github.Branch{"test-repo": {
+			{Name: github.String("main"), Commit: &github.RepositoryCommit{SHA: github.String(mainSHA)}, Protected: github.Bool(true)},
+		}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "POST", "/repos/test-repo/branches", `{"branch": "feature/login"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %v", code, response)
+	}
+	branch := response["branch"].(map[string]interface{})
+	if branch["name"] != "feature/login" || branch["sha"] != mainSHA {
+		t.Errorf("Expected feature/login from main, got %v", branch)
+	}
+
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/branches", `{"branch": "feature/login"}`)
+	if code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected status 422 for an existing branch, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/branches", `{"branch": "bad..name"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid name, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/branches", `{"branch": "other", "from": "missing"}`)
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing ref, got %d", code)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/branches", "")
+	if code != http.StatusOK || response["count"] != float64(2) {
+		t.Errorf("Expected 2 branches, got %d: %v", code, response)
+	}
+
+	// Slashes in branch names are encoded
+	code, response = serveJSON(t, router, "DELETE", "/repos/test-repo/branches/feature%2Flogin", "")
+	if code != http.StatusOK || response["branch"] != "feature/login" {
+		t.Errorf("Expected feature/login to be deleted, got %d: %v", code, response)
+	}
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/branches/feature%2Flogin", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a deleted branch, got %d", code)
+	}
+}
+
+func Test_BranchProtection(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, _ := serveJSON(t, router, "GET", "/repos/test-repo/branches/main/protection", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unprotected branch, got %d", code)
+	}
+
+	body := `{
+		"required_reviews": {"approving_review_count": 2, "require_code_owner_reviews": true},
+		"required_status_checks": {"strict": true, "contexts": ["ci/build"]},
+		"enforce_admins": true,
+		"require_linear_history": true,
+		"push_restrictions": {"teams": ["release"]}
+	}`
+	code, response := serveJSON(t, router, "PUT", "/repos/test-repo/branches/main/protection", body)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/branches/main/protection", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	protection := response["protection"].(map[string]interface{})
+	reviews := protection["required_reviews"].(map[string]interface{})
+	restrictions := protection["push_restrictions"].(map[string]interface{})
+	if reviews["approving_review_count"] != float64(2) || protection["enforce_admins"] != true || protection["require_linear_history"] != true {
+		t.Errorf("Unexpected protection %v", protection)
+	}
+	if teams := restrictions["teams"].([]interface{}); len(teams) != 1 || teams[0] != "release" {
+		t.Errorf("Expected pushes restricted to release, got %v", restrictions)
+	}
+
+	code, _ = serveJSON(t, router, "PUT", "/repos/test-repo/branches/main/protection", `{"required_reviews": {"approving_review_count": 9}}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too many reviews, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/branches/main/protection", "")
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "GET", "/repos/test-repo/branches/main/protection", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 once unprotected, got %d", code)
+	}
+}
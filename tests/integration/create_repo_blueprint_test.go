@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/blueprint"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func newBlueprintRegistry(t *testing.T) *blueprint.Registry {
+	t.Helper()
+
+	registry := blueprint.NewRegistry()
+	err := registry.Add(&blueprint.Blueprint{
+		Name:   "service",
+		Labels: []githubapi.Label{{Name: "bug", Color: "d73a4a"}},
+		Files:  []blueprint.File{{Path: "CODEOWNERS", Content: "* @team"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to add blueprint: %v", err)
+	}
+	return registry
+}
+
+func Test_CreateRepo_WithBlueprint(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouterWithServices(ghClient, Services{Blueprints: newBlueprintRegistry(t)})
+
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBufferString(`{"name": "new-repo", "blueprint": "service"}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["blueprint"] != "service" {
+		t.Errorf("Expected blueprint 'service', got '%v'", response["blueprint"])
+	}
+
+	steps, ok := response["steps"].([]interface{})
+	if !ok || len(steps) != 3 {
+		t.Fatalf("Expected 3 steps, got '%v'", response["steps"])
+	}
+
+	if len(mockClient.Labels["new-repo"]) != 1 || mockClient.Files["new-repo"]["CODEOWNERS"] != "* @team" {
+		t.Errorf("Expected the blueprint to be applied, got labels %v and files %v", mockClient.Labels, mockClient.Files)
+	}
+}
+
+func Test_CreateRepo_WithBlueprint_RolledBack(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		MethodErrs: map[string]error{"CreateLabelForOwner": githubapi.Error("mock error")},
+	}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouterWithServices(ghClient, Services{Blueprints: newBlueprintRegistry(t)})
+
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBufferString(`{"name": "new-repo", "blueprint": "service"}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["rolled_back"] != true {
+		t.Errorf("Expected rolled_back true, got '%v'", response["rolled_back"])
+	}
+
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("Expected 0 repositories after rollback, got %d", len(mockClient.Repos))
+	}
+}
+
+func Test_CreateRepo_UnknownBlueprint(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBufferString(`{"name": "new-repo", "blueprint": "missing"}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("Expected 0 repositories, got %d", len(mockClient.Repos))
+	}
+}
+
+func Test_CreateRepo_WithBlueprint_AutoInitAllowsDefaultBranch(t *testing.T) {
+	registry := blueprint.NewRegistry()
+	if err := registry.Add(&blueprint.Blueprint{Name: "initialized", AutoInit: true}); err != nil {
+		t.Fatalf("Failed to add blueprint: %v", err)
+	}
+	mockClient := &mocks.MockGitHubClient{}
+	router := SetupRouterWithServices(githubapi.NewTestClient(mockClient, "test-owner"), Services{Blueprints: registry})
+
+	code, response := serveJSON(t, router, "POST", "/repos", `{"name": "new-repo", "default_branch": "trunk", "blueprint": "initialized"}`)
+	if code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d: %v", http.StatusCreated, code, response)
+	}
+}
@@ -0,0 +1,113 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_CreateRepoFromTemplate_Success(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	reqBody := `{"name": "new-service", "template_owner": "test-owner", "template_repo": "service-template", "include_all_branches": true}`
+
+	req, err := http.NewRequest("POST", "/repos/from-template", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["name"] != "new-service" {
+		t.Errorf("Expected name 'new-service', got '%v'", response["name"])
+	}
+
+	if response["template"] != "test-owner/service-template" {
+		t.Errorf("Expected template 'test-owner/service-template', got '%v'", response["template"])
+	}
+
+	repo, ok := response["repository"].(map[string]interface{})
+	if !ok || repo["default_branch"] != "main" {
+		t.Errorf("Expected the generated repository with its default branch, got '%v'", response["repository"])
+	}
+}
+
+func Test_CreateRepoFromTemplate_MissingTemplate(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("POST", "/repos/from-template", bytes.NewBufferString(`{"name": "new-service"}`))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("Expected 0 repositories, got %d", len(mockClient.Repos))
+	}
+}
+
+func Test_CreateRepoFromTemplate_ClientError(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{Err: errors.New("mock error")}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	reqBody := `{"name": "new-service", "template_owner": "test-owner", "template_repo": "service-template"}`
+
+	req, err := http.NewRequest("POST", "/repos/from-template", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+}
+
+func Test_CreateRepoFromTemplate_NotReady(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		MethodErrs: map[string]error{"CreateRepoFromTemplateForOwner": githubapi.ErrTemplateNotReady},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "POST", "/repos/from-template",
+		`{"name": "new-service", "template_owner": "test-owner", "template_repo": "service-template"}`)
+	if code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %v", http.StatusAccepted, code, response)
+	}
+	if response["name"] != "new-service" || response["repository"] == nil {
+		t.Errorf("Expected the created repository, got %v", response)
+	}
+}
@@ -140,3 +140,87 @@ func Test_CreateRepo_ClientError(t *testing.T) {
 		t.Errorf("Expected 0 repositories, got %d", len(mockClient.Repos))
 	}
 }
+
+func Test_CreateRepo_WithOptions(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	reqBody := `{
+		"name": "new-repo",
+		"description": "Service repo",
+		"visibility": "private",
+		"topics": ["go"],
+		"auto_init": true,
+		"default_branch": "main",
+		"allow_merge_commit": false,
+		"delete_branch_on_merge": true
+	}`
+
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	repo, ok := response["repository"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected 'repository' to be a map, got '%v'", response["repository"])
+	}
+
+	if repo["description"] != "Service repo" || repo["visibility"] != "private" || repo["default_branch"] != "main" {
+		t.Errorf("Expected the options to be applied, got '%v'", repo)
+	}
+
+	if !mockClient.Repos[0].GetDeleteBranchOnMerge() || mockClient.Repos[0].GetAllowMergeCommit() {
+		t.Errorf("Expected merge settings to be passed through, got %+v", mockClient.Repos[0])
+	}
+}
+
+func Test_CreateRepo_InvalidOptions(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	reqBody := `{"name": "new-repo", "default_branch": "main"}`
+
+	req, err := http.NewRequest("POST", "/repos", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["error"] != "default_branch requires auto_init" {
+		t.Errorf("Expected error 'default_branch requires auto_init', got '%v'", response["error"])
+	}
+
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("Expected 0 repositories, got %d", len(mockClient.Repos))
+	}
+}
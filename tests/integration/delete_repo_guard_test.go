@@ -0,0 +1,169 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/safeguard"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func newGuardedRouter(t *testing.T, mockClient *mocks.MockGitHubClient, protected []string) *gin.Engine {
+	t.Helper()
+
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	guard, err := safeguard.NewGuard(ghClient, protected, nil, time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create guard: %v", err)
+	}
+	return SetupRouterWithServices(ghClient, Services{Guard: guard})
+}
+
+func serveDelete(t *testing.T, router *gin.Engine, target string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest("DELETE", target, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return w.Code, response
+}
+
+func Test_DeleteRepo_RequiresConfirmation(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("existing-repo")}},
+	}
+	router := newGuardedRouter(t, mockClient, nil)
+
+	code, response := serveDelete(t, router, "/repos/existing-repo")
+	if code != http.StatusPreconditionRequired {
+		t.Fatalf("Expected status %d, got %d", http.StatusPreconditionRequired, code)
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Fatal("Expected the first request not to delete the repository")
+	}
+
+	token, ok := response["confirmation_token"].(string)
+	if !ok || token == "" {
+		t.Fatalf("Expected a confirmation token, got %v", response)
+	}
+
+	code, _ = serveDelete(t, router, "/repos/existing-repo?confirm=wrong")
+	if code != http.StatusForbidden {
+		t.Errorf("Expected status %d for a wrong token, got %d", http.StatusForbidden, code)
+	}
+
+	code, _ = serveDelete(t, router, "/repos/existing-repo?confirm="+token)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+	if len(mockClient.Repos) != 0 {
+		t.Error("Expected the repository to be deleted")
+	}
+}
+
+func Test_DeleteRepo_ConfirmationIsForTheSameDeletion(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("existing-repo")}},
+	}
+	router := newGuardedRouter(t, mockClient, nil)
+
+	_, response := serveDelete(t, router, "/repos/existing-repo?soft=true")
+	token, _ := response["confirmation_token"].(string)
+
+	// A token for a soft delete doesn't confirm a hard one
+	code, _ := serveDelete(t, router, "/repos/existing-repo?confirm="+token)
+	if code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, code)
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Error("Expected the repository to be kept")
+	}
+}
+
+func Test_DeleteRepo_FailedDeletionKeepsToken(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("existing-repo")}},
+	}
+	router := newGuardedRouter(t, mockClient, nil)
+
+	_, response := serveDelete(t, router, "/repos/existing-repo")
+	token, _ := response["confirmation_token"].(string)
+
+	mockClient.Err = githubapi.ErrRateLimited
+	code, _ := serveDelete(t, router, "/repos/existing-repo?confirm="+token)
+	if code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, code)
+	}
+
+	// The same token confirms the retry
+	mockClient.Err = nil
+	code, _ = serveDelete(t, router, "/repos/existing-repo?confirm="+token)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+
+	code, _ = serveDelete(t, router, "/repos/existing-repo?confirm="+token)
+	if code != http.StatusForbidden {
+		t.Errorf("Expected the used token to be refused, got %d", code)
+	}
+}
+
+func Test_DeleteRepo_Protected(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("prod-api")}},
+	}
+	router := newGuardedRouter(t, mockClient, []string{"prod-api"})
+
+	code, response := serveDelete(t, router, "/repos/prod-api")
+	if code != http.StatusForbidden {
+		t.Fatalf("Expected status %d, got %d", http.StatusForbidden, code)
+	}
+	if response["error"] != "repository is protected from deletion" {
+		t.Errorf("Expected error 'repository is protected from deletion', got '%v'", response["error"])
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Error("Expected the repository to be kept")
+	}
+}
+
+func Test_DeleteRepo_DryRun(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{
+			Name:            github.String("existing-repo"),
+			StargazersCount: github.Int(7),
+		}},
+		PullRequests: []*github.PullRequest{{Number: github.Int(1)}},
+	}
+	router := newGuardedRouter(t, mockClient, nil)
+
+	code, response := serveDelete(t, router, "/repos/existing-repo?dry_run=true")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, code)
+	}
+
+	report, ok := response["would_delete"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a would_delete report, got %v", response)
+	}
+	if report["open_pull_requests"] != float64(1) || report["stars"] != float64(7) {
+		t.Errorf("Unexpected report: %v", report)
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Error("Expected the dry run to delete nothing")
+	}
+}
@@ -2,11 +2,13 @@ package integration
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/backup"
 	"github.com/jorgebaptista/octo-manager/internal/githubapi"
 	"github.com/jorgebaptista/octo-manager/tests/mocks"
 )
@@ -72,8 +74,8 @@ func Test_DeleteRepo_NotFound(t *testing.T) {
 	router.ServeHTTP(w, req)
 
 	// Assert response status code
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
 	}
 
 	// Parse response body
@@ -93,3 +95,186 @@ func Test_DeleteRepo_NotFound(t *testing.T) {
 		t.Errorf("Expected 0 repositories, got %d", len(mockClient.Repos))
 	}
 }
+
+func Test_DeleteRepo_SoftAndRestore(t *testing.T) {
+	repoName := "existing-repo"
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{
+			{Name: github.String(repoName)},
+		},
+	}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	// Simulate DELETE /repos/existing-repo?soft=true
+	req, err := http.NewRequest("DELETE", "/repos/"+repoName+"?soft=true", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("Expected status %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["purge_at"] == nil {
+		t.Error("Expected a purge_at time")
+	}
+
+	// The repo is archived, not deleted
+	if len(mockClient.Repos) != 1 || !mockClient.Repos[0].GetArchived() {
+		t.Fatalf("Expected the repository to be archived, got %+v", mockClient.Repos)
+	}
+
+	// Simulate POST /repos/existing-repo/restore
+	req, err = http.NewRequest("POST", "/repos/"+repoName+"/restore", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	if mockClient.Repos[0].GetArchived() {
+		t.Error("Expected the repository to be unarchived")
+	}
+}
+
+func Test_RestoreRepo_NotPending(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("POST", "/repos/some-repo/restore", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["error"] != "repository is not pending deletion" {
+		t.Errorf("Expected error 'repository is not pending deletion', got '%v'", response["error"])
+	}
+}
+
+func Test_DeleteRepo_WithBackup(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos:   []*github.Repository{{Name: github.String("existing-repo")}},
+		Archive: []byte("tarball content"),
+	}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	backups := backup.NewService(ghClient, backup.NewLocalStorage(t.TempDir()))
+	router := SetupRouterWithServices(ghClient, Services{Backups: backups})
+
+	req, err := http.NewRequest("DELETE", "/repos/existing-repo?backup=tarball", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["backup"] == nil {
+		t.Error("Expected the backup location in the response")
+	}
+	if len(mockClient.Repos) != 0 {
+		t.Error("Expected the repository to be deleted")
+	}
+
+	// The backup is listed
+	req, err = http.NewRequest("GET", "/backups?repo=existing-repo", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	if response["count"] != float64(1) {
+		t.Errorf("Expected 1 backup, got %v", response["count"])
+	}
+}
+
+func Test_DeleteRepo_BackupFailureKeepsRepo(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos:      []*github.Repository{{Name: github.String("existing-repo")}},
+		MethodErrs: map[string]error{"DownloadArchiveForOwner": fmt.Errorf("GitHub is down")},
+	}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	backups := backup.NewService(ghClient, backup.NewLocalStorage(t.TempDir()))
+	router := SetupRouterWithServices(ghClient, Services{Backups: backups})
+
+	req, err := http.NewRequest("DELETE", "/repos/existing-repo?backup=zipball", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Error("Expected the repository to be kept")
+	}
+}
+
+func Test_DeleteRepo_BackupNotConfigured(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("existing-repo")}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	req, err := http.NewRequest("DELETE", "/repos/existing-repo?backup=tarball", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Error("Expected the repository to be kept")
+	}
+}
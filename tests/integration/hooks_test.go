@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_Hooks(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "POST", "/repos/test-repo/hooks", `{"url": "https://ci.example.com/hook", "secret": "s3cret", "events": ["push", "pull_request"]}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %v", code, response)
+	}
+	hook := response["hook"].(map[string]interface{})
+	if hook["content_type"] != "json" || hook["has_secret"] != true || hook["active"] != true {
+		t.Errorf("Expected a json hook with a secret, got %v", hook)
+	}
+	if _, ok := hook["secret"]; ok {
+		t.Error("Expected the secret not to be returned")
+	}
+
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/hooks", `{"url": "ftp://ci.example.com"}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid url, got %d", code)
+	}
+
+	code, response = serveJSON(t, router, "PATCH", "/repos/test-repo/hooks/1", `{"active": false, "events": ["push"]}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	hook = response["hook"].(map[string]interface{})
+	if hook["active"] != false || len(hook["events"].([]interface{})) != 1 || hook["url"] != "https://ci.example.com/hook" {
+		t.Errorf("Expected only active and events to change, got %v", hook)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/hooks", "")
+	if code != http.StatusOK || response["count"] != float64(1) {
+		t.Errorf("Expected 1 hook, got %d: %v", code, response)
+	}
+
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/hooks/1/test", "")
+	if code != http.StatusOK || len(mockClient.PingedHooks) != 1 {
+		t.Errorf("Expected the hook to be pinged, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "GET", "/repos/test-repo/hooks/abc", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid id, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "DELETE", "/repos/test-repo/hooks/1", "")
+	if code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "GET", "/repos/test-repo/hooks/1", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after deletion, got %d", code)
+	}
+}
+
+func Test_HookDeliveries(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Hooks: map[string][]*github.Hook{"test-repo": {{ID: github.Int64(7), Config: &github.HookConfig{}}}},
+		HookDeliveries: map[int64][]*github.HookDelivery{7: {
+			{ID: github.Int64(3), Event: github.String("push"), StatusCode: github.Int(500)},
+			{ID: github.Int64(2), Event: github.String("push"), StatusCode: github.Int(200)},
+			{ID: github.Int64(1), Event: github.String("ping"), StatusCode: github.Int(200)},
+		}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "GET", "/repos/test-repo/hooks/7/deliveries?page_size=2", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	if response["count"] != float64(2) || response["next_cursor"] != "2" {
+		t.Errorf("Expected a first page of 2, got %v", response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/hooks/7/deliveries?page_size=2&cursor=2", "")
+	if code != http.StatusOK || response["count"] != float64(1) || response["next_cursor"] != nil {
+		t.Errorf("Expected a last page of 1, got %d: %v", code, response)
+	}
+
+	code, _ = serveJSON(t, router, "GET", "/repos/test-repo/hooks/7/deliveries?page_size=0", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid page_size, got %d", code)
+	}
+
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/hooks/7/deliveries/3/redeliver", "")
+	if code != http.StatusAccepted || len(mockClient.Redeliveries) != 1 || mockClient.Redeliveries[0] != 3 {
+		t.Errorf("Expected delivery 3 to be redelivered, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/hooks/7/deliveries/9/redeliver", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a missing delivery, got %d", code)
+	}
+}
@@ -0,0 +1,87 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_SyncRepoLabels(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Labels: map[string][]*github.Label{"test-repo": {
+			{Name: github.String("bug"), Color: github.String("ff0000")},
+			{Name: github.String("question"), Color: github.String("d876e3")},
+		}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+	body := `{"labels": [{"name": "bug", "color": "#d73a4a"}, {"name": "feature", "color": "a2eeef"}]}`
+
+	code, response := serveJSON(t, router, "PUT", "/repos/test-repo/labels?prune=true&dry_run=true", body)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	if changes := response["changes"].([]interface{}); len(changes) != 3 || response["applied"] != false {
+		t.Errorf("Expected 3 changes not applied, got %v", response)
+	}
+	if len(mockClient.Labels["test-repo"]) != 2 {
+		t.Error("Expected a dry run to change nothing")
+	}
+
+	code, response = serveJSON(t, router, "PUT", "/repos/test-repo/labels?prune=true", body)
+	if code != http.StatusOK || response["applied"] != true {
+		t.Fatalf("Expected the changes to be applied, got %d: %v", code, response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/test-repo/labels", "")
+	if code != http.StatusOK || response["count"] != float64(2) {
+		t.Fatalf("Expected 2 labels, got %d: %v", code, response)
+	}
+	label := response["labels"].([]interface{})[0].(map[string]interface{})
+	if label["name"] != "bug" || label["color"] != "d73a4a" {
+		t.Errorf("Expected bug to be recolored, got %v", label)
+	}
+
+	code, _ = serveJSON(t, router, "PUT", "/repos/test-repo/labels", `{"labels": [{"name": "bug", "color": "red"}]}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid color, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "PUT", "/repos/test-repo/labels?prune=maybe", body)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid prune, got %d", code)
+	}
+}
+
+func Test_SyncAllMilestones(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("repo1")}, {Name: github.String("repo2")}},
+		Milestones: map[string][]*github.Milestone{"repo1": {
+			{Number: github.Int(1), Title: github.String("v1.0"), State: github.String("open")},
+		}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "POST", "/milestones/sync", `{"milestones": [{"title": "v1.0", "due_on": "2025-06-30T00:00:00Z"}]}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	if response["count"] != float64(2) || response["failed"] != float64(0) {
+		t.Errorf("Expected 2 repos synced, got %v", response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/repos/repo2/milestones", "")
+	if code != http.StatusOK || response["count"] != float64(1) {
+		t.Fatalf("Expected v1.0 to be created in repo2, got %d: %v", code, response)
+	}
+	milestone := response["milestones"].([]interface{})[0].(map[string]interface{})
+	if milestone["state"] != "open" || milestone["due_on"] != "2025-06-30T00:00:00Z" {
+		t.Errorf("Unexpected milestone %v", milestone)
+	}
+
+	code, _ = serveJSON(t, router, "POST", "/milestones/sync", `{"milestones": [{"title": ""}]}`)
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a missing title, got %d", code)
+	}
+}
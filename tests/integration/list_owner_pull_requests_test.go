@@ -0,0 +1,59 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_ListOwnerPullRequests(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("repo1")}, {Name: github.String("repo2")}},
+		RepoPullRequests: map[string][]*github.PullRequest{
+			"repo1": {
+				{Number: github.Int(1), User: &github.User{Login: github.String("alice")}},
+				{Number: github.Int(2), User: &github.User{Login: github.String("bob")}},
+			},
+			"repo2": {
+				{Number: github.Int(3), User: &github.User{Login: github.String("alice")}},
+			},
+		},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "GET", "/pulls?author=alice", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	if response["count"] != float64(2) || response["source"] != "search" {
+		t.Errorf("Expected alice's 2 PRs from search, got %v", response)
+	}
+	counts := response["repositories"].(map[string]interface{})
+	if counts["repo1"] != float64(1) || counts["repo2"] != float64(1) {
+		t.Errorf("Expected a PR in each repo, got %v", counts)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/pulls?name_prefix=repo2&sort=updated", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	if response["count"] != float64(1) || response["source"] != "repos" {
+		t.Errorf("Expected repo2's PR from listing, got %v", response)
+	}
+
+	code, _ = serveJSON(t, router, "GET", "/pulls?state=bogus", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid state, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "GET", "/pulls?visibility=bogus", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid visibility, got %d", code)
+	}
+	code, _ = serveJSON(t, router, "GET", "/pulls?sort=created", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a sort other than updated, got %d", code)
+	}
+}
@@ -268,3 +268,115 @@ func Test_ListPullRequests_ClientError(t *testing.T) {
 		t.Errorf("Expected error 'mock error', got '%v'", response["error"])
 	}
 }
+
+func Test_ListPullRequests_Filters(t *testing.T) {
+	mockPulls := []*github.PullRequest{
+		{Number: github.Int(1), State: github.String("open"), User: &github.User{Login: github.String("alice")}},
+		{Number: github.Int(2), State: github.String("closed"), User: &github.User{Login: github.String("alice")}},
+		{Number: github.Int(3), State: github.String("open"), User: &github.User{Login: github.String("bob")}, Draft: github.Bool(true)},
+	}
+	mockClient := &mocks.MockGitHubClient{PullRequests: mockPulls}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	tests := map[string][]float64{
+		"/repos/test-repo/pulls":                        {1, 3},
+		"/repos/test-repo/pulls?state=all&author=alice": {1, 2},
+		"/repos/test-repo/pulls?state=closed":           {2},
+		"/repos/test-repo/pulls?draft=true":             {3},
+		"/repos/test-repo/pulls?draft=true&page_size=5": {3},
+	}
+	for target, expected := range tests {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", target, http.StatusOK, w.Code)
+		}
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+
+		prs := response["pull_requests"].([]interface{})
+		if len(prs) != len(expected) {
+			t.Errorf("%s: expected %d pull requests, got %d", target, len(expected), len(prs))
+			continue
+		}
+		for i, pr := range prs {
+			if number := pr.(map[string]interface{})["number"]; number != expected[i] {
+				t.Errorf("%s: expected PR %v at %d, got %v", target, expected[i], i, number)
+			}
+		}
+	}
+}
+
+func Test_ListPullRequests_InvalidFilter(t *testing.T) {
+	router := SetupRouter(githubapi.NewTestClient(&mocks.MockGitHubClient{}, "test-owner"))
+
+	req, err := http.NewRequest("GET", "/repos/test-repo/pulls?state=pending", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func Test_ListPullRequests_Views(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{PullRequests: []*github.PullRequest{{
+		Number: github.Int(1),
+		User:   &github.User{Login: github.String("alice"), ID: github.Int64(42)},
+	}}}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	get := func(target string) map[string]interface{} {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status %d, got %d", target, http.StatusOK, w.Code)
+		}
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v", err)
+		}
+		return response
+	}
+
+	summary := get("/repos/test-repo/pulls")
+	if summary["schema_version"] != float64(githubapi.PullRequestSchemaVersion) {
+		t.Errorf("Expected schema_version %d, got %v", githubapi.PullRequestSchemaVersion, summary["schema_version"])
+	}
+	pr := summary["pull_requests"].([]interface{})[0].(map[string]interface{})
+	if pr["author"] != "alice" || pr["user"] != nil {
+		t.Errorf("Expected the summary schema, got %v", pr)
+	}
+
+	full := get("/repos/test-repo/pulls?view=full")
+	pr = full["pull_requests"].([]interface{})[0].(map[string]interface{})
+	user, ok := pr["user"].(map[string]interface{})
+	if !ok || user["id"] != float64(42) {
+		t.Errorf("Expected the raw pull request, got %v", pr)
+	}
+
+	req, _ := http.NewRequest("GET", "/repos/test-repo/pulls?view=raw", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for an unknown view, got %d", http.StatusBadRequest, w.Code)
+	}
+}
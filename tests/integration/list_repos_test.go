@@ -85,3 +85,82 @@ func Test_ListRepos_ClientError(t *testing.T) {
 		t.Errorf("Expected error 'mock error', got '%v'", response["error"])
 	}
 }
+
+func Test_ListRepos_FilteredWithFields(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{
+			{Name: github.String("repo1"), Private: github.Bool(true), StargazersCount: github.Int(5)},
+			{Name: github.String("repo2")},
+		},
+	}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("GET", "/repos?visibility=private&fields=name,stars", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	repos, ok := response["repositories"].([]interface{})
+	if !ok || len(repos) != 1 {
+		t.Fatalf("Expected 1 repository, got '%v'", response["repositories"])
+	}
+
+	repo, ok := repos[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected repository to be a map, got '%v'", repos[0])
+	}
+
+	if repo["name"] != "repo1" {
+		t.Errorf("Expected name 'repo1', got '%v'", repo["name"])
+	}
+
+	if repo["stars"] != float64(5) {
+		t.Errorf("Expected stars 5, got '%v'", repo["stars"])
+	}
+
+	if _, ok := repo["visibility"]; ok {
+		t.Errorf("Expected visibility to be left out, got '%v'", repo["visibility"])
+	}
+}
+
+func Test_ListRepos_InvalidFilter(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("GET", "/repos?sort=stars", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["error"] != "invalid value for sort" {
+		t.Errorf("Expected error 'invalid value for sort', got '%v'", response["error"])
+	}
+}
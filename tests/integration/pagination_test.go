@@ -0,0 +1,166 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_ListRepos_Paginated(t *testing.T) {
+	var mockRepos []*github.Repository
+	for i := 0; i < 5; i++ {
+		mockRepos = append(mockRepos, &github.Repository{Name: github.String(fmt.Sprintf("repo%d", i))})
+	}
+	mockClient := &mocks.MockGitHubClient{Repos: mockRepos}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("GET", "/repos?page_size=3", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	repos, ok := response["repositories"].([]interface{})
+	if !ok || len(repos) != 3 {
+		t.Fatalf("Expected 3 repositories, got '%v'", response["repositories"])
+	}
+
+	nextCursor, ok := response["next_cursor"].(string)
+	if !ok || nextCursor == "" {
+		t.Fatalf("Expected a next_cursor, got '%v'", response["next_cursor"])
+	}
+
+	link := w.Header().Get("Link")
+	if !strings.Contains(link, "cursor="+nextCursor) || !strings.Contains(link, `rel="next"`) {
+		t.Errorf("Expected Link header to point to the next cursor, got '%s'", link)
+	}
+
+	// Follow the cursor to the last page
+	req, err = http.NewRequest("GET", "/repos?page_size=3&cursor="+nextCursor, nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	response = map[string]interface{}{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	repos, ok = response["repositories"].([]interface{})
+	if !ok || len(repos) != 2 {
+		t.Fatalf("Expected 2 repositories, got '%v'", response["repositories"])
+	}
+
+	if repos[0] != "repo3" {
+		t.Errorf("Expected 'repo3', got '%v'", repos[0])
+	}
+
+	if response["next_cursor"] != nil {
+		t.Errorf("Expected no next_cursor on the last page, got '%v'", response["next_cursor"])
+	}
+
+	if w.Header().Get("Link") != "" {
+		t.Errorf("Expected no Link header on the last page, got '%s'", w.Header().Get("Link"))
+	}
+}
+
+func Test_ListRepos_InvalidCursor(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("GET", "/repos?cursor=garbage!", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func Test_ListPullRequests_Paginated(t *testing.T) {
+	mockPulls := []*github.PullRequest{
+		{Title: github.String("PR 1")},
+		{Title: github.String("PR 2")},
+		{Title: github.String("PR 3")},
+	}
+	mockClient := &mocks.MockGitHubClient{PullRequests: mockPulls}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("GET", "/repos/test_repo/pulls?page_size=2", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	if response["count"] != float64(2) {
+		t.Errorf("Expected count 2, got %v", response["count"])
+	}
+
+	if _, ok := response["next_cursor"].(string); !ok {
+		t.Errorf("Expected a next_cursor, got '%v'", response["next_cursor"])
+	}
+
+	if w.Header().Get("Link") == "" {
+		t.Error("Expected a Link header")
+	}
+}
+
+func Test_ListPullRequests_PaginatedWithN(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	ghClient := githubapi.NewTestClient(mockClient, "test-owner")
+	router := SetupRouter(ghClient)
+
+	req, err := http.NewRequest("GET", "/repos/test_repo/pulls?page_size=2&n=1", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
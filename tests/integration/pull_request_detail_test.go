@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_GetPullRequestDetail(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		PullRequests: []*github.PullRequest{{
+			Number: github.Int(3),
+			Title:  github.String("Fix bug"),
+			Head:   &github.PullRequestBranch{Ref: github.String("fix"), SHA: github.String("sha1")},
+		}},
+		PullRequestFiles: map[int][]*github.CommitFile{3: {{Filename: github.String("fix.go")}}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	req, err := http.NewRequest("GET", "/repos/test-repo/pulls/3", nil)
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+
+	pr, ok := response["pull_request"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected a pull_request object, got %v", response)
+	}
+	if pr["number"] != float64(3) || pr["title"] != "Fix bug" {
+		t.Errorf("Unexpected pull request: %v", pr)
+	}
+	if files, ok := pr["files"].([]interface{}); !ok || len(files) != 1 {
+		t.Errorf("Expected 1 changed file, got %v", pr["files"])
+	}
+}
+
+func Test_GetPullRequestDetail_Errors(t *testing.T) {
+	router := SetupRouter(githubapi.NewTestClient(&mocks.MockGitHubClient{}, "test-owner"))
+
+	tests := map[string]int{
+		"/repos/test-repo/pulls/abc": http.StatusBadRequest,
+		"/repos/test-repo/pulls/0":   http.StatusBadRequest,
+		"/repos/test-repo/pulls/42":  http.StatusNotFound,
+	}
+	for target, expected := range tests {
+		req, err := http.NewRequest("GET", target, nil)
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != expected {
+			t.Errorf("%s: expected status %d, got %d", target, expected, w.Code)
+		}
+	}
+}
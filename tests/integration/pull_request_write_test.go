@@ -0,0 +1,98 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func serveJSON(t *testing.T, router *gin.Engine, method, target, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest(method, target, bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return w.Code, response
+}
+
+func Test_PullRequestWrites(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "POST", "/repos/test-repo/pulls", `{"title": "Add feature", "head": "feature", "base": "main"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d: %v", http.StatusCreated, code, response)
+	}
+	pr := response["pull_request"].(map[string]interface{})
+	if pr["number"] != float64(1) || pr["state"] != "open" {
+		t.Errorf("Unexpected pull request: %v", pr)
+	}
+
+	code, response = serveJSON(t, router, "PATCH", "/repos/test-repo/pulls/1", `{"title": "Add the feature", "draft": true, "labels": ["enhancement"]}`)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %v", http.StatusOK, code, response)
+	}
+	pr = response["pull_request"].(map[string]interface{})
+	if pr["title"] != "Add the feature" || pr["draft"] != true || len(pr["labels"].([]interface{})) != 1 {
+		t.Errorf("Unexpected pull request: %v", pr)
+	}
+
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/pulls/1/close", "")
+	if code != http.StatusOK || mockClient.PullRequests[0].GetState() != "closed" {
+		t.Errorf("Expected the pull request to be closed, got status %d", code)
+	}
+	code, _ = serveJSON(t, router, "POST", "/repos/test-repo/pulls/1/reopen", "")
+	if code != http.StatusOK || mockClient.PullRequests[0].GetState() != "open" {
+		t.Errorf("Expected the pull request to be reopened, got status %d", code)
+	}
+
+	// A stale head SHA refuses the merge
+	code, _ = serveJSON(t, router, "PUT", "/repos/test-repo/pulls/1/merge", `{"merge_method": "squash", "sha": "`+strings.Repeat("c", 40)+`"}`)
+	if code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d", http.StatusConflict, code)
+	}
+
+	code, response = serveJSON(t, router, "PUT", "/repos/test-repo/pulls/1/merge", "")
+	if code != http.StatusOK || response["merged"] != true {
+		t.Errorf("Expected the pull request to be merged, got %d: %v", code, response)
+	}
+}
+
+func Test_PullRequestWrites_Invalid(t *testing.T) {
+	router := SetupRouter(githubapi.NewTestClient(&mocks.MockGitHubClient{}, "test-owner"))
+
+	tests := []struct {
+		method, target, body string
+		expected             int
+	}{
+		{"POST", "/repos/test-repo/pulls", `{"title": "No branches"}`, http.StatusBadRequest},
+		{"POST", "/repos/test-repo/pulls", `not json`, http.StatusBadRequest},
+		{"PATCH", "/repos/test-repo/pulls/1", `{}`, http.StatusBadRequest},
+		{"PATCH", "/repos/test-repo/pulls/1", `{"title": "Missing"}`, http.StatusNotFound},
+		{"PUT", "/repos/test-repo/pulls/1/merge", `{"merge_method": "octopus"}`, http.StatusBadRequest},
+		{"POST", "/repos/test-repo/pulls/x/close", ``, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		code, response := serveJSON(t, router, tt.method, tt.target, tt.body)
+		if code != tt.expected {
+			t.Errorf("%s %s %s: expected status %d, got %d: %v", tt.method, tt.target, tt.body, tt.expected, code, response)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_RateLimit(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "GET", "/ratelimit", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	core, ok := response["core"].(map[string]interface{})
+	if !ok || core["remaining"] != float64(5000) {
+		t.Errorf("Expected the core quota, got %v", response)
+	}
+	if _, ok := response["search"]; !ok {
+		t.Errorf("Expected the search quota, got %v", response)
+	}
+}
+
+func Test_RateLimited_RetryAfter(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{Err: fmt.Errorf("listing repos: %w", githubapi.ErrRateLimited)}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	req, _ := http.NewRequest("GET", "/repos", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected Retry-After 60, got %q", w.Header().Get("Retry-After"))
+	}
+
+	// Other errors don't get one
+	mockClient.Err = fmt.Errorf("repository %w", githubapi.ErrNotFound)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound || w.Header().Get("Retry-After") != "" {
+		t.Errorf("Expected a 404 without Retry-After, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/insights"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_RepoInsights(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Languages: map[string]map[string]int{"test-repo": {"Go": 100}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "GET", "/repos/test-repo/insights", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	languages := response["languages"].([]interface{})
+	if response["repository"] != "test-repo" || len(languages) != 1 {
+		t.Errorf("Expected test-repo's languages, got %v", response)
+	}
+
+	code, _ = serveJSON(t, router, "GET", "/repos/test-repo/insights?refresh=maybe", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an invalid refresh, got %d", code)
+	}
+}
+
+func Test_RepoInsights_Pending(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{StatsPending: 100}
+	client := githubapi.NewTestClient(mockClient, "test-owner")
+	stats := insights.NewService(client, insights.DefaultCacheTTL)
+	stats.PollDelays = []time.Duration{time.Millisecond}
+	router := SetupRouterWithServices(client, Services{Insights: stats})
+
+	req, _ := http.NewRequest("GET", "/repos/test-repo/insights", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202 while statistics are computed, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected a Retry-After header")
+	}
+}
+
+func Test_RepoInsights_NotFound(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		MethodErrs: map[string]error{"ListLanguagesForOwner": githubapi.ErrNotFound},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, _ := serveJSON(t, router, "GET", "/repos/missing/insights", "")
+	if code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", code)
+	}
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func Test_StalePullsReport(t *testing.T) {
+	updated := &github.Timestamp{Time: time.Now().AddDate(0, 0, -20)}
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("repo1")}},
+		PullRequests: []*github.PullRequest{
+			{Number: github.Int(7), Title: github.String("Stale"), User: &github.User{Login: github.String("alice")}, CreatedAt: updated, UpdatedAt: updated},
+		},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, response := serveJSON(t, router, "GET", "/reports/stale-pulls?days=14", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	if response["count"] != float64(1) || response["days"] != float64(14) {
+		t.Errorf("Expected 1 stale pull request over 14 days, got %v", response)
+	}
+
+	code, response = serveJSON(t, router, "GET", "/reports/stale-pulls?days=30", "")
+	if code != http.StatusOK || response["count"] != float64(0) {
+		t.Errorf("Expected no stale pull requests over 30 days, got %d: %v", code, response)
+	}
+
+	code, _ = serveJSON(t, router, "GET", "/reports/stale-pulls?days=-1", "")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for negative days, got %d", code)
+	}
+
+	req, _ := http.NewRequest("GET", "/reports/stale-pulls?format=csv", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.HasPrefix(w.Header().Get("Content-Type"), "text/csv") {
+		t.Fatalf("Expected a CSV response, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(w.Body.String(), "repo1,alice,7,Stale,no_reviews,20,20,") {
+		t.Errorf("Expected a row for PR 7, got %q", w.Body.String())
+	}
+}
+
+func Test_StalePullsReport_RateLimited(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("repo1")}},
+		Rate:  &github.Rate{Remaining: 0, Reset: github.Timestamp{Time: time.Now().Add(time.Minute)}},
+	}
+	router := SetupRouter(githubapi.NewTestClient(mockClient, "test-owner"))
+
+	code, _ := serveJSON(t, router, "GET", "/reports/stale-pulls", "")
+	if code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429, got %d", code)
+	}
+}
@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/webhooks"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func deliver(t *testing.T, router *gin.Engine, event, delivery, body, secret string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", "/webhooks/github", bytes.NewBufferString(body))
+	if err != nil {
+		t.Fatalf("Failed to create request: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", delivery)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to parse response: %v", err)
+	}
+	return w.Code, response
+}
+
+func Test_Webhooks(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{
+		Languages: map[string]map[string]int{"test-repo": {"Go": 100}},
+	}
+	receiver := webhooks.NewReceiver("secret", time.Hour)
+	router := SetupRouterWithServices(githubapi.NewTestClient(mockClient, "test-owner"), Services{Webhooks: receiver})
+
+	code, response := serveJSON(t, router, "GET", "/repos/test-repo/insights", "")
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %v", code, response)
+	}
+	mockClient.Languages["test-repo"] = map[string]int{"Go": 100, "Rust": 100}
+
+	push := `{"ref": "refs/heads/main", "repository": {"name": "test-repo"}}`
+	code, response = deliver(t, router, "push", "delivery-1", push, "secret")
+	if code != http.StatusOK || response["event"] != "push" || response["handled"] != float64(1) {
+		t.Fatalf("Expected the push to be handled, got %d: %v", code, response)
+	}
+
+	// The push dropped the cached insights
+	_, response = serveJSON(t, router, "GET", "/repos/test-repo/insights", "")
+	if languages := response["languages"].([]interface{}); len(languages) != 2 {
+		t.Errorf("Expected fresh insights after a push, got %v", response)
+	}
+
+	code, response = deliver(t, router, "push", "delivery-1", push, "secret")
+	if code != http.StatusOK || response["message"] != "Delivery already received" {
+		t.Errorf("Expected the duplicate to be acknowledged, got %d: %v", code, response)
+	}
+	code, _ = deliver(t, router, "push", "delivery-2", push, "wrong")
+	if code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a bad signature, got %d", code)
+	}
+	code, _ = deliver(t, router, "push", "", push, "secret")
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without a delivery ID, got %d", code)
+	}
+
+	// Events go-github doesn't know are acknowledged when nothing handles them
+	code, response = deliver(t, router, "new_event_type", "delivery-3", `{"action": "created"}`, "secret")
+	if code != http.StatusOK || response["handled"] != float64(0) {
+		t.Errorf("Expected the unknown event to be acknowledged, got %d: %v", code, response)
+	}
+}
+
+func Test_Webhooks_NotConfigured(t *testing.T) {
+	router := SetupRouter(githubapi.NewTestClient(&mocks.MockGitHubClient{}, "test-owner"))
+
+	code, _ := deliver(t, router, "ping", "delivery-1", `{}`, "secret")
+	if code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 without a webhook secret, got %d", code)
+	}
+}
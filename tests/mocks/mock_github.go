@@ -1,24 +1,137 @@
 package mocks
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
 )
 
 type MockGitHubClient struct {
 	Repos        []*github.Repository  // Mock repos
 	PullRequests []*github.PullRequest // Mock pull requests
 	Err          error
+
+	PagesFetched int // Number of single page requests made
+
+	// Repository setup, keyed by repo name
+	Labels          map[string][]*github.Label
+	Milestones      map[string][]*github.Milestone
+	Protections     map[string]map[string]githubapi.BranchProtection // By branch
+	Branches        map[string][]*github.Branch
+	TeamPermissions map[string]map[string]string // Permission by team
+	Files           map[string]map[string]string // Content by path
+	Hooks           map[string][]*github.Hook
+	HookDeliveries  map[int64][]*github.HookDelivery // By hook ID, most recent first
+	PingedHooks     []int64
+	Redeliveries    []int64 // Redelivered delivery IDs
+
+	// Access, keyed by repo name
+	Collaborators    map[string]map[string]string // Permission by login
+	Invitations      map[string][]*github.RepositoryInvitation
+	lastInvitationID int64
+
+	// Pull requests of a single repo, other repos list PullRequests
+	RepoPullRequests map[string][]*github.PullRequest
+
+	// Repository data, keyed by repo name
+	Issues   map[string][]*github.Issue
+	Releases map[string][]*github.RepositoryRelease
+	Archive  []byte // Content returned for any archive download
+
+	// Pull request details, keyed by PR number or commit SHA
+	Reviews          map[int][]*github.PullRequestReview
+	PullRequestFiles map[int][]*github.CommitFile
+	CombinedStatuses map[string]*github.CombinedStatus
+	CheckRuns        map[string][]*github.CheckRun
+
+	// Repository statistics, keyed by repo name
+	Languages        map[string]map[string]int
+	ContributorStats map[string][]*github.ContributorStats
+	CommitActivity   map[string][]*github.WeeklyCommitActivity
+	CodeFrequency    map[string][]*github.WeeklyStats
+	TrafficViews     map[string]*github.TrafficViews
+	TrafficClones    map[string]*github.TrafficClones
+	StatsPending     int // Computed statistics requests answered with ErrStatsPending first
+	StatsRequests    int // Number of computed statistics requests made
+
+	Rate *github.Rate // Defaults to a fresh hourly limit
+
+	MethodErrs map[string]error // Errors for specific methods, by method name
+
+	mu sync.Mutex
+}
+
+// errFor returns the error set for the method, falling back to Err
+func (m *MockGitHubClient) errFor(method string) error {
+	if err, ok := m.MethodErrs[method]; ok {
+		return err
+	}
+	return m.Err
 }
 
-func (m *MockGitHubClient) CreateRepoForOwner(ctx context.Context, owner, repoName string) (*github.Repository, error) {
+func (m *MockGitHubClient) findRepo(repoName string) *github.Repository {
+	for _, repo := range m.Repos {
+		if repo.GetName() == repoName {
+			return repo
+		}
+	}
+	return nil
+}
+
+func (m *MockGitHubClient) CreateRepoForOwner(ctx context.Context, owner string, opts githubapi.CreateRepoOptions) (*github.Repository, error) {
 	if m.Err != nil {
 		return nil, m.Err
 	}
-	repo := &github.Repository{Name: github.String(repoName)}
+	repo := &github.Repository{
+		Name:                github.String(opts.Name),
+		Description:         github.String(opts.Description),
+		Homepage:            github.String(opts.Homepage),
+		Topics:              opts.Topics,
+		AllowSquashMerge:    opts.AllowSquashMerge,
+		AllowRebaseMerge:    opts.AllowRebaseMerge,
+		AllowMergeCommit:    opts.AllowMergeCommit,
+		DeleteBranchOnMerge: opts.DeleteBranchOnMerge,
+	}
+	if opts.Visibility != "" {
+		repo.Visibility = github.String(opts.Visibility)
+	}
+	if opts.DefaultBranch != "" {
+		repo.DefaultBranch = github.String(opts.DefaultBranch)
+	}
 	m.Repos = append(m.Repos, repo) // Add to mock repos
+
+	// Setting up the created repo failed
+	if err, ok := m.MethodErrs["CreateRepoForOwner"]; ok {
+		return repo, err
+	}
+	return repo, nil
+}
+
+func (m *MockGitHubClient) CreateRepoFromTemplateForOwner(ctx context.Context, owner string, opts githubapi.TemplateRepoOptions) (*github.Repository, error) {
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	repo := &github.Repository{
+		Name:               github.String(opts.Name),
+		Description:        github.String(opts.Description),
+		DefaultBranch:      github.String("main"),
+		Private:            github.Bool(opts.Visibility == "private"),
+		TemplateRepository: &github.Repository{FullName: github.String(opts.TemplateOwner + "/" + opts.TemplateRepo)},
+	}
+	m.Repos = append(m.Repos, repo)
+
+	// Generated but its contents didn't show up
+	if err, ok := m.MethodErrs["CreateRepoFromTemplateForOwner"]; ok {
+		return repo, err
+	}
 	return repo, nil
 }
 
@@ -37,7 +150,7 @@ func (m *MockGitHubClient) DeleteRepoForOwner(ctx context.Context, owner, repoNa
 	}
 
 	if !repoFound {
-		return fmt.Errorf("repository not found")
+		return fmt.Errorf("repository %w", githubapi.ErrNotFound)
 	}
 
 	for i, repo := range m.Repos {
@@ -57,18 +170,964 @@ func (m *MockGitHubClient) ListReposForOwner(ctx context.Context, owner string)
 	return m.Repos, nil
 }
 
-func (m *MockGitHubClient) ListPullRequestsForOwner(ctx context.Context, owner, repoName string, n int) ([]*github.PullRequest, error) {
+func (m *MockGitHubClient) GetRepoForOwner(ctx context.Context, owner, repoName string) (*github.Repository, error) {
+	if err := m.errFor("GetRepoForOwner"); err != nil {
+		return nil, err
+	}
+
+	repo := m.findRepo(repoName)
+	if repo == nil {
+		return nil, fmt.Errorf("repository %w", githubapi.ErrNotFound)
+	}
+	return repo, nil
+}
+
+func (m *MockGitHubClient) ListPullRequestsForOwner(ctx context.Context, owner, repoName string, filter githubapi.PullRequestFilter, n int) ([]*github.PullRequest, error) {
+	if err := m.errFor("ListPullRequestsForOwner"); err != nil {
+		return nil, err
+	}
+
+	source := m.PullRequests
+	if repoPRs, ok := m.RepoPullRequests[repoName]; ok {
+		source = repoPRs
+	}
+
+	prs := []*github.PullRequest{}
+	for _, pr := range source {
+		if filter.Matches(pr) {
+			prs = append(prs, pr)
+		}
+	}
+
+	if n != -1 && len(prs) > n {
+		return prs[:n], nil
+	}
+	return prs, nil
+}
+
+// SearchPullRequestsForOwner returns the pull requests listing every repo would,
+// as search results
+func (m *MockGitHubClient) SearchPullRequestsForOwner(ctx context.Context, owner string, filter githubapi.PullRequestFilter) ([]*github.Issue, error) {
+	if err := m.errFor("SearchPullRequestsForOwner"); err != nil {
+		return nil, err
+	}
+
+	issues := []*github.Issue{}
+	for _, repo := range m.Repos {
+		prs, err := m.ListPullRequestsForOwner(ctx, owner, repo.GetName(), filter, -1)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			issues = append(issues, &github.Issue{
+				Number:           pr.Number,
+				Title:            pr.Title,
+				User:             pr.User,
+				State:            pr.State,
+				Draft:            pr.Draft,
+				Labels:           pr.Labels,
+				CreatedAt:        pr.CreatedAt,
+				UpdatedAt:        pr.UpdatedAt,
+				HTMLURL:          pr.HTMLURL,
+				RepositoryURL:    github.String("https://api.github.com/repos/" + owner + "/" + repo.GetName()),
+				PullRequestLinks: &github.PullRequestLinks{MergedAt: pr.MergedAt},
+			})
+		}
+	}
+	return issues, nil
+}
+
+// mockPage returns the 1-based page of items and the next page number
+func mockPage[T any](items []T, page, perPage int) ([]T, int) {
+	start := (page - 1) * perPage
+	if start >= len(items) {
+		return []T{}, 0
+	}
+
+	end := start + perPage
+	if end >= len(items) {
+		return items[start:], 0
+	}
+	return items[start:end], page + 1
+}
+
+func (m *MockGitHubClient) ListReposPageForOwner(ctx context.Context, owner string, page, perPage int) ([]*github.Repository, int, error) {
 	if m.Err != nil {
-		return nil, m.Err
+		return nil, 0, m.Err
+	}
+	m.PagesFetched++
+	repos, next := mockPage(m.Repos, page, perPage)
+	return repos, next, nil
+}
+
+func (m *MockGitHubClient) ListPullRequestsPageForOwner(ctx context.Context, owner, repoName string, filter githubapi.PullRequestFilter, page, perPage int) ([]*github.PullRequest, int, error) {
+	if m.Err != nil {
+		return nil, 0, m.Err
+	}
+	m.PagesFetched++
+	prs, next := mockPage(m.PullRequests, page, perPage)
+	return prs, next, nil
+}
+
+func (m *MockGitHubClient) UpdateRepoSettingsForOwner(ctx context.Context, owner, repoName string, settings githubapi.RepoSettings) (*github.Repository, error) {
+	if err := m.errFor("UpdateRepoSettingsForOwner"); err != nil {
+		return nil, err
+	}
+
+	repo := m.findRepo(repoName)
+	if repo == nil {
+		return nil, fmt.Errorf("repository %w", githubapi.ErrNotFound)
+	}
+	if settings.Description != nil {
+		repo.Description = settings.Description
+	}
+	if settings.HasWiki != nil {
+		repo.HasWiki = settings.HasWiki
+	}
+	if settings.AllowSquashMerge != nil {
+		repo.AllowSquashMerge = settings.AllowSquashMerge
+	}
+	if settings.AllowMergeCommit != nil {
+		repo.AllowMergeCommit = settings.AllowMergeCommit
+	}
+	if settings.DeleteBranchOnMerge != nil {
+		repo.DeleteBranchOnMerge = settings.DeleteBranchOnMerge
+	}
+	return repo, nil
+}
+
+func (m *MockGitHubClient) CreateLabelForOwner(ctx context.Context, owner, repoName string, label githubapi.Label) (*github.Label, error) {
+	if err := m.errFor("CreateLabelForOwner"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Labels == nil {
+		m.Labels = map[string][]*github.Label{}
+	}
+
+	created := &github.Label{
+		Name:        github.String(label.Name),
+		Color:       github.String(label.Color),
+		Description: github.String(label.Description),
+	}
+	m.Labels[repoName] = append(m.Labels[repoName], created)
+	return created, nil
+}
+
+func (m *MockGitHubClient) findLabel(repoName, name string) int {
+	for i, label := range m.Labels[repoName] {
+		if strings.EqualFold(label.GetName(), name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MockGitHubClient) UpdateLabelForOwner(ctx context.Context, owner, repoName, name string, label githubapi.Label) (*github.Label, error) {
+	if err := m.errFor("UpdateLabelForOwner"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findLabel(repoName, name)
+	if i < 0 {
+		return nil, fmt.Errorf("label %s %w", name, githubapi.ErrNotFound)
+	}
+	updated := &github.Label{
+		Name:        github.String(label.Name),
+		Color:       github.String(label.Color),
+		Description: github.String(label.Description),
+	}
+	m.Labels[repoName][i] = updated
+	return updated, nil
+}
+
+func (m *MockGitHubClient) DeleteLabelForOwner(ctx context.Context, owner, repoName, name string) error {
+	if err := m.errFor("DeleteLabelForOwner"); err != nil {
+		return err
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	if n == -1 {
-		return m.PullRequests, nil
+	i := m.findLabel(repoName, name)
+	if i < 0 {
+		return fmt.Errorf("label %s %w", name, githubapi.ErrNotFound)
 	}
+	m.Labels[repoName] = append(m.Labels[repoName][:i], m.Labels[repoName][i+1:]...)
+	return nil
+}
+
+func newMockMilestone(number int, milestone githubapi.Milestone) *github.Milestone {
+	created := &github.Milestone{
+		Number:      github.Int(number),
+		Title:       github.String(milestone.Title),
+		Description: github.String(milestone.Description),
+		State:       github.String(milestone.State),
+	}
+	if milestone.DueOn != nil {
+		created.DueOn = &github.Timestamp{Time: *milestone.DueOn}
+	}
+	return created
+}
+
+func (m *MockGitHubClient) CreateMilestoneForOwner(ctx context.Context, owner, repoName string, milestone githubapi.Milestone) (*github.Milestone, error) {
+	if err := m.errFor("CreateMilestoneForOwner"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Milestones == nil {
+		m.Milestones = map[string][]*github.Milestone{}
+	}
+
+	number := 1
+	for _, existing := range m.Milestones[repoName] {
+		if existing.GetNumber() >= number {
+			number = existing.GetNumber() + 1
+		}
+	}
+	created := newMockMilestone(number, milestone)
+	m.Milestones[repoName] = append(m.Milestones[repoName], created)
+	return created, nil
+}
+
+func (m *MockGitHubClient) findMilestone(repoName string, number int) int {
+	for i, milestone := range m.Milestones[repoName] {
+		if milestone.GetNumber() == number {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *MockGitHubClient) UpdateMilestoneForOwner(ctx context.Context, owner, repoName string, number int, milestone githubapi.Milestone) (*github.Milestone, error) {
+	if err := m.errFor("UpdateMilestoneForOwner"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findMilestone(repoName, number)
+	if i < 0 {
+		return nil, fmt.Errorf("milestone %d %w", number, githubapi.ErrNotFound)
+	}
+	updated := newMockMilestone(number, milestone)
+	if updated.DueOn == nil {
+		updated.DueOn = m.Milestones[repoName][i].DueOn
+	}
+	m.Milestones[repoName][i] = updated
+	return updated, nil
+}
+
+func (m *MockGitHubClient) DeleteMilestoneForOwner(ctx context.Context, owner, repoName string, number int) error {
+	if err := m.errFor("DeleteMilestoneForOwner"); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i := m.findMilestone(repoName, number)
+	if i < 0 {
+		return fmt.Errorf("milestone %d %w", number, githubapi.ErrNotFound)
+	}
+	m.Milestones[repoName] = append(m.Milestones[repoName][:i], m.Milestones[repoName][i+1:]...)
+	return nil
+}
+
+func (m *MockGitHubClient) UpdateBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string, protection githubapi.BranchProtection) (*github.Protection, error) {
+	if err := m.errFor("UpdateBranchProtectionForOwner"); err != nil {
+		return nil, err
+	}
+	if m.Protections == nil {
+		m.Protections = map[string]map[string]githubapi.BranchProtection{}
+	}
+	if m.Protections[repoName] == nil {
+		m.Protections[repoName] = map[string]githubapi.BranchProtection{}
+	}
+
+	m.Protections[repoName][branch] = protection
+	return protectionResponse(protection), nil
+}
+
+// protectionResponse builds the protection GitHub would answer for the rules
+func protectionResponse(protection githubapi.BranchProtection) *github.Protection {
+	response := &github.Protection{
+		EnforceAdmins:        &github.AdminEnforcement{Enabled: protection.EnforceAdmins},
+		RequireLinearHistory: &github.RequireLinearHistory{Enabled: protection.RequireLinearHistory},
+	}
+	if reviews := protection.RequiredReviews; reviews != nil {
+		response.RequiredPullRequestReviews = &github.PullRequestReviewsEnforcement{
+			RequiredApprovingReviewCount: reviews.ApprovingReviewCount,
+			DismissStaleReviews:          reviews.DismissStaleReviews,
+			RequireCodeOwnerReviews:      reviews.RequireCodeOwnerReviews,
+		}
+	}
+	if checks := protection.RequiredStatusChecks; checks != nil {
+		contexts := checks.Contexts
+		response.RequiredStatusChecks = &github.RequiredStatusChecks{Strict: checks.Strict, Contexts: &contexts}
+	}
+	if restrictions := protection.PushRestrictions; restrictions != nil {
+		response.Restrictions = &github.BranchRestrictions{}
+		for _, login := range restrictions.Users {
+			response.Restrictions.Users = append(response.Restrictions.Users, &github.User{Login: github.String(login)})
+		}
+		for _, slug := range restrictions.Teams {
+			response.Restrictions.Teams = append(response.Restrictions.Teams, &github.Team{Slug: github.String(slug)})
+		}
+		for _, slug := range restrictions.Apps {
+			response.Restrictions.Apps = append(response.Restrictions.Apps, &github.App{Slug: github.String(slug)})
+		}
+	}
+	return response
+}
+
+func (m *MockGitHubClient) GetBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string) (*github.Protection, error) {
+	if err := m.errFor("GetBranchProtectionForOwner"); err != nil {
+		return nil, err
+	}
+	protection, ok := m.Protections[repoName][branch]
+	if !ok {
+		return nil, fmt.Errorf("branch protection %w", githubapi.ErrNotFound)
+	}
+	return protectionResponse(protection), nil
+}
 
-	if len(m.PullRequests) > n {
-		return m.PullRequests[:n], nil
+func (m *MockGitHubClient) RemoveBranchProtectionForOwner(ctx context.Context, owner, repoName, branch string) error {
+	if err := m.errFor("RemoveBranchProtectionForOwner"); err != nil {
+		return err
 	}
+	if _, ok := m.Protections[repoName][branch]; !ok {
+		return fmt.Errorf("branch protection %w", githubapi.ErrNotFound)
+	}
+	delete(m.Protections[repoName], branch)
+	return nil
+}
+
+func (m *MockGitHubClient) ListBranchesForOwner(ctx context.Context, owner, repoName string) ([]*github.Branch, error) {
+	if err := m.errFor("ListBranchesForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Branches[repoName], nil
+}
+
+// CreateBranchForOwner branches from a branch of the repo or a commit SHA, main by default
+func (m *MockGitHubClient) CreateBranchForOwner(ctx context.Context, owner, repoName, branch, from string) (*github.Branch, error) {
+	if err := m.errFor("CreateBranchForOwner"); err != nil {
+		return nil, err
+	}
+	if from == "" {
+		from = "main"
+	}
+
+	sha := ""
+	if len(from) == 40 {
+		sha = from
+	}
+	for _, existing := range m.Branches[repoName] {
+		if existing.GetName() == branch {
+			return nil, fmt.Errorf("reference already exists: %w", githubapi.ErrValidation)
+		}
+		if existing.GetName() == from {
+			sha = existing.GetCommit().GetSHA()
+		}
+	}
+	if sha == "" {
+		return nil, fmt.Errorf("ref %w", githubapi.ErrNotFound)
+	}
+
+	created := &github.Branch{Name: github.String(branch), Commit: &github.RepositoryCommit{SHA: github.String(sha)}}
+	if m.Branches == nil {
+		m.Branches = map[string][]*github.Branch{}
+	}
+	m.Branches[repoName] = append(m.Branches[repoName], created)
+	return created, nil
+}
+
+func (m *MockGitHubClient) DeleteBranchForOwner(ctx context.Context, owner, repoName, branch string) error {
+	if err := m.errFor("DeleteBranchForOwner"); err != nil {
+		return err
+	}
+	for i, existing := range m.Branches[repoName] {
+		if existing.GetName() == branch {
+			m.Branches[repoName] = append(m.Branches[repoName][:i], m.Branches[repoName][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("branch %w", githubapi.ErrNotFound)
+}
+
+func (m *MockGitHubClient) AddTeamRepoForOwner(ctx context.Context, owner, repoName, teamSlug, permission string) error {
+	if err := m.errFor("AddTeamRepoForOwner"); err != nil {
+		return err
+	}
+	if m.TeamPermissions == nil {
+		m.TeamPermissions = map[string]map[string]string{}
+	}
+	if m.TeamPermissions[repoName] == nil {
+		m.TeamPermissions[repoName] = map[string]string{}
+	}
+
+	m.TeamPermissions[repoName][teamSlug] = permission
+	return nil
+}
+
+func (m *MockGitHubClient) CreateFileForOwner(ctx context.Context, owner, repoName, path string, content []byte, message string) error {
+	if err := m.errFor("CreateFileForOwner"); err != nil {
+		return err
+	}
+	if m.Files == nil {
+		m.Files = map[string]map[string]string{}
+	}
+	if m.Files[repoName] == nil {
+		m.Files[repoName] = map[string]string{}
+	}
+
+	m.Files[repoName][path] = string(content)
+	return nil
+}
+
+func (m *MockGitHubClient) CreateHookForOwner(ctx context.Context, owner, repoName string, hook githubapi.Hook) (*github.Hook, error) {
+	if err := m.errFor("CreateHookForOwner"); err != nil {
+		return nil, err
+	}
+	if m.Hooks == nil {
+		m.Hooks = map[string][]*github.Hook{}
+	}
+
+	created := &github.Hook{
+		ID:     github.Int64(int64(len(m.Hooks[repoName]) + 1)),
+		Config: &github.HookConfig{URL: github.String(hook.URL), ContentType: github.String(hook.ContentType)},
+		Events: hook.Events,
+		Active: hook.Active,
+	}
+	if hook.Secret != "" {
+		created.Config.Secret = github.String("********")
+	}
+	m.Hooks[repoName] = append(m.Hooks[repoName], created)
+	return created, nil
+}
+
+func (m *MockGitHubClient) findHook(repoName string, id int64) (int, error) {
+	for i, hook := range m.Hooks[repoName] {
+		if hook.GetID() == id {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("hook %d %w", id, githubapi.ErrNotFound)
+}
 
-	return m.PullRequests, nil
+func (m *MockGitHubClient) UpdateHookForOwner(ctx context.Context, owner, repoName string, id int64, update githubapi.HookUpdate) (*github.Hook, error) {
+	if err := m.errFor("UpdateHookForOwner"); err != nil {
+		return nil, err
+	}
+	i, err := m.findHook(repoName, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hook := m.Hooks[repoName][i]
+	if update.URL != nil {
+		hook.Config.URL = update.URL
+	}
+	if update.ContentType != nil {
+		hook.Config.ContentType = update.ContentType
+	}
+	if update.Secret != nil {
+		hook.Config.Secret = nil
+		if *update.Secret != "" {
+			hook.Config.Secret = github.String("********")
+		}
+	}
+	if update.Events != nil {
+		hook.Events = update.Events
+	}
+	if update.Active != nil {
+		hook.Active = update.Active
+	}
+	return hook, nil
+}
+
+func (m *MockGitHubClient) DeleteHookForOwner(ctx context.Context, owner, repoName string, id int64) error {
+	if err := m.errFor("DeleteHookForOwner"); err != nil {
+		return err
+	}
+	i, err := m.findHook(repoName, id)
+	if err != nil {
+		return err
+	}
+	m.Hooks[repoName] = append(m.Hooks[repoName][:i], m.Hooks[repoName][i+1:]...)
+	return nil
+}
+
+func (m *MockGitHubClient) PingHookForOwner(ctx context.Context, owner, repoName string, id int64) error {
+	if err := m.errFor("PingHookForOwner"); err != nil {
+		return err
+	}
+	if _, err := m.findHook(repoName, id); err != nil {
+		return err
+	}
+	m.PingedHooks = append(m.PingedHooks, id)
+	return nil
+}
+
+func (m *MockGitHubClient) RedeliverHookDeliveryForOwner(ctx context.Context, owner, repoName string, hookID, deliveryID int64) error {
+	if err := m.errFor("RedeliverHookDeliveryForOwner"); err != nil {
+		return err
+	}
+	for _, delivery := range m.HookDeliveries[hookID] {
+		if delivery.GetID() == deliveryID {
+			m.Redeliveries = append(m.Redeliveries, deliveryID)
+			return nil
+		}
+	}
+	return fmt.Errorf("delivery %d %w", deliveryID, githubapi.ErrNotFound)
+}
+
+func (m *MockGitHubClient) SetRepoArchivedForOwner(ctx context.Context, owner, repoName string, archived bool) (*github.Repository, error) {
+	if err := m.errFor("SetRepoArchivedForOwner"); err != nil {
+		return nil, err
+	}
+
+	repo := m.findRepo(repoName)
+	if repo == nil {
+		return nil, fmt.Errorf("repository %w", githubapi.ErrNotFound)
+	}
+	repo.Archived = github.Bool(archived)
+	return repo, nil
+}
+
+func (m *MockGitHubClient) ReplaceTopicsForOwner(ctx context.Context, owner, repoName string, topics []string) ([]string, error) {
+	if err := m.errFor("ReplaceTopicsForOwner"); err != nil {
+		return nil, err
+	}
+
+	repo := m.findRepo(repoName)
+	if repo == nil {
+		return nil, fmt.Errorf("repository %w", githubapi.ErrNotFound)
+	}
+	repo.Topics = topics
+	return topics, nil
+}
+
+func (m *MockGitHubClient) ListIssuesForOwner(ctx context.Context, owner, repoName string) ([]*github.Issue, error) {
+	if err := m.errFor("ListIssuesForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Issues[repoName], nil
+}
+
+func (m *MockGitHubClient) ListLabelsForOwner(ctx context.Context, owner, repoName string) ([]*github.Label, error) {
+	if err := m.errFor("ListLabelsForOwner"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*github.Label(nil), m.Labels[repoName]...), nil
+}
+
+func (m *MockGitHubClient) ListHooksForOwner(ctx context.Context, owner, repoName string) ([]*github.Hook, error) {
+	if err := m.errFor("ListHooksForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Hooks[repoName], nil
+}
+
+func (m *MockGitHubClient) GetHookForOwner(ctx context.Context, owner, repoName string, id int64) (*github.Hook, error) {
+	if err := m.errFor("GetHookForOwner"); err != nil {
+		return nil, err
+	}
+	i, err := m.findHook(repoName, id)
+	if err != nil {
+		return nil, err
+	}
+	return m.Hooks[repoName][i], nil
+}
+
+// ListHookDeliveriesForOwner uses the index of the next delivery as cursor
+func (m *MockGitHubClient) ListHookDeliveriesForOwner(ctx context.Context, owner, repoName string, id int64, cursor string, perPage int) ([]*github.HookDelivery, string, error) {
+	if err := m.errFor("ListHookDeliveriesForOwner"); err != nil {
+		return nil, "", err
+	}
+	if _, err := m.findHook(repoName, id); err != nil {
+		return nil, "", err
+	}
+
+	deliveries := m.HookDeliveries[id]
+	start := 0
+	if cursor != "" {
+		fmt.Sscan(cursor, &start)
+	}
+	if start > len(deliveries) {
+		start = len(deliveries)
+	}
+	end := start + perPage
+	if end >= len(deliveries) {
+		return deliveries[start:], "", nil
+	}
+	return deliveries[start:end], fmt.Sprint(end), nil
+}
+
+func (m *MockGitHubClient) ListMilestonesForOwner(ctx context.Context, owner, repoName string) ([]*github.Milestone, error) {
+	if err := m.errFor("ListMilestonesForOwner"); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*github.Milestone(nil), m.Milestones[repoName]...), nil
+}
+
+func (m *MockGitHubClient) ListReleasesForOwner(ctx context.Context, owner, repoName string) ([]*github.RepositoryRelease, error) {
+	if err := m.errFor("ListReleasesForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Releases[repoName], nil
+}
+
+func (m *MockGitHubClient) DownloadArchiveForOwner(ctx context.Context, owner, repoName, format string) (io.ReadCloser, error) {
+	if err := m.errFor("DownloadArchiveForOwner"); err != nil {
+		return nil, err
+	}
+	if m.findRepo(repoName) == nil {
+		return nil, fmt.Errorf("repository %w", githubapi.ErrNotFound)
+	}
+	return io.NopCloser(bytes.NewReader(m.Archive)), nil
+}
+
+func (m *MockGitHubClient) GetPullRequestForOwner(ctx context.Context, owner, repoName string, number int) (*github.PullRequest, error) {
+	if err := m.errFor("GetPullRequestForOwner"); err != nil {
+		return nil, err
+	}
+	for _, pr := range m.PullRequests {
+		if pr.GetNumber() == number {
+			return pr, nil
+		}
+	}
+	return nil, fmt.Errorf("pull request %w", githubapi.ErrNotFound)
+}
+
+func (m *MockGitHubClient) ListReviewsForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.PullRequestReview, error) {
+	if err := m.errFor("ListReviewsForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Reviews[number], nil
+}
+
+func (m *MockGitHubClient) ListPullRequestFilesForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.CommitFile, error) {
+	if err := m.errFor("ListPullRequestFilesForOwner"); err != nil {
+		return nil, err
+	}
+	return m.PullRequestFiles[number], nil
+}
+
+func (m *MockGitHubClient) GetCombinedStatusForOwner(ctx context.Context, owner, repoName, ref string) (*github.CombinedStatus, error) {
+	if err := m.errFor("GetCombinedStatusForOwner"); err != nil {
+		return nil, err
+	}
+	if status, ok := m.CombinedStatuses[ref]; ok {
+		return status, nil
+	}
+	return &github.CombinedStatus{State: github.String("pending")}, nil
+}
+
+func (m *MockGitHubClient) ListCheckRunsForOwner(ctx context.Context, owner, repoName, ref string) ([]*github.CheckRun, error) {
+	if err := m.errFor("ListCheckRunsForOwner"); err != nil {
+		return nil, err
+	}
+	return m.CheckRuns[ref], nil
+}
+
+func (m *MockGitHubClient) findPullRequest(number int) *github.PullRequest {
+	for _, pr := range m.PullRequests {
+		if pr.GetNumber() == number {
+			return pr
+		}
+	}
+	return nil
+}
+
+func (m *MockGitHubClient) CreatePullRequestForOwner(ctx context.Context, owner, repoName string, opts githubapi.CreatePullRequestOptions) (*github.PullRequest, error) {
+	if err := m.errFor("CreatePullRequestForOwner"); err != nil {
+		return nil, err
+	}
+
+	pr := &github.PullRequest{
+		Number: github.Int(len(m.PullRequests) + 1),
+		Title:  github.String(opts.Title),
+		Body:   github.String(opts.Body),
+		State:  github.String("open"),
+		Draft:  github.Bool(opts.Draft),
+		Head:   &github.PullRequestBranch{Ref: github.String(opts.Head), SHA: github.String(strings.Repeat("a", 40))},
+		Base:   &github.PullRequestBranch{Ref: github.String(opts.Base)},
+	}
+	m.PullRequests = append(m.PullRequests, pr)
+	return pr, nil
+}
+
+func (m *MockGitHubClient) UpdatePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts githubapi.UpdatePullRequestOptions) (*github.PullRequest, error) {
+	if err := m.errFor("UpdatePullRequestForOwner"); err != nil {
+		return nil, err
+	}
+
+	pr := m.findPullRequest(number)
+	if pr == nil {
+		return nil, fmt.Errorf("pull request %w", githubapi.ErrNotFound)
+	}
+	if opts.Title != nil {
+		pr.Title = opts.Title
+	}
+	if opts.Body != nil {
+		pr.Body = opts.Body
+	}
+	if opts.Base != nil {
+		pr.Base = &github.PullRequestBranch{Ref: opts.Base}
+	}
+	if opts.State != nil {
+		if pr.MergedAt != nil {
+			return nil, fmt.Errorf("merged pull requests can't change state: %w", githubapi.ErrValidation)
+		}
+		pr.State = opts.State
+	}
+	if opts.Draft != nil {
+		pr.Draft = opts.Draft
+	}
+	if opts.Labels != nil {
+		pr.Labels = []*github.Label{}
+		for _, name := range *opts.Labels {
+			pr.Labels = append(pr.Labels, &github.Label{Name: github.String(name)})
+		}
+	}
+	return pr, nil
+}
+
+func (m *MockGitHubClient) MergePullRequestForOwner(ctx context.Context, owner, repoName string, number int, opts githubapi.MergePullRequestOptions) (*github.PullRequestMergeResult, error) {
+	if err := m.errFor("MergePullRequestForOwner"); err != nil {
+		return nil, err
+	}
+
+	pr := m.findPullRequest(number)
+	if pr == nil {
+		return nil, fmt.Errorf("pull request %w", githubapi.ErrNotFound)
+	}
+	if pr.GetState() == "closed" {
+		return nil, fmt.Errorf("pull request is not mergeable: %w", githubapi.ErrConflict)
+	}
+	if opts.SHA != "" && opts.SHA != pr.GetHead().GetSHA() {
+		return nil, fmt.Errorf("head branch was modified: %w", githubapi.ErrConflict)
+	}
+
+	pr.State = github.String("closed")
+	pr.Merged = github.Bool(true)
+	pr.MergedAt = &github.Timestamp{Time: time.Now()}
+	return &github.PullRequestMergeResult{
+		SHA:     github.String(strings.Repeat("b", 40)),
+		Merged:  github.Bool(true),
+		Message: github.String("Pull Request successfully merged"),
+	}, nil
+}
+
+func (m *MockGitHubClient) GetRateLimits(ctx context.Context) (*github.RateLimits, error) {
+	if err := m.errFor("GetRateLimits"); err != nil {
+		return nil, err
+	}
+	core := m.Rate
+	if core == nil {
+		core = &github.Rate{Limit: 5000, Remaining: 5000, Reset: github.Timestamp{Time: time.Now().Add(time.Hour)}}
+	}
+	search := &github.Rate{Limit: 30, Remaining: 30, Reset: github.Timestamp{Time: time.Now().Add(time.Minute)}}
+	return &github.RateLimits{Core: core, Search: search}, nil
+}
+
+func (m *MockGitHubClient) ListLanguagesForOwner(ctx context.Context, owner, repoName string) (map[string]int, error) {
+	if err := m.errFor("ListLanguagesForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Languages[repoName], nil
+}
+
+// statsPending counts a computed statistics request, which is pending while StatsPending lasts
+func (m *MockGitHubClient) statsPending() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.StatsRequests++
+	if m.StatsPending > 0 {
+		m.StatsPending--
+		return true
+	}
+	return false
+}
+
+func (m *MockGitHubClient) ListContributorStatsForOwner(ctx context.Context, owner, repoName string) ([]*github.ContributorStats, error) {
+	if err := m.errFor("ListContributorStatsForOwner"); err != nil {
+		return nil, err
+	}
+	if m.statsPending() {
+		return nil, githubapi.ErrStatsPending
+	}
+	return m.ContributorStats[repoName], nil
+}
+
+func (m *MockGitHubClient) ListCommitActivityForOwner(ctx context.Context, owner, repoName string) ([]*github.WeeklyCommitActivity, error) {
+	if err := m.errFor("ListCommitActivityForOwner"); err != nil {
+		return nil, err
+	}
+	if m.statsPending() {
+		return nil, githubapi.ErrStatsPending
+	}
+	return m.CommitActivity[repoName], nil
+}
+
+func (m *MockGitHubClient) ListCodeFrequencyForOwner(ctx context.Context, owner, repoName string) ([]*github.WeeklyStats, error) {
+	if err := m.errFor("ListCodeFrequencyForOwner"); err != nil {
+		return nil, err
+	}
+	if m.statsPending() {
+		return nil, githubapi.ErrStatsPending
+	}
+	return m.CodeFrequency[repoName], nil
+}
+
+func (m *MockGitHubClient) ListTrafficViewsForOwner(ctx context.Context, owner, repoName string) (*github.TrafficViews, error) {
+	if err := m.errFor("ListTrafficViewsForOwner"); err != nil {
+		return nil, err
+	}
+	if views, ok := m.TrafficViews[repoName]; ok {
+		return views, nil
+	}
+	return &github.TrafficViews{}, nil
+}
+
+func (m *MockGitHubClient) ListTrafficClonesForOwner(ctx context.Context, owner, repoName string) (*github.TrafficClones, error) {
+	if err := m.errFor("ListTrafficClonesForOwner"); err != nil {
+		return nil, err
+	}
+	if clones, ok := m.TrafficClones[repoName]; ok {
+		return clones, nil
+	}
+	return &github.TrafficClones{}, nil
+}
+
+func (m *MockGitHubClient) ListCollaboratorsForOwner(ctx context.Context, owner, repoName string) ([]*github.User, error) {
+	if err := m.errFor("ListCollaboratorsForOwner"); err != nil {
+		return nil, err
+	}
+
+	logins := make([]string, 0, len(m.Collaborators[repoName]))
+	for login := range m.Collaborators[repoName] {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+
+	users := make([]*github.User, 0, len(logins))
+	for _, login := range logins {
+		users = append(users, &github.User{Login: github.String(login), RoleName: github.String(m.Collaborators[repoName][login])})
+	}
+	return users, nil
+}
+
+// AddCollaboratorForOwner updates existing collaborators and pending invitations, and invites anyone else
+func (m *MockGitHubClient) AddCollaboratorForOwner(ctx context.Context, owner, repoName, user, permission string) (*github.RepositoryInvitation, error) {
+	if err := m.errFor("AddCollaboratorForOwner"); err != nil {
+		return nil, err
+	}
+	if _, ok := m.Collaborators[repoName][user]; ok {
+		m.Collaborators[repoName][user] = permission
+		return nil, nil
+	}
+
+	for _, invitation := range m.Invitations[repoName] {
+		if invitation.GetInvitee().GetLogin() == user {
+			invitation.Permissions = github.String(permission)
+			return invitation, nil
+		}
+	}
+
+	m.lastInvitationID++
+	invitation := &github.RepositoryInvitation{
+		ID:          github.Int64(m.lastInvitationID),
+		Invitee:     &github.User{Login: github.String(user)},
+		Inviter:     &github.User{Login: github.String(owner)},
+		Permissions: github.String(permission),
+		CreatedAt:   &github.Timestamp{Time: time.Now()},
+	}
+	if m.Invitations == nil {
+		m.Invitations = map[string][]*github.RepositoryInvitation{}
+	}
+	m.Invitations[repoName] = append(m.Invitations[repoName], invitation)
+	return invitation, nil
+}
+
+func (m *MockGitHubClient) RemoveCollaboratorForOwner(ctx context.Context, owner, repoName, user string) error {
+	if err := m.errFor("RemoveCollaboratorForOwner"); err != nil {
+		return err
+	}
+	if _, ok := m.Collaborators[repoName][user]; !ok {
+		return fmt.Errorf("collaborator %w", githubapi.ErrNotFound)
+	}
+	delete(m.Collaborators[repoName], user)
+	return nil
+}
+
+func (m *MockGitHubClient) ListInvitationsForOwner(ctx context.Context, owner, repoName string) ([]*github.RepositoryInvitation, error) {
+	if err := m.errFor("ListInvitationsForOwner"); err != nil {
+		return nil, err
+	}
+	return m.Invitations[repoName], nil
+}
+
+func (m *MockGitHubClient) UpdateInvitationForOwner(ctx context.Context, owner, repoName string, id int64, permission string) (*github.RepositoryInvitation, error) {
+	if err := m.errFor("UpdateInvitationForOwner"); err != nil {
+		return nil, err
+	}
+	for _, invitation := range m.Invitations[repoName] {
+		if invitation.GetID() == id {
+			invitation.Permissions = github.String(permission)
+			return invitation, nil
+		}
+	}
+	return nil, fmt.Errorf("invitation %w", githubapi.ErrNotFound)
+}
+
+func (m *MockGitHubClient) DeleteInvitationForOwner(ctx context.Context, owner, repoName string, id int64) error {
+	if err := m.errFor("DeleteInvitationForOwner"); err != nil {
+		return err
+	}
+	for i, invitation := range m.Invitations[repoName] {
+		if invitation.GetID() == id {
+			m.Invitations[repoName] = append(m.Invitations[repoName][:i], m.Invitations[repoName][i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("invitation %w", githubapi.ErrNotFound)
+}
+
+func (m *MockGitHubClient) ListTeamsForOwner(ctx context.Context, owner, repoName string) ([]*github.Team, error) {
+	if err := m.errFor("ListTeamsForOwner"); err != nil {
+		return nil, err
+	}
+
+	slugs := make([]string, 0, len(m.TeamPermissions[repoName]))
+	for slug := range m.TeamPermissions[repoName] {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+
+	teams := make([]*github.Team, 0, len(slugs))
+	for _, slug := range slugs {
+		teams = append(teams, &github.Team{Slug: github.String(slug), Name: github.String(slug), Permission: github.String(m.TeamPermissions[repoName][slug])})
+	}
+	return teams, nil
+}
+
+func (m *MockGitHubClient) RemoveTeamRepoForOwner(ctx context.Context, owner, repoName, teamSlug string) error {
+	if err := m.errFor("RemoveTeamRepoForOwner"); err != nil {
+		return err
+	}
+	if _, ok := m.TeamPermissions[repoName][teamSlug]; !ok {
+		return fmt.Errorf("team %w", githubapi.ErrNotFound)
+	}
+	delete(m.TeamPermissions[repoName], teamSlug)
+	return nil
 }
@@ -0,0 +1,87 @@
+package mocks
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockRedis is an in-memory stand-in for httpcache.RedisClient
+type MockRedis struct {
+	Err error
+
+	mu      sync.Mutex
+	values  map[string][]byte
+	Expiry  map[string]time.Duration // Expiry of the last Set, by key
+	Deleted []string
+}
+
+func NewMockRedis() *MockRedis {
+	return &MockRedis{values: map[string][]byte{}, Expiry: map[string]time.Duration{}}
+}
+
+func (m *MockRedis) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, false, m.Err
+	}
+	value, ok := m.values[key]
+	return value, ok, nil
+}
+
+func (m *MockRedis) Set(ctx context.Context, key string, value []byte, expiry time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	m.values[key] = value
+	m.Expiry[key] = expiry
+	return nil
+}
+
+func (m *MockRedis) Del(ctx context.Context, keys ...string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	for _, key := range keys {
+		delete(m.values, key)
+		m.Deleted = append(m.Deleted, key)
+	}
+	return nil
+}
+
+func (m *MockRedis) ScanPrefix(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	var keys []string
+	for key := range m.values {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// Keys lists the stored keys
+func (m *MockRedis) Keys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.values))
+	for key := range m.values {
+		keys = append(keys, key)
+	}
+	return keys
+}
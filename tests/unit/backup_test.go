@@ -0,0 +1,230 @@
+package githubapi_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/backup"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func newBackupMock() *mocks.MockGitHubClient {
+	return &mocks.MockGitHubClient{
+		Repos:   []*github.Repository{{Name: github.String("repo1")}},
+		Archive: []byte("tarball content"),
+		Issues: map[string][]*github.Issue{"repo1": {
+			{Number: github.Int(1), Title: github.String("An issue")},
+			{Number: github.Int(2), Title: github.String("A PR"), PullRequestLinks: &github.PullRequestLinks{}},
+		}},
+		Labels: map[string][]*github.Label{"repo1": {{Name: github.String("bug")}}},
+	}
+}
+
+func TestBackup_TarballToLocalStorage(t *testing.T) {
+	dir := t.TempDir()
+	client := githubapi.NewTestClient(newBackupMock(), "test_owner")
+	service := backup.NewService(client, backup.NewLocalStorage(dir))
+
+	manifest, err := service.Run(context.Background(), "repo1", backup.FormatTarball)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(manifest.Location, filepath.Join(dir, "repo1")) {
+		t.Errorf("expected the backup under %s, got %s", dir, manifest.Location)
+	}
+
+	expected := []string{"repository.tar.gz", "issues.json", "pulls.json", "labels.json", "releases.json"}
+	if strings.Join(manifest.Files, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected files %v, got %v", expected, manifest.Files)
+	}
+
+	archive, err := os.ReadFile(filepath.Join(manifest.Location, "repository.tar.gz"))
+	if err != nil || string(archive) != "tarball content" {
+		t.Errorf("expected the archive to be stored, got %q (%v)", archive, err)
+	}
+
+	// Pull requests are split out of the issues
+	issues, _ := os.ReadFile(filepath.Join(manifest.Location, "issues.json"))
+	pulls, _ := os.ReadFile(filepath.Join(manifest.Location, "pulls.json"))
+	if !strings.Contains(string(issues), "An issue") || strings.Contains(string(issues), "A PR") {
+		t.Errorf("unexpected issues.json: %s", issues)
+	}
+	if !strings.Contains(string(pulls), "A PR") {
+		t.Errorf("unexpected pulls.json: %s", pulls)
+	}
+
+	manifests, err := service.List(context.Background(), "repo1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Format != backup.FormatTarball {
+		t.Errorf("expected the backup to be listed, got %+v", manifests)
+	}
+
+	if manifests, _ := service.List(context.Background(), "other-repo"); len(manifests) != 0 {
+		t.Errorf("expected no backups for other-repo, got %+v", manifests)
+	}
+}
+
+func TestBackup_InvalidFormat(t *testing.T) {
+	client := githubapi.NewTestClient(newBackupMock(), "test_owner")
+	service := backup.NewService(client, backup.NewLocalStorage(t.TempDir()))
+
+	if _, err := service.Run(context.Background(), "repo1", "rar"); err != backup.ErrInvalidFormat {
+		t.Errorf("expected ErrInvalidFormat, got %v", err)
+	}
+}
+
+func TestBackup_MirrorWithoutGit(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	client := githubapi.NewTestClient(newBackupMock(), "test_owner")
+	service := backup.NewService(client, backup.NewLocalStorage(t.TempDir()))
+
+	_, err := service.Run(context.Background(), "repo1", backup.FormatMirror)
+	if err != backup.ErrMirrorUnavailable {
+		t.Errorf("expected ErrMirrorUnavailable, got %v", err)
+	}
+	if githubapi.HTTPStatus(err) != http.StatusNotImplemented {
+		t.Errorf("expected a 501, got %d", githubapi.HTTPStatus(err))
+	}
+}
+
+func TestBackup_FailureWritesNoManifest(t *testing.T) {
+	mockClient := newBackupMock()
+	mockClient.MethodErrs = map[string]error{"ListReleasesForOwner": githubapi.Error("boom")}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	service := backup.NewService(client, backup.NewLocalStorage(t.TempDir()))
+
+	if _, err := service.Run(context.Background(), "repo1", backup.FormatTarball); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	manifests, err := service.List(context.Background(), "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manifests) != 0 {
+		t.Errorf("expected the incomplete backup not to be listed, got %+v", manifests)
+	}
+}
+
+func TestBackup_Mirror(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// A local repository stands in for GitHub
+	source := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--quiet", source},
+		{"-C", source, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "--quiet", "--allow-empty", "-m", "initial"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+
+	mockClient := newBackupMock()
+	mockClient.Repos[0].CloneURL = github.String(source)
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	service := backup.NewService(client, backup.NewLocalStorage(t.TempDir()))
+
+	manifest, err := service.Run(context.Background(), "repo1", backup.FormatMirror)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	bundle := filepath.Join(manifest.Location, "repository.bundle")
+	if out, err := exec.Command("git", "bundle", "list-heads", bundle).CombinedOutput(); err != nil {
+		t.Errorf("expected a valid bundle, got %v: %s", err, out)
+	}
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	storage := backup.NewLocalStorage(t.TempDir())
+
+	err := storage.Put(context.Background(), "../outside", strings.NewReader("x"))
+	if err != backup.ErrInvalidKey {
+		t.Errorf("expected ErrInvalidKey, got %v", err)
+	}
+}
+
+// fakeS3 is a bucket that keeps objects in memory and checks requests are signed
+func fakeS3(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+			t.Errorf("expected a signed request, got Authorization %q", auth)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		switch {
+		case r.Method == http.MethodPut:
+			objects[key], _ = io.ReadAll(r.Body)
+		case r.URL.Path == "/bucket" && r.URL.Query().Get("list-type") == "2":
+			io.WriteString(w, "<ListBucketResult>")
+			for k := range objects {
+				if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+					io.WriteString(w, "<Contents><Key>"+k+"</Key></Contents>")
+				}
+			}
+			io.WriteString(w, "<IsTruncated>false</IsTruncated></ListBucketResult>")
+		case r.Method == http.MethodGet:
+			body, ok := objects[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(body)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBackup_S3Storage(t *testing.T) {
+	server := fakeS3(t)
+	storage := &backup.S3Storage{
+		Endpoint:  server.URL,
+		Bucket:    "bucket",
+		Region:    "us-east-1",
+		AccessKey: "key",
+		SecretKey: "secret",
+	}
+	client := githubapi.NewTestClient(newBackupMock(), "test_owner")
+	service := backup.NewService(client, storage)
+
+	manifest, err := service.Run(context.Background(), "repo1", backup.FormatZipball)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(manifest.Location, "s3://bucket/repo1/") {
+		t.Errorf("unexpected location %s", manifest.Location)
+	}
+
+	manifests, err := service.List(context.Background(), "repo1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(manifests) != 1 || manifests[0].Format != backup.FormatZipball {
+		t.Errorf("expected the backup to be listed, got %+v", manifests)
+	}
+}
@@ -0,0 +1,214 @@
+package githubapi_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/blueprint"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+const serviceBlueprint = `
+name: go-service
+auto_init: true
+settings:
+  has_wiki: false
+  delete_branch_on_merge: true
+files:
+  - path: CODEOWNERS
+    content: "* @my-org/platform"
+  - path: .github/workflows/ci.yaml
+    source: files/ci.yaml
+labels:
+  - name: bug
+    color: "#d73a4a"
+teams:
+  - team: platform
+    permission: maintain
+webhooks:
+  - url: https://ci.example.com/hook
+    events: [push, pull_request]
+branch_protection:
+  - required_reviews:
+      approving_review_count: 1
+    enforce_admins: true
+`
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+}
+
+func loadServiceBlueprint(t *testing.T) *blueprint.Blueprint {
+	t.Helper()
+
+	dir := t.TempDir()
+	writeFile(t, dir, "go-service.yaml", serviceBlueprint)
+	if err := os.Mkdir(filepath.Join(dir, "files"), 0o750); err != nil {
+		t.Fatalf("failed to create files dir: %v", err)
+	}
+	writeFile(t, dir, "files/ci.yaml", "name: ci")
+	writeFile(t, dir, "minimal.json", `{"labels": [{"name": "docs", "color": "0075ca"}]}`)
+	writeFile(t, dir, "notes.txt", "not a blueprint")
+
+	registry, err := blueprint.LoadDir(dir)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	names := registry.Names()
+	if len(names) != 2 || names[0] != "go-service" || names[1] != "minimal" {
+		t.Fatalf("unexpected blueprints: %v", names)
+	}
+
+	bp, _ := registry.Get("go-service")
+	return bp
+}
+
+func TestBlueprint_LoadDir(t *testing.T) {
+	bp := loadServiceBlueprint(t)
+
+	if bp.Files[1].Content != "name: ci" {
+		t.Errorf("expected file content to be read from source, got %q", bp.Files[1].Content)
+	}
+	if bp.Labels[0].Color != "d73a4a" {
+		t.Errorf("expected the # to be dropped from the label color, got %q", bp.Labels[0].Color)
+	}
+	if bp.Webhooks[0].ContentType != "json" {
+		t.Errorf("expected webhook content type to default to json, got %q", bp.Webhooks[0].ContentType)
+	}
+	if bp.BranchProtection[0].RequiredReviews.ApprovingReviewCount != 1 {
+		t.Errorf("unexpected branch protection: %+v", bp.BranchProtection[0])
+	}
+}
+
+func TestBlueprint_LoadDir_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field": `{"labelz": []}`,
+		"bad label":     `{"labels": [{"name": "bug", "color": "red"}]}`,
+		"bad team":      `{"teams": [{"team": "platform", "permission": "owner"}]}`,
+		"bad webhook":   `{"webhooks": [{"url": "ftp://example.com"}]}`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, dir, "bad.json", content)
+
+			if _, err := blueprint.LoadDir(dir); err == nil {
+				t.Fatal("expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestBlueprint_LoadDir_SourceOutsideDirectory(t *testing.T) {
+	parent := t.TempDir()
+	writeFile(t, parent, "secret", "password")
+	dir := filepath.Join(parent, "blueprints")
+	if err := os.Mkdir(dir, 0o700); err != nil {
+		t.Fatalf("failed to create %s: %v", dir, err)
+	}
+
+	for _, source := range []string{"../secret", "ci/../../secret", filepath.Join(parent, "secret")} {
+		writeFile(t, dir, "bad.json", `{"files": [{"path": "leak", "source": "`+filepath.ToSlash(source)+`"}]}`)
+		if _, err := blueprint.LoadDir(dir); err == nil {
+			t.Errorf("expected source %q to be refused", source)
+		}
+	}
+}
+
+func TestBlueprint_Apply_Success(t *testing.T) {
+	bp := loadServiceBlueprint(t)
+	mockClient := &mocks.MockGitHubClient{}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	result, err := blueprint.Apply(context.Background(), client, githubapi.CreateRepoOptions{Name: "svc"}, bp)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expectedSteps := []string{
+		"create repository",
+		"settings",
+		"file CODEOWNERS",
+		"file .github/workflows/ci.yaml",
+		"label bug",
+		"team platform",
+		"webhook https://ci.example.com/hook",
+		"branch protection (default branch)",
+	}
+	if len(result.Steps) != len(expectedSteps) {
+		t.Fatalf("expected %d steps, got %+v", len(expectedSteps), result.Steps)
+	}
+	for i, step := range result.Steps {
+		if step.Step != expectedSteps[i] || step.Status != blueprint.StatusOK {
+			t.Errorf("expected step %q to be ok, got %+v", expectedSteps[i], step)
+		}
+	}
+
+	if mockClient.Files["svc"]["CODEOWNERS"] != "* @my-org/platform" {
+		t.Errorf("expected CODEOWNERS to be committed, got %+v", mockClient.Files["svc"])
+	}
+	if mockClient.TeamPermissions["svc"]["platform"] != "maintain" {
+		t.Errorf("expected platform team to get maintain, got %+v", mockClient.TeamPermissions["svc"])
+	}
+	if _, ok := mockClient.Protections["svc"]["main"]; !ok {
+		t.Errorf("expected the default branch to be protected, got %+v", mockClient.Protections["svc"])
+	}
+	if !mockClient.Repos[0].GetDeleteBranchOnMerge() {
+		t.Error("expected the settings to be applied")
+	}
+}
+
+func TestBlueprint_Apply_RollsBackOnFailure(t *testing.T) {
+	bp := loadServiceBlueprint(t)
+	mockClient := &mocks.MockGitHubClient{
+		MethodErrs: map[string]error{"CreateHookForOwner": githubapi.Error("mock error")},
+	}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	result, err := blueprint.Apply(context.Background(), client, githubapi.CreateRepoOptions{Name: "svc"}, bp)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !result.RolledBack {
+		t.Error("expected the repo to be rolled back")
+	}
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("expected the repo to be deleted, got %d repos", len(mockClient.Repos))
+	}
+
+	last := result.Steps[len(result.Steps)-1]
+	failed := result.Steps[len(result.Steps)-2]
+	if failed.Status != blueprint.StatusFailed || failed.Error != "mock error" {
+		t.Errorf("expected the webhook step to fail, got %+v", failed)
+	}
+	if last.Status != blueprint.StatusSkipped {
+		t.Errorf("expected branch protection to be skipped, got %+v", last)
+	}
+}
+
+func TestBlueprint_Apply_RollsBackPartiallyCreatedRepo(t *testing.T) {
+	bp := loadServiceBlueprint(t)
+	mockClient := &mocks.MockGitHubClient{
+		MethodErrs: map[string]error{"CreateRepoForOwner": githubapi.Error("repository created but setting topics failed")},
+	}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	result, err := blueprint.Apply(context.Background(), client, githubapi.CreateRepoOptions{Name: "svc"}, bp)
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if !result.RolledBack || len(mockClient.Repos) != 0 {
+		t.Errorf("expected the created repo to be deleted, got rolled back %v and %d repos", result.RolledBack, len(mockClient.Repos))
+	}
+	if result.Steps[0].Status != blueprint.StatusFailed {
+		t.Errorf("expected the create step to fail, got %+v", result.Steps[0])
+	}
+}
@@ -0,0 +1,110 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestNewCollaborator(t *testing.T) {
+	tests := []struct {
+		user     *github.User
+		expected string
+	}{
+		{&github.User{RoleName: github.String("write")}, "push"},
+		{&github.User{RoleName: github.String("maintain")}, "maintain"},
+		{&github.User{Permissions: map[string]bool{"pull": true, "triage": true, "push": true}}, "push"},
+		{&github.User{Permissions: map[string]bool{"pull": true}}, "pull"},
+	}
+	for _, test := range tests {
+		if got := githubapi.NewCollaborator(test.user).Permission; got != test.expected {
+			t.Errorf("expected %q for %+v, got %q", test.expected, test.user, got)
+		}
+	}
+}
+
+func TestRealClient_AddCollaborator(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/collaborators/alice", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["permission"] != "maintain" {
+			t.Errorf("expected maintain, got %v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id": 7, "invitee": {"login": "alice"}, "permissions": "maintain"}`)
+	})
+	mux.HandleFunc("/repos/my-org/repo1/collaborators/bob", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	invitation, err := client.AddCollaboratorForOwner(context.Background(), "my-org", "repo1", "alice", "maintain")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if invitation.GetID() != 7 || githubapi.NewInvitation(invitation).Invitee != "alice" {
+		t.Errorf("expected an invitation for alice, got %+v", invitation)
+	}
+
+	invitation, err = client.AddCollaboratorForOwner(context.Background(), "my-org", "repo1", "bob", "push")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if invitation != nil {
+		t.Errorf("expected no invitation for an existing collaborator, got %+v", invitation)
+	}
+}
+
+func TestRealClient_UpdateInvitation_PermissionNames(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/invitations/7", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["permissions"] != "write" {
+			t.Errorf("expected push to be sent as write, got %v", body)
+		}
+		fmt.Fprint(w, `{"id": 7, "permissions": "write"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	invitation, err := client.UpdateInvitationForOwner(context.Background(), "my-org", "repo1", 7, "push")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := githubapi.NewInvitation(invitation).Permission; got != "push" {
+		t.Errorf("expected write to read back as push, got %q", got)
+	}
+}
+
+func TestRealClient_ListTeams_OutsideOrg(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/jo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "jo", "type": "User"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	if _, err := client.ListTeamsForOwner(context.Background(), "jo", "repo1"); err != githubapi.ErrTeamsOutsideOrg {
+		t.Errorf("expected ErrTeamsOutsideOrg, got %v", err)
+	}
+}
+
+func TestClient_AccessValidation(t *testing.T) {
+	client := githubapi.NewTestClient(&mocks.MockGitHubClient{}, "my-org")
+
+	if _, err := client.SetCollaborator(context.Background(), "repo1", "alice", "write"); err != githubapi.ErrInvalidPermission {
+		t.Errorf("expected ErrInvalidPermission, got %v", err)
+	}
+	if _, err := client.UpdateInvitation(context.Background(), "repo1", 0, "push"); err != githubapi.ErrInvalidInvitation {
+		t.Errorf("expected ErrInvalidInvitation, got %v", err)
+	}
+}
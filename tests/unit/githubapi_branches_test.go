@@ -0,0 +1,117 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	valid := []string{"main", "feature/login", "release-1.2", "user/jo/fix_3"}
+	for _, name := range valid {
+		if err := githubapi.ValidateBranchName(name); err != nil {
+			t.Errorf("expected %q to be valid, got %v", name, err)
+		}
+	}
+
+	invalid := []string{"", "-main", "feature/", "/feature", "a..b", "a//b", "has space", "a~1", "a^", "a:b", "a?", "a*", "a[b", `a\b`, "x.lock", "end.", "feature/.hidden", "@", "a@{b"}
+	for _, name := range invalid {
+		if err := githubapi.ValidateBranchName(name); err != githubapi.ErrInvalidBranchName {
+			t.Errorf("expected %q to be invalid, got %v", name, err)
+		}
+	}
+}
+
+func TestNewBranchProtection(t *testing.T) {
+	contexts := []string{"ci/build"}
+	protection := githubapi.NewBranchProtection(&github.Protection{
+		RequiredPullRequestReviews: &github.PullRequestReviewsEnforcement{RequiredApprovingReviewCount: 2, RequireCodeOwnerReviews: true},
+		RequiredStatusChecks:       &github.RequiredStatusChecks{Strict: true, Contexts: &contexts},
+		EnforceAdmins:              &github.AdminEnforcement{Enabled: true},
+		RequireLinearHistory:       &github.RequireLinearHistory{Enabled: true},
+		Restrictions: &github.BranchRestrictions{
+			Users: []*github.User{{Login: github.String("alice")}},
+			Teams: []*github.Team{{Slug: github.String("release")}},
+		},
+	})
+
+	expected := githubapi.BranchProtection{
+		RequiredReviews:      &githubapi.RequiredReviews{ApprovingReviewCount: 2, RequireCodeOwnerReviews: true},
+		RequiredStatusChecks: &githubapi.RequiredStatusChecks{Strict: true, Contexts: []string{"ci/build"}},
+		EnforceAdmins:        true,
+		RequireLinearHistory: true,
+		PushRestrictions:     &githubapi.PushRestrictions{Users: []string{"alice"}, Teams: []string{"release"}, Apps: []string{}},
+	}
+	if !reflect.DeepEqual(protection, expected) {
+		t.Errorf("expected %+v, got %+v", expected, protection)
+	}
+
+	if empty := githubapi.NewBranchProtection(&github.Protection{}); !reflect.DeepEqual(empty, githubapi.BranchProtection{}) {
+		t.Errorf("expected no rules, got %+v", empty)
+	}
+}
+
+func TestRealClient_CreateBranch(t *testing.T) {
+	var created struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "repo1", "default_branch": "trunk"}`)
+	})
+	mux.HandleFunc("/repos/my-org/repo1/commits/trunk", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0123456789abcdef0123456789abcdef01234567")
+	})
+	mux.HandleFunc("/repos/my-org/repo1/git/refs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		json.NewDecoder(r.Body).Decode(&created)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"ref": "refs/heads/feature"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	branch, err := client.CreateBranchForOwner(context.Background(), "my-org", "repo1", "feature", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if created.Ref != "refs/heads/feature" || created.SHA != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("expected feature to be created from trunk's head, got %+v", created)
+	}
+	if githubapi.NewBranch(branch).SHA != created.SHA {
+		t.Errorf("unexpected branch %+v", branch)
+	}
+}
+
+func TestRealClient_GetBranchProtection_NotProtected(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/branches/main/protection", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Branch not protected"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	if _, err := client.GetBranchProtectionForOwner(context.Background(), "my-org", "repo1", "main"); !errors.Is(err, githubapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestClient_CreateBranch_Invalid(t *testing.T) {
+	client := githubapi.NewTestClient(&mocks.MockGitHubClient{}, "my-org")
+
+	if _, err := client.CreateBranch(context.Background(), "repo1", githubapi.CreateBranchOptions{Branch: "bad name"}); err != githubapi.ErrInvalidBranchName {
+		t.Errorf("expected ErrInvalidBranchName, got %v", err)
+	}
+}
@@ -35,3 +35,69 @@ func TestClient_CreateRepo_Error(t *testing.T) {
 		t.Fatal("expected an error, got nil")
 	}
 }
+
+func TestClient_CreateRepoWithOptions_Success(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	opts := githubapi.CreateRepoOptions{
+		Name:          "new-repo",
+		Description:   "A new repo",
+		Visibility:    "private",
+		Topics:        []string{"go", "api"},
+		AutoInit:      true,
+		DefaultBranch: "main",
+	}
+	repo, err := client.CreateRepoWithOptions(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repo.GetDescription() != "A new repo" || repo.GetVisibility() != "private" {
+		t.Errorf("expected the options to be passed through, got %+v", repo)
+	}
+	if len(repo.Topics) != 2 || repo.GetDefaultBranch() != "main" {
+		t.Errorf("expected topics and default branch to be set, got %+v", repo)
+	}
+}
+
+func TestCreateRepoOptions_Validate(t *testing.T) {
+	no := false
+	tests := []struct {
+		name     string
+		opts     githubapi.CreateRepoOptions
+		expected error
+	}{
+		{"valid", githubapi.CreateRepoOptions{Name: "repo"}, nil},
+		{"bad name", githubapi.CreateRepoOptions{Name: "bad name"}, githubapi.ErrInvalidRepoName},
+		{"bad visibility", githubapi.CreateRepoOptions{Name: "repo", Visibility: "secret"}, githubapi.ErrInvalidVisibility},
+		{"bad homepage", githubapi.CreateRepoOptions{Name: "repo", Homepage: "not a url"}, githubapi.ErrInvalidHomepage},
+		{"bad topic", githubapi.CreateRepoOptions{Name: "repo", Topics: []string{"Upper Case"}}, githubapi.ErrInvalidTopic},
+		{
+			"no merge strategy",
+			githubapi.CreateRepoOptions{Name: "repo", AllowSquashMerge: &no, AllowRebaseMerge: &no, AllowMergeCommit: &no},
+			githubapi.ErrNoMergeStrategy,
+		},
+		{"default branch without init", githubapi.CreateRepoOptions{Name: "repo", DefaultBranch: "main"}, githubapi.ErrDefaultBranchNoInit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); err != tt.expected {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
+
+func TestClient_CreateRepoWithOptions_Invalid(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	_, err := client.CreateRepoWithOptions(context.Background(), githubapi.CreateRepoOptions{Name: "bad name"})
+	if err != githubapi.ErrInvalidRepoName {
+		t.Fatalf("expected ErrInvalidRepoName, got %v", err)
+	}
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("expected no repo to be created, got %d", len(mockClient.Repos))
+	}
+}
@@ -0,0 +1,112 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+func TestRealClient_DeleteRepo_ChecksRepoDirectly(t *testing.T) {
+	deleted := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			fmt.Fprint(w, `{"name": "repo1"}`)
+		case http.MethodDelete:
+			deleted = true
+			w.WriteHeader(http.StatusNoContent)
+		}
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	if err := client.DeleteRepoForOwner(context.Background(), "my-org", "repo1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !deleted {
+		t.Error("expected the repo to be deleted")
+	}
+}
+
+func TestRealClient_DeleteRepo_NotFound(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/missing", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			t.Error("expected no delete request")
+		}
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	err := client.DeleteRepoForOwner(context.Background(), "my-org", "missing")
+	if !errors.Is(err, githubapi.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err.Error() != "repository not found" {
+		t.Errorf("expected message %q, got %q", "repository not found", err.Error())
+	}
+}
+
+func TestRealClient_TypedErrors(t *testing.T) {
+	tests := []struct {
+		status   int
+		body     string
+		expected error
+		code     int
+	}{
+		{http.StatusForbidden, `{"message": "Must have admin rights"}`, githubapi.ErrForbidden, http.StatusForbidden},
+		{http.StatusConflict, `{"message": "Repository is archived"}`, githubapi.ErrConflict, http.StatusConflict},
+		{http.StatusUnprocessableEntity, `{"message": "Validation Failed"}`, githubapi.ErrValidation, http.StatusUnprocessableEntity},
+		{http.StatusTooManyRequests, `{"message": "Too many requests"}`, githubapi.ErrRateLimited, http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/my-org/repo1", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+			_, err := client.GetRepoForOwner(context.Background(), "my-org", "repo1")
+			if !errors.Is(err, tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, err)
+			}
+			if code := githubapi.HTTPStatus(err); code != tt.code {
+				t.Errorf("expected status %d, got %d", tt.code, code)
+			}
+		})
+	}
+}
+
+func TestRealClient_PrimaryRateLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800")
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "API rate limit exceeded"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	_, err := client.GetRepoForOwner(context.Background(), "my-org", "repo1")
+	if !errors.Is(err, githubapi.ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+}
+
+func TestHTTPStatus_UntypedError(t *testing.T) {
+	if code := githubapi.HTTPStatus(errors.New("boom")); code != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", code)
+	}
+}
@@ -0,0 +1,104 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestRealClient_UpdateHook(t *testing.T) {
+	var config, hook map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/hooks/7/config", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&config)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/repos/my-org/repo1/hooks/7", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			json.NewDecoder(r.Body).Decode(&hook)
+		}
+		fmt.Fprint(w, `{"id": 7, "active": false, "events": ["push"], "config": {"url": "https://ci.example.com/new", "secret": "********"}}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	updated, err := client.UpdateHookForOwner(context.Background(), "my-org", "repo1", 7, githubapi.HookUpdate{
+		URL:    github.String("https://ci.example.com/new"),
+		Active: github.Bool(false),
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// The secret isn't sent, so GitHub keeps it
+	if len(config) != 1 || config["url"] != "https://ci.example.com/new" {
+		t.Errorf("expected only the url in the configuration, got %v", config)
+	}
+	if _, ok := hook["config"]; ok || hook["active"] != false {
+		t.Errorf("expected only the active flag in the hook, got %v", hook)
+	}
+	if converted := githubapi.NewRepoHook(updated); !converted.HasSecret || converted.Active {
+		t.Errorf("unexpected hook %+v", converted)
+	}
+}
+
+func TestRealClient_ListHookDeliveries(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/hooks/7/deliveries", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("per_page") != "2" {
+			t.Errorf("expected per_page=2, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Link", `<https://api.github.com/repos/my-org/repo1/hooks/7/deliveries?per_page=2&cursor=v1_42>; rel="next"`)
+		fmt.Fprint(w, `[{"id": 43, "guid": "abc", "event": "push", "status_code": 200, "duration": 0.12}, {"id": 42, "status_code": 500}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	deliveries, next, err := client.ListHookDeliveriesForOwner(context.Background(), "my-org", "repo1", 7, "", 2)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if next != "v1_42" {
+		t.Errorf("expected GitHub's cursor, got %q", next)
+	}
+	if len(deliveries) != 2 || githubapi.NewHookDelivery(deliveries[0]).Duration != 0.12 {
+		t.Errorf("unexpected deliveries %+v", deliveries)
+	}
+}
+
+func TestRealClient_RedeliverHookDelivery_Accepted(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/hooks/7/deliveries/42/attempts", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	if err := client.RedeliverHookDeliveryForOwner(context.Background(), "my-org", "repo1", 7, 42); err != nil {
+		t.Errorf("expected a 202 to be a success, got %v", err)
+	}
+}
+
+func TestClient_HookValidation(t *testing.T) {
+	client := githubapi.NewTestClient(&mocks.MockGitHubClient{}, "my-org")
+
+	if _, err := client.UpdateHook(context.Background(), "repo1", 1, githubapi.HookUpdate{ContentType: github.String("xml")}); err != githubapi.ErrInvalidHookContentType {
+		t.Errorf("expected ErrInvalidHookContentType, got %v", err)
+	}
+	if _, err := client.UpdateHook(context.Background(), "repo1", 1, githubapi.HookUpdate{Events: []string{}}); err != githubapi.ErrInvalidHookEvents {
+		t.Errorf("expected ErrInvalidHookEvents, got %v", err)
+	}
+	if err := client.PingHook(context.Background(), "repo1", 0); err != githubapi.ErrInvalidHookID {
+		t.Errorf("expected ErrInvalidHookID, got %v", err)
+	}
+	if _, _, err := client.ListHookDeliveries(context.Background(), "repo1", 1, "", 500); err != githubapi.ErrInvalidPageSize {
+		t.Errorf("expected ErrInvalidPageSize, got %v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+func TestRealClient_UpdateLabel(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/labels/Bug", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("expected PATCH, got %s", r.Method)
+		}
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["name"] != "bug" || body["color"] != "d73a4a" {
+			t.Errorf("expected Bug to be renamed and recolored, got %v", body)
+		}
+		fmt.Fprint(w, `{"name": "bug", "color": "d73a4a"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	label, err := client.UpdateLabelForOwner(context.Background(), "my-org", "repo1", "Bug", githubapi.Label{Name: "bug", Color: "d73a4a"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if label.GetName() != "bug" {
+		t.Errorf("unexpected label %+v", label)
+	}
+}
+
+func TestRealClient_ListMilestones_All(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/milestones", func(w http.ResponseWriter, r *http.Request) {
+		if state := r.URL.Query().Get("state"); state != "all" {
+			t.Errorf("expected closed milestones to be listed too, got state=%q", state)
+		}
+		fmt.Fprint(w, `[{"number": 1, "title": "v1.0", "state": "closed", "due_on": "2025-06-30T07:00:00Z"}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	milestones, err := client.ListMilestonesForOwner(context.Background(), "my-org", "repo1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(milestones) != 1 {
+		t.Fatalf("expected 1 milestone, got %d", len(milestones))
+	}
+	milestone := githubapi.NewMilestone(milestones[0])
+	if milestone.State != "closed" || milestone.DueOn == nil || milestone.DueOn.Day() != 30 {
+		t.Errorf("unexpected milestone %+v", milestone)
+	}
+}
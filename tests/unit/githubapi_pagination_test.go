@@ -0,0 +1,137 @@
+package githubapi_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func makeRepos(n int) []*github.Repository {
+	repos := make([]*github.Repository, 0, n)
+	for i := 0; i < n; i++ {
+		repos = append(repos, &github.Repository{Name: github.String(fmt.Sprintf("repo%03d", i))})
+	}
+	return repos
+}
+
+func TestIterator_FetchesOnlyConsumedPages(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{Repos: makeRepos(250)}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	it := client.RepoIterator(githubapi.Cursor{})
+	for i := 0; i < 101; i++ {
+		if _, ok, err := it.Next(context.Background()); err != nil || !ok {
+			t.Fatalf("expected item %d, got ok=%v err=%v", i, ok, err)
+		}
+	}
+
+	// 101 items span the first two pages of 100
+	if mockClient.PagesFetched != 2 {
+		t.Errorf("expected 2 pages fetched, got %d", mockClient.PagesFetched)
+	}
+}
+
+func TestClient_ListRepositoriesPage_WalksAllPages(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{Repos: makeRepos(250)}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	var names []string
+	req := githubapi.PageRequest{Size: 40}
+	for {
+		repos, next, err := client.ListRepositoriesPage(context.Background(), githubapi.RepoFilter{}, req)
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		for _, repo := range repos {
+			names = append(names, repo.Name)
+		}
+		if next == "" {
+			break
+		}
+
+		req.Cursor, err = githubapi.DecodeCursor(next)
+		if err != nil {
+			t.Fatalf("expected a valid cursor, got %v", err)
+		}
+	}
+
+	if len(names) != 250 {
+		t.Fatalf("expected 250 repos, got %d", len(names))
+	}
+	for i, name := range names {
+		if name != fmt.Sprintf("repo%03d", i) {
+			t.Fatalf("expected repo%03d at position %d, got %s", i, i, name)
+		}
+	}
+}
+
+func TestClient_ListRepositoriesPage_Sorted(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{Repos: makeRepos(5)}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	filter := githubapi.RepoFilter{Sort: "name", Direction: "desc"}
+
+	repos, next, err := client.ListRepositoriesPage(context.Background(), filter, githubapi.PageRequest{Size: 3})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repos) != 3 || repos[0].Name != "repo004" {
+		t.Fatalf("unexpected first page: %+v", repos)
+	}
+
+	cursor, err := githubapi.DecodeCursor(next)
+	if err != nil {
+		t.Fatalf("expected a valid cursor, got %v", err)
+	}
+
+	repos, next, err = client.ListRepositoriesPage(context.Background(), filter, githubapi.PageRequest{Size: 3, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repos) != 2 || repos[1].Name != "repo000" || next != "" {
+		t.Fatalf("unexpected last page: %+v, next %q", repos, next)
+	}
+
+	// The cursor belongs to the name sort, so it can't be used without it
+	_, _, err = client.ListRepositoriesPage(context.Background(), githubapi.RepoFilter{}, githubapi.PageRequest{Size: 3, Cursor: cursor})
+	if err != githubapi.ErrInvalidCursor {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestClient_ListPullRequestsPage(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{PullRequests: []*github.PullRequest{
+		{Title: github.String("PR 1")},
+		{Title: github.String("PR 2")},
+		{Title: github.String("PR 3")},
+	}}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	prs, next, err := client.ListPullRequestsPage(context.Background(), "test_repo", githubapi.PullRequestFilter{}, githubapi.PageRequest{Size: 2})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 2 || next == "" {
+		t.Fatalf("expected 2 pull requests and a cursor, got %d and %q", len(prs), next)
+	}
+
+	cursor, _ := githubapi.DecodeCursor(next)
+	prs, next, err = client.ListPullRequestsPage(context.Background(), "test_repo", githubapi.PullRequestFilter{}, githubapi.PageRequest{Size: 2, Cursor: cursor})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 1 || *prs[0].Title != "PR 3" || next != "" {
+		t.Fatalf("unexpected last page: %+v, next %q", prs, next)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	for _, value := range []string{"not base64!", "bm90IGpzb24"} {
+		if _, err := githubapi.DecodeCursor(value); err == nil {
+			t.Errorf("expected an error for %q, got nil", value)
+		}
+	}
+}
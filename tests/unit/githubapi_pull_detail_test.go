@@ -0,0 +1,127 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func newPullDetailMock() *mocks.MockGitHubClient {
+	return &mocks.MockGitHubClient{
+		PullRequests: []*github.PullRequest{{
+			Number:         github.Int(5),
+			Title:          github.String("Add feature"),
+			Head:           &github.PullRequestBranch{Ref: github.String("feature"), SHA: github.String("abc123")},
+			Comments:       github.Int(2),
+			ReviewComments: github.Int(4),
+			Additions:      github.Int(10),
+		}},
+		Reviews: map[int][]*github.PullRequestReview{5: {
+			{User: &github.User{Login: github.String("bob")}, State: github.String("CHANGES_REQUESTED")},
+			{User: &github.User{Login: github.String("alice")}, State: github.String("COMMENTED")},
+			{User: &github.User{Login: github.String("bob")}, State: github.String("APPROVED")},
+			{User: &github.User{Login: github.String("bob")}, State: github.String("COMMENTED")},
+		}},
+		CombinedStatuses: map[string]*github.CombinedStatus{"abc123": {
+			State:    github.String("success"),
+			Statuses: []*github.RepoStatus{{Context: github.String("ci/build"), State: github.String("success")}},
+		}},
+		CheckRuns: map[string][]*github.CheckRun{"abc123": {
+			{Name: github.String("test"), Status: github.String("completed"), Conclusion: github.String("success")},
+		}},
+		PullRequestFiles: map[int][]*github.CommitFile{5: {
+			{Filename: github.String("main.go"), Additions: github.Int(10), Deletions: github.Int(1)},
+		}},
+	}
+}
+
+func TestClient_GetPullRequestDetail(t *testing.T) {
+	client := githubapi.NewTestClient(newPullDetailMock(), "test_owner")
+
+	detail, err := client.GetPullRequestDetail(context.Background(), "test_repo", 5)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if detail.Title != "Add feature" || detail.Comments != 2 || detail.ReviewComments != 4 || detail.Additions != 10 {
+		t.Errorf("unexpected pull request fields: %+v", detail)
+	}
+
+	// A later comment doesn't override bob's approval
+	if len(detail.Reviews) != 2 || detail.Reviews[0].Reviewer != "alice" || detail.Reviews[1].State != "APPROVED" {
+		t.Errorf("unexpected reviews: %+v", detail.Reviews)
+	}
+	if detail.Status.State != "success" || len(detail.Status.Contexts) != 1 {
+		t.Errorf("unexpected status: %+v", detail.Status)
+	}
+	if len(detail.CheckRuns) != 1 || detail.CheckRuns[0].Conclusion != "success" {
+		t.Errorf("unexpected check runs: %+v", detail.CheckRuns)
+	}
+	if len(detail.Files) != 1 || detail.Files[0].Filename != "main.go" || detail.Files[0].Deletions != 1 {
+		t.Errorf("unexpected files: %+v", detail.Files)
+	}
+}
+
+func TestClient_GetPullRequestDetail_Errors(t *testing.T) {
+	mockClient := newPullDetailMock()
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	if _, err := client.GetPullRequestDetail(context.Background(), "test_repo", 0); err != githubapi.ErrInvalidPullNumber {
+		t.Errorf("expected ErrInvalidPullNumber, got %v", err)
+	}
+	if _, err := client.GetPullRequestDetail(context.Background(), "test_repo", 99); !errors.Is(err, githubapi.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	boom := errors.New("check runs unavailable")
+	mockClient.MethodErrs = map[string]error{"ListCheckRunsForOwner": boom}
+	if _, err := client.GetPullRequestDetail(context.Background(), "test_repo", 5); err != boom {
+		t.Errorf("expected the check runs error, got %v", err)
+	}
+}
+
+// slowMock counts how many detail requests run at the same time
+type slowMock struct {
+	*mocks.MockGitHubClient
+	running, peak atomic.Int32
+}
+
+func (m *slowMock) track() {
+	n := m.running.Add(1)
+	for {
+		peak := m.peak.Load()
+		if n <= peak || m.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	m.running.Add(-1)
+}
+
+func (m *slowMock) ListReviewsForOwner(ctx context.Context, owner, repoName string, number int) ([]*github.PullRequestReview, error) {
+	m.track()
+	return m.MockGitHubClient.ListReviewsForOwner(ctx, owner, repoName, number)
+}
+
+func (m *slowMock) ListCheckRunsForOwner(ctx context.Context, owner, repoName, ref string) ([]*github.CheckRun, error) {
+	m.track()
+	return m.MockGitHubClient.ListCheckRunsForOwner(ctx, owner, repoName, ref)
+}
+
+func TestClient_GetPullRequestDetail_FansOut(t *testing.T) {
+	mockClient := &slowMock{MockGitHubClient: newPullDetailMock()}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	if _, err := client.GetPullRequestDetail(context.Background(), "test_repo", 5); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mockClient.peak.Load() != 2 {
+		t.Errorf("expected reviews and check runs to be fetched concurrently, peak was %d", mockClient.peak.Load())
+	}
+}
@@ -0,0 +1,242 @@
+package githubapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+func TestParsePullRequestFilter(t *testing.T) {
+	query := url.Values{
+		"state":          {"Merged"},
+		"draft":          {"false"},
+		"created_after":  {"2024-01-02"},
+		"updated_before": {"2024-03-01T10:00:00Z"},
+		"sort":           {"updated"},
+	}
+
+	filter, err := githubapi.ParsePullRequestFilter(query)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if filter.State != "merged" || filter.Draft == nil || *filter.Draft || filter.Sort != "updated" {
+		t.Errorf("unexpected filter: %+v", filter)
+	}
+	if filter.CreatedAfter == nil || !filter.CreatedAfter.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected created_after: %v", filter.CreatedAfter)
+	}
+	if filter.UpdatedBefore == nil || filter.UpdatedBefore.Hour() != 10 {
+		t.Errorf("unexpected updated_before: %v", filter.UpdatedBefore)
+	}
+}
+
+func TestParsePullRequestFilter_DatesAreInclusive(t *testing.T) {
+	query := url.Values{"state": {"all"}, "created_after": {"2024-02-01"}, "created_before": {"2024-02-01"}}
+	filter, err := githubapi.ParsePullRequestFilter(query)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Both bounds on the same day keep the whole day
+	for _, hour := range []int{0, 10, 23} {
+		created := time.Date(2024, 2, 1, hour, 59, 59, 0, time.UTC)
+		if !filter.Matches(&github.PullRequest{State: github.String("open"), CreatedAt: &github.Timestamp{Time: created}}) {
+			t.Errorf("expected a PR created at %v to be kept", created)
+		}
+	}
+	nextDay := time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC)
+	if filter.Matches(&github.PullRequest{State: github.String("open"), CreatedAt: &github.Timestamp{Time: nextDay}}) {
+		t.Error("expected a PR created the next day to be dropped")
+	}
+}
+
+func TestParsePullRequestFilter_Invalid(t *testing.T) {
+	tests := map[string]error{
+		"state=draft":             githubapi.ErrInvalidState,
+		"draft=maybe":             githubapi.ErrInvalidBool,
+		"created_after=yesterday": githubapi.ErrInvalidDate,
+		"sort=name":               githubapi.ErrInvalidSort,
+		"direction=up":            githubapi.ErrInvalidDirection,
+	}
+	for raw, expected := range tests {
+		query, _ := url.ParseQuery(raw)
+		if _, err := githubapi.ParsePullRequestFilter(query); err != expected {
+			t.Errorf("%s: expected %v, got %v", raw, expected, err)
+		}
+	}
+}
+
+func TestPullRequestFilter_Matches(t *testing.T) {
+	created := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	pr := &github.PullRequest{
+		State:     github.String("closed"),
+		MergedAt:  &github.Timestamp{Time: created.Add(time.Hour)},
+		Draft:     github.Bool(false),
+		User:      &github.User{Login: github.String("Octocat")},
+		Labels:    []*github.Label{{Name: github.String("bug")}},
+		Base:      &github.PullRequestBranch{Ref: github.String("main")},
+		Head:      &github.PullRequestBranch{Ref: github.String("fix"), Label: github.String("octocat:fix")},
+		CreatedAt: &github.Timestamp{Time: created},
+	}
+
+	after := created.Add(-time.Hour)
+	before := created.Add(-time.Minute)
+	yes, no := true, false
+
+	tests := []struct {
+		name     string
+		filter   githubapi.PullRequestFilter
+		expected bool
+	}{
+		{"open by default", githubapi.PullRequestFilter{}, false},
+		{"closed", githubapi.PullRequestFilter{State: "closed"}, true},
+		{"merged", githubapi.PullRequestFilter{State: "merged"}, true},
+		{"all", githubapi.PullRequestFilter{State: "all"}, true},
+		{"author is case insensitive", githubapi.PullRequestFilter{State: "all", Author: "octocat"}, true},
+		{"other author", githubapi.PullRequestFilter{State: "all", Author: "someone"}, false},
+		{"label", githubapi.PullRequestFilter{State: "all", Label: "BUG"}, true},
+		{"missing label", githubapi.PullRequestFilter{State: "all", Label: "docs"}, false},
+		{"base", githubapi.PullRequestFilter{State: "all", Base: "develop"}, false},
+		{"head branch", githubapi.PullRequestFilter{State: "all", Head: "fix"}, true},
+		{"head label", githubapi.PullRequestFilter{State: "all", Head: "octocat:fix"}, true},
+		{"not a draft", githubapi.PullRequestFilter{State: "all", Draft: &no}, true},
+		{"draft", githubapi.PullRequestFilter{State: "all", Draft: &yes}, false},
+		{"created after", githubapi.PullRequestFilter{State: "all", CreatedAfter: &after}, true},
+		{"created before", githubapi.PullRequestFilter{State: "all", CreatedBefore: &before}, false},
+		{"no updated time", githubapi.PullRequestFilter{State: "all", UpdatedAfter: &after}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(pr); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRealClient_ListPullRequests_FiltersAndStopsEarly(t *testing.T) {
+	pagesFetched := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != "closed" || query.Get("head") != "my-org:fix" || query.Get("base") != "main" {
+			t.Errorf("expected state, head and base to be sent to GitHub, got %s", r.URL.RawQuery)
+		}
+
+		pagesFetched++
+		branches := `"base": {"ref": "main"}, "head": {"ref": "fix", "label": "my-org:fix"}`
+		if query.Get("page") == "2" {
+			fmt.Fprint(w, `[{"number": 3, "state": "closed", "merged_at": "2024-01-01T00:00:00Z", `+branches+`}]`)
+			return
+		}
+		w.Header().Set("Link", `<http://`+r.Host+`/repos/my-org/repo1/pulls?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"number": 1, "state": "closed", `+branches+`}, {"number": 2, "state": "closed", "merged_at": "2024-01-01T00:00:00Z", `+branches+`}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	filter := githubapi.PullRequestFilter{State: "merged", Head: "fix", Base: "main"}
+
+	prs, err := client.ListPullRequestsForOwner(context.Background(), "my-org", "repo1", filter, 1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 1 || prs[0].GetNumber() != 2 {
+		t.Fatalf("expected only the merged PR 2, got %+v", prs)
+	}
+	if pagesFetched != 1 {
+		t.Errorf("expected n to stop after the first page, fetched %d", pagesFetched)
+	}
+}
+
+func TestRealClient_ListPullRequests_ZeroLimit(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no request for n=0")
+		fmt.Fprint(w, `[{"number": 1}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	prs, err := client.ListPullRequestsForOwner(context.Background(), "my-org", "repo1", githubapi.PullRequestFilter{}, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if prs == nil || len(prs) != 0 {
+		t.Errorf("expected an empty list, got %+v", prs)
+	}
+}
+
+func TestRealClient_ListPullRequests_StopsPastCreatedAfter(t *testing.T) {
+	pagesFetched := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls", func(w http.ResponseWriter, r *http.Request) {
+		pagesFetched++
+		w.Header().Set("Link", `<http://`+r.Host+`/repos/my-org/repo1/pulls?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"number": 2, "created_at": "2024-03-01T00:00:00Z"}, {"number": 1, "created_at": "2023-12-01T00:00:00Z"}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	prs, err := client.ListPullRequestsForOwner(context.Background(), "my-org", "repo1", githubapi.PullRequestFilter{CreatedAfter: &after}, -1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 1 || prs[0].GetNumber() != 2 {
+		t.Fatalf("expected only PR 2, got %+v", prs)
+	}
+	if pagesFetched != 1 {
+		t.Errorf("expected the listing to stop once past created_after, fetched %d pages", pagesFetched)
+	}
+}
+
+func TestRealClient_ListPullRequests_StopsPastUpdatedBeforeAscending(t *testing.T) {
+	pagesFetched := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls", func(w http.ResponseWriter, r *http.Request) {
+		pagesFetched++
+		if r.URL.Query().Get("sort") != "updated" || r.URL.Query().Get("direction") != "asc" {
+			t.Errorf("expected an oldest update first listing, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Link", `<http://`+r.Host+`/repos/my-org/repo1/pulls?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"number": 1, "updated_at": "2023-12-01T00:00:00Z"}, {"number": 2, "updated_at": "2024-03-01T00:00:00Z"}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := githubapi.PullRequestFilter{Sort: "updated", Direction: "asc", UpdatedBefore: &before}
+
+	prs, err := client.ListPullRequestsForOwner(context.Background(), "my-org", "repo1", filter, -1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 1 || prs[0].GetNumber() != 1 {
+		t.Fatalf("expected only PR 1, got %+v", prs)
+	}
+	if pagesFetched != 1 {
+		t.Errorf("expected the listing to stop once past updated_before, fetched %d pages", pagesFetched)
+	}
+}
+
+func TestRealClient_ListPullRequests_SortUpdatedDefaultsToAscending(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls", func(w http.ResponseWriter, r *http.Request) {
+		// GitHub lists oldest update first when sorting by update without a direction
+		fmt.Fprint(w, `[{"number": 1, "updated_at": "2023-12-01T00:00:00Z"}, {"number": 2, "updated_at": "2024-03-01T00:00:00Z"}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := githubapi.PullRequestFilter{Sort: "updated", UpdatedAfter: &after}
+
+	prs, err := client.ListPullRequestsForOwner(context.Background(), "my-org", "repo1", filter, -1)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(prs) != 1 || prs[0].GetNumber() != 2 {
+		t.Fatalf("expected PR 2, got %+v", prs)
+	}
+}
@@ -0,0 +1,67 @@
+package githubapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+func TestNewPullRequest(t *testing.T) {
+	created := time.Now().Add(-72 * time.Hour)
+	pr := &github.PullRequest{
+		Number:             github.Int(7),
+		Title:              github.String("Fix the thing"),
+		User:               &github.User{Login: github.String("octocat")},
+		State:              github.String("closed"),
+		MergedAt:           &github.Timestamp{Time: time.Now()},
+		Labels:             []*github.Label{{Name: github.String("bug")}},
+		RequestedReviewers: []*github.User{{Login: github.String("reviewer")}},
+		RequestedTeams:     []*github.Team{{Slug: github.String("core")}},
+		Base:               &github.PullRequestBranch{Ref: github.String("main")},
+		Head:               &github.PullRequestBranch{Ref: github.String("fix")},
+		MergeableState:     github.String("clean"),
+		CreatedAt:          &github.Timestamp{Time: created},
+		HTMLURL:            github.String("https://github.com/o/r/pull/7"),
+	}
+
+	converted := githubapi.NewPullRequest(pr)
+
+	if converted.Number != 7 || converted.Title != "Fix the thing" || converted.Author != "octocat" {
+		t.Errorf("unexpected pull request: %+v", converted)
+	}
+	if converted.State != "merged" {
+		t.Errorf("expected a merged PR to have state merged, got %q", converted.State)
+	}
+	if len(converted.Labels) != 1 || converted.Labels[0] != "bug" {
+		t.Errorf("unexpected labels: %v", converted.Labels)
+	}
+	if len(converted.Reviewers) != 1 || len(converted.TeamReviewers) != 1 || converted.TeamReviewers[0] != "core" {
+		t.Errorf("unexpected reviewers: %v %v", converted.Reviewers, converted.TeamReviewers)
+	}
+	if converted.Base != "main" || converted.Head != "fix" || converted.MergeableState != "clean" {
+		t.Errorf("unexpected branches or mergeable state: %+v", converted)
+	}
+	if converted.AgeDays != 3 {
+		t.Errorf("expected an age of 3 days, got %d", converted.AgeDays)
+	}
+}
+
+func TestNewPullRequest_EmptyLists(t *testing.T) {
+	converted := githubapi.NewPullRequest(&github.PullRequest{})
+
+	// Empty lists encode as [] rather than null
+	if converted.Labels == nil || converted.Reviewers == nil || converted.TeamReviewers == nil {
+		t.Errorf("expected empty lists, got %+v", converted)
+	}
+}
+
+func TestParsePullRequestView(t *testing.T) {
+	if view, err := githubapi.ParsePullRequestView(""); err != nil || view != githubapi.ViewSummary {
+		t.Errorf("expected the summary view by default, got %q (%v)", view, err)
+	}
+	if _, err := githubapi.ParsePullRequestView("raw"); err != githubapi.ErrInvalidView {
+		t.Errorf("expected ErrInvalidView, got %v", err)
+	}
+}
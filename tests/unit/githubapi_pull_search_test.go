@@ -0,0 +1,198 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestRealClient_SearchPullRequests_Query(t *testing.T) {
+	var queries []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q"))
+		if r.URL.Query().Get("sort") != "updated" || r.URL.Query().Get("order") != "desc" {
+			t.Errorf("expected results by update time, got %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"total_count": 1, "incomplete_results": false, "items": [
+			{"number": 3, "repository_url": "https://api.github.com/repos/my-org/api", "pull_request": {"merged_at": "2024-02-01T00:00:00Z"}}
+		]}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	filter, err := githubapi.ParsePullRequestFilter(url.Values{
+		"state":         {"merged"},
+		"head":          {"my-org:feature"},
+		"label":         {"needs review"},
+		"draft":         {"false"},
+		"updated_after": {"2024-01-01"},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	issues, err := client.SearchPullRequestsForOwner(context.Background(), "my-org", filter)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(issues))
+	}
+
+	expected := `is:pr user:my-org is:merged head:feature label:"needs review" draft:false updated:>=2024-01-01T00:00:00Z`
+	if len(queries) != 1 || queries[0] != expected {
+		t.Errorf("expected query %q, got %q", expected, queries)
+	}
+}
+
+func TestRealClient_SearchPullRequests_Unsupported(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected no search for a head in a fork")
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	_, err := client.SearchPullRequestsForOwner(context.Background(), "my-org", githubapi.PullRequestFilter{Head: "someone:feature"})
+	if err != githubapi.ErrSearchUnsupported {
+		t.Errorf("expected ErrSearchUnsupported, got %v", err)
+	}
+}
+
+func TestRealClient_SearchPullRequests_Incomplete(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search/issues", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 1500, "incomplete_results": false, "items": []}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	_, err := client.SearchPullRequestsForOwner(context.Background(), "my-org", githubapi.PullRequestFilter{})
+	if err != githubapi.ErrSearchIncomplete {
+		t.Errorf("expected ErrSearchIncomplete past the search limit, got %v", err)
+	}
+}
+
+func ownerPullsMock() *mocks.MockGitHubClient {
+	at := func(days int) *github.Timestamp { return &github.Timestamp{Time: time.Now().AddDate(0, 0, -days)} }
+	return &mocks.MockGitHubClient{
+		Repos: []*github.Repository{
+			{Name: github.String("api"), Language: github.String("Go")},
+			{Name: github.String("web"), Language: github.String("TypeScript")},
+		},
+		RepoPullRequests: map[string][]*github.PullRequest{
+			"api": {
+				{Number: github.Int(1), State: github.String("open"), UpdatedAt: at(5)},
+				{Number: github.Int(2), State: github.String("open"), UpdatedAt: at(1)},
+			},
+			"web": {
+				{Number: github.Int(3), State: github.String("open"), UpdatedAt: at(3)},
+				{Number: github.Int(4), State: github.String("closed"), UpdatedAt: at(0)},
+			},
+		},
+	}
+}
+
+func TestListOwnerPullRequests_Search(t *testing.T) {
+	client := githubapi.NewTestClient(ownerPullsMock(), "my-org")
+
+	prs, searched, err := client.ListOwnerPullRequests(context.Background(), githubapi.RepoFilter{}, githubapi.PullRequestFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !searched {
+		t.Error("expected every repo to be searched at once")
+	}
+
+	var order []string
+	for _, pr := range prs {
+		order = append(order, fmt.Sprintf("%s#%d", pr.Repository, pr.Number))
+	}
+	if fmt.Sprint(order) != "[api#2 web#3 api#1]" {
+		t.Errorf("expected open PRs most recently updated first, got %v", order)
+	}
+
+	counts := githubapi.CountByRepository(prs)
+	if counts["api"] != 2 || counts["web"] != 1 {
+		t.Errorf("unexpected counts %v", counts)
+	}
+}
+
+func TestListOwnerPullRequests_FallsBackToListing(t *testing.T) {
+	mock := ownerPullsMock()
+	mock.MethodErrs = map[string]error{"SearchPullRequestsForOwner": githubapi.ErrSearchIncomplete}
+	client := githubapi.NewTestClient(mock, "my-org")
+
+	prs, searched, err := client.ListOwnerPullRequests(context.Background(), githubapi.RepoFilter{}, githubapi.PullRequestFilter{Direction: "asc"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if searched || len(prs) != 3 || prs[0].Number != 1 || prs[2].Number != 2 {
+		t.Errorf("expected the 3 open PRs listed oldest update first, got %+v", prs)
+	}
+
+	// Repo filters can't be searched, only the selected repos are listed
+	prs, searched, err = client.ListOwnerPullRequests(context.Background(), githubapi.RepoFilter{NameRegex: regexp.MustCompile("^web$")}, githubapi.PullRequestFilter{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if searched || len(prs) != 1 || prs[0].Repository != "web" {
+		t.Errorf("expected only web's open PR, got %+v", prs)
+	}
+}
+
+func TestListOwnerPullRequests_SearchErrors(t *testing.T) {
+	for _, searchErr := range []error{githubapi.ErrRateLimited, githubapi.ErrForbidden} {
+		mock := ownerPullsMock()
+		mock.MethodErrs = map[string]error{"SearchPullRequestsForOwner": searchErr}
+		client := githubapi.NewTestClient(mock, "my-org")
+
+		if _, _, err := client.ListOwnerPullRequests(context.Background(), githubapi.RepoFilter{}, githubapi.PullRequestFilter{}); !errors.Is(err, searchErr) {
+			t.Errorf("expected %v instead of listing every repository, got %v", searchErr, err)
+		}
+	}
+}
+
+func TestListOwnerPullRequests_OnlySortedByUpdated(t *testing.T) {
+	client := githubapi.NewTestClient(ownerPullsMock(), "my-org")
+
+	if _, _, err := client.ListOwnerPullRequests(context.Background(), githubapi.RepoFilter{}, githubapi.PullRequestFilter{Sort: "created"}); err != githubapi.ErrOwnerPullsSort {
+		t.Errorf("expected ErrOwnerPullsSort, got %v", err)
+	}
+}
+
+func TestListOwnerPullRequests_ListsMostRecentlyUpdatedFirst(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/my-org", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "my-org", "type": "Organization"}`)
+	})
+	mux.HandleFunc("/orgs/my-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "api"}]`)
+	})
+	mux.HandleFunc("/repos/my-org/api/pulls", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sort") != "updated" || r.URL.Query().Get("direction") != "desc" {
+			t.Errorf("expected a most recently updated first listing, got %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `[{"number": 2, "updated_at": "2024-03-01T00:00:00Z"}, {"number": 1, "updated_at": "2023-12-01T00:00:00Z"}]`)
+	})
+
+	client := githubapi.NewTestClient(githubapi.NewRealGitHubClient(newTestGitHub(t, mux)), "my-org")
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	prs, searched, err := client.ListOwnerPullRequests(context.Background(), githubapi.RepoFilter{NamePrefix: "a"}, githubapi.PullRequestFilter{UpdatedAfter: &after})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if searched || len(prs) != 1 || prs[0].Number != 2 {
+		t.Errorf("expected PR 2 to be listed, got %+v", prs)
+	}
+}
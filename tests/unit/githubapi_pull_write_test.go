@@ -0,0 +1,183 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestCreatePullRequestOptions_Validate(t *testing.T) {
+	tests := []struct {
+		opts     githubapi.CreatePullRequestOptions
+		expected error
+	}{
+		{githubapi.CreatePullRequestOptions{Title: "Fix", Head: "fix", Base: "main"}, nil},
+		{githubapi.CreatePullRequestOptions{Title: " ", Head: "fix", Base: "main"}, githubapi.ErrMissingPullFields},
+		{githubapi.CreatePullRequestOptions{Title: "Fix", Base: "main"}, githubapi.ErrMissingPullFields},
+		{githubapi.CreatePullRequestOptions{Title: "Fix", Head: "main", Base: "main"}, githubapi.ErrSameHeadBase},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); err != tt.expected {
+			t.Errorf("%+v: expected %v, got %v", tt.opts, tt.expected, err)
+		}
+	}
+}
+
+func TestUpdatePullRequestOptions_Validate(t *testing.T) {
+	empty := ""
+	merged := "merged"
+	tests := []struct {
+		opts     githubapi.UpdatePullRequestOptions
+		expected error
+	}{
+		{githubapi.UpdatePullRequestOptions{}, githubapi.ErrNothingToUpdate},
+		{githubapi.UpdatePullRequestOptions{Title: &empty}, githubapi.ErrEmptyTitle},
+		{githubapi.UpdatePullRequestOptions{State: &merged}, githubapi.ErrInvalidState},
+		{githubapi.UpdatePullRequestOptions{Labels: &[]string{}}, nil},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); err != tt.expected {
+			t.Errorf("%+v: expected %v, got %v", tt.opts, tt.expected, err)
+		}
+	}
+}
+
+func TestMergePullRequestOptions_Validate(t *testing.T) {
+	if err := (githubapi.MergePullRequestOptions{Method: "fast-forward"}).Validate(); err != githubapi.ErrInvalidMergeMethod {
+		t.Errorf("expected ErrInvalidMergeMethod, got %v", err)
+	}
+	if err := (githubapi.MergePullRequestOptions{SHA: "abc123"}).Validate(); err != githubapi.ErrInvalidSHA {
+		t.Errorf("expected ErrInvalidSHA, got %v", err)
+	}
+	if err := (githubapi.MergePullRequestOptions{Method: "squash", SHA: strings.Repeat("a", 40)}).Validate(); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+}
+
+func TestClient_PullRequestLifecycle(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	ctx := context.Background()
+
+	pr, err := client.CreatePullRequest(ctx, "test_repo", githubapi.CreatePullRequestOptions{Title: "Fix", Head: "fix", Base: "main"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	number := pr.GetNumber()
+
+	if pr, err = client.ClosePullRequest(ctx, "test_repo", number); err != nil || pr.GetState() != "closed" {
+		t.Fatalf("expected the PR to be closed, got %q (%v)", pr.GetState(), err)
+	}
+	if _, err := client.MergePullRequest(ctx, "test_repo", number, githubapi.MergePullRequestOptions{}); !errors.Is(err, githubapi.ErrConflict) {
+		t.Errorf("expected a closed PR not to merge, got %v", err)
+	}
+	if pr, err = client.ReopenPullRequest(ctx, "test_repo", number); err != nil || pr.GetState() != "open" {
+		t.Fatalf("expected the PR to be reopened, got %q (%v)", pr.GetState(), err)
+	}
+
+	result, err := client.MergePullRequest(ctx, "test_repo", number, githubapi.MergePullRequestOptions{Method: "squash", SHA: pr.GetHead().GetSHA()})
+	if err != nil || !result.GetMerged() {
+		t.Errorf("expected the PR to be merged, got %+v (%v)", result, err)
+	}
+}
+
+func TestRealClient_UpdatePullRequest(t *testing.T) {
+	var edited map[string]interface{}
+	var labels []string
+	var graphQuery string
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls/4", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			json.NewDecoder(r.Body).Decode(&edited)
+		}
+		fmt.Fprint(w, `{"number": 4, "node_id": "PR_node", "draft": false, "title": "New title"}`)
+	})
+	mux.HandleFunc("/repos/my-org/repo1/issues/4/labels", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&labels)
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string            `json:"query"`
+			Variables map[string]string `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Variables["id"] != "PR_node" {
+			t.Errorf("expected the PR node id, got %v", body.Variables)
+		}
+		graphQuery = body.Query
+		fmt.Fprint(w, `{"data": {}}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	opts := githubapi.UpdatePullRequestOptions{
+		Title:  github.String("New title"),
+		Draft:  github.Bool(true),
+		Labels: &[]string{"bug"},
+	}
+
+	pr, err := client.UpdatePullRequestForOwner(context.Background(), "my-org", "repo1", 4, opts)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if pr.GetTitle() != "New title" {
+		t.Errorf("unexpected pull request: %+v", pr)
+	}
+	if edited["title"] != "New title" {
+		t.Errorf("expected the title to be edited, got %v", edited)
+	}
+	if len(labels) != 1 || labels[0] != "bug" {
+		t.Errorf("expected the labels to be replaced, got %v", labels)
+	}
+	if !strings.Contains(graphQuery, "convertPullRequestToDraft") {
+		t.Errorf("expected the PR to be converted to a draft, got query %q", graphQuery)
+	}
+}
+
+func TestRealClient_MergePullRequest_NotMergeable(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/pulls/4/merge", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		fmt.Fprint(w, `{"message": "Pull Request is not mergeable"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	_, err := client.MergePullRequestForOwner(context.Background(), "my-org", "repo1", 4, githubapi.MergePullRequestOptions{})
+	if !errors.Is(err, githubapi.ErrConflict) {
+		t.Errorf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestRealClient_UpdatePullRequest_DraftOnEnterprise(t *testing.T) {
+	var graphQLRequests int
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/my-org/repo1/pulls/4", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"number": 4, "node_id": "PR_node", "draft": true}`)
+	})
+	mux.HandleFunc("/api/graphql", func(w http.ResponseWriter, r *http.Request) {
+		graphQLRequests++
+		fmt.Fprint(w, `{"data": {}}`)
+	})
+
+	gh := newTestGitHub(t, mux)
+	gh.BaseURL = gh.BaseURL.JoinPath("api/v3/")
+	client := githubapi.NewRealGitHubClient(gh)
+
+	if _, err := client.UpdatePullRequestForOwner(context.Background(), "my-org", "repo1", 4, githubapi.UpdatePullRequestOptions{Draft: github.Bool(false)}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if graphQLRequests != 1 {
+		t.Errorf("expected GitHub Enterprise's GraphQL endpoint to be used, got %d requests", graphQLRequests)
+	}
+}
@@ -0,0 +1,208 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+func rateLimitedClient(maxRetries int) (*http.Client, *githubapi.RateLimiter) {
+	limiter := githubapi.NewRateLimiter(maxRetries, time.Millisecond)
+	return &http.Client{Transport: limiter.Transport(nil)}, limiter
+}
+
+func TestRateLimiter_RetriesServerErrors(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, _ := rateLimitedClient(githubapi.DefaultMaxRetries)
+	resp, err := client.Get(server.URL + "/repos/octo/repo1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || requests.Load() != 3 {
+		t.Errorf("expected a 200 after 3 requests, got %d after %d", resp.StatusCode, requests.Load())
+	}
+
+	// Retries stop after maxRetries, with GitHub's last answer
+	requests.Store(-10)
+	resp, err = client.Get(server.URL + "/repos/octo/repo1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || requests.Load() != -6 {
+		t.Errorf("expected a 502 after 4 requests, got %d after %d", resp.StatusCode, requests.Load()+10)
+	}
+}
+
+func TestRateLimiter_DoesNotRetryPosts(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, _ := rateLimitedClient(githubapi.DefaultMaxRetries)
+	resp, err := client.Post(server.URL+"/orgs/octo/repos", "application/json", strings.NewReader(`{"name":"repo1"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if requests.Load() != 1 {
+		t.Errorf("expected a single request, got %d", requests.Load())
+	}
+}
+
+func TestRateLimiter_RetriesSecondaryRateLimits(t *testing.T) {
+	var requests atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, r.ContentLength)
+		r.Body.Read(body)
+		bodies = append(bodies, string(body))
+
+		switch requests.Add(1) {
+		case 1:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit"}`)
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "4000")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You have exceeded a secondary rate limit. Please wait a few minutes"}`)
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := rateLimitedClient(githubapi.DefaultMaxRetries)
+	resp, err := client.Post(server.URL+"/orgs/octo/repos", "application/json", strings.NewReader(`{"name":"repo1"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || requests.Load() != 3 {
+		t.Fatalf("expected a 201 after 3 requests, got %d after %d", resp.StatusCode, requests.Load())
+	}
+	for _, body := range bodies {
+		if body != `{"name":"repo1"}` {
+			t.Errorf("expected every attempt to send the body, got %q", body)
+		}
+	}
+}
+
+func TestRateLimiter_ExhaustedQuota(t *testing.T) {
+	reset := time.Now().Add(30 * time.Minute)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message":"API rate limit exceeded"}`)
+	}))
+	defer server.Close()
+
+	client, limiter := rateLimitedClient(githubapi.DefaultMaxRetries)
+	if limiter.RetryAfter() != 0 {
+		t.Fatalf("expected no wait before any request, got %v", limiter.RetryAfter())
+	}
+
+	// The quota doesn't come back before the reset, so it isn't retried
+	resp, err := client.Get(server.URL + "/user/repos")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden || requests.Load() != 1 {
+		t.Errorf("expected a single 403, got %d after %d requests", resp.StatusCode, requests.Load())
+	}
+
+	wait := limiter.RetryAfter()
+	if wait < 29*time.Minute || wait > 30*time.Minute {
+		t.Errorf("expected to wait until the reset, got %v", wait)
+	}
+}
+
+func TestRateLimiter_ThrottlesNearTheLimit(t *testing.T) {
+	reset := time.Now().Add(2 * time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "1")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		w.Header().Set("X-RateLimit-Resource", "core")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, _ := rateLimitedClient(githubapi.DefaultMaxRetries)
+	resp, err := client.Get(server.URL + "/user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	// A single request left, it waits for half of the time until the reset
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/user", nil)
+	if _, err := client.Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the request to be throttled, got %v", err)
+	}
+
+	// Search has its own quota
+	resp, err = client.Get(server.URL + "/search/issues")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRateLimiter_DoesNotThrottleRateLimitChecks(t *testing.T) {
+	reset := time.Now().Add(time.Minute)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	client, _ := rateLimitedClient(githubapi.DefaultMaxRetries)
+	resp, err := client.Get(server.URL + "/user")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	// The quota is used up, yet checking it answers right away
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/rate_limit", nil)
+	resp, err = client.Do(req)
+	if err != nil {
+		t.Fatalf("expected the rate limit check to go through, got %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,100 @@
+package githubapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+func TestRealClient_CreateRepo_InOrganization(t *testing.T) {
+	var created map[string]interface{}
+	var topicsSet, branchRenamed bool
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/my-org", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "my-org", "type": "Organization"}`)
+	})
+	mux.HandleFunc("/orgs/my-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("expected POST, got %s", r.Method)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&created); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		fmt.Fprint(w, `{"name": "svc", "default_branch": "master", "owner": {"login": "my-org"}}`)
+	})
+	mux.HandleFunc("/repos/my-org/svc/topics", func(w http.ResponseWriter, r *http.Request) {
+		topicsSet = true
+		fmt.Fprint(w, `{"names": ["go"]}`)
+	})
+	mux.HandleFunc("/repos/my-org/svc/branches/master/rename", func(w http.ResponseWriter, r *http.Request) {
+		branchRenamed = true
+		fmt.Fprint(w, `{"name": "main"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	no := false
+	repo, err := client.CreateRepoForOwner(context.Background(), "my-org", githubapi.CreateRepoOptions{
+		Name:             "svc",
+		Visibility:       "internal",
+		Topics:           []string{"go"},
+		AutoInit:         true,
+		DefaultBranch:    "main",
+		AllowMergeCommit: &no,
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if created["visibility"] != "internal" || created["auto_init"] != true || created["allow_merge_commit"] != false {
+		t.Errorf("unexpected create request: %+v", created)
+	}
+	if !topicsSet || !branchRenamed {
+		t.Errorf("expected topics and default branch to be set, got topics=%v branch=%v", topicsSet, branchRenamed)
+	}
+	if repo.GetDefaultBranch() != "main" || len(repo.Topics) != 1 {
+		t.Errorf("unexpected repo: %+v", repo)
+	}
+}
+
+func TestRealClient_CreateRepo_ReturnsRepoWhenSetupFails(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/my-org", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "my-org", "type": "Organization"}`)
+	})
+	mux.HandleFunc("/orgs/my-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "svc", "default_branch": "main", "owner": {"login": "my-org"}}`)
+	})
+	mux.HandleFunc("/repos/my-org/svc/topics", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		fmt.Fprint(w, `{"message": "Validation Failed"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	repo, err := client.CreateRepoForOwner(context.Background(), "my-org", githubapi.CreateRepoOptions{Name: "svc", Topics: []string{"go"}})
+	if err == nil {
+		t.Fatal("expected an error, got nil")
+	}
+	if repo.GetName() != "svc" {
+		t.Errorf("expected the created repo along with the error, got %+v", repo)
+	}
+}
+
+func TestRealClient_CreateRepo_InternalForUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/me", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "me", "type": "User"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	_, err := client.CreateRepoForOwner(context.Background(), "me", githubapi.CreateRepoOptions{Name: "svc", Visibility: "internal"})
+	if err != githubapi.ErrInternalOutsideOrg {
+		t.Fatalf("expected ErrInternalOutsideOrg, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package githubapi_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+)
+
+// newTestGitHub creates a go-github client that talks to a local test server
+func newTestGitHub(t *testing.T, mux *http.ServeMux) *github.Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	gh := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server url: %v", err)
+	}
+	gh.BaseURL = baseURL
+	return gh
+}
+
+func TestRealClient_ListRepos_OrgPagination(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/my-org", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "my-org", "type": "Organization"}`)
+	})
+	mux.HandleFunc("/orgs/my-org/repos", func(w http.ResponseWriter, r *http.Request) {
+		// Serve two pages, the first one pointing to the second through the Link header
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `[{"name": "repo3"}]`)
+			return
+		}
+		w.Header().Set("Link", `<http://`+r.Host+`/orgs/my-org/repos?page=2>; rel="next"`)
+		fmt.Fprint(w, `[{"name": "repo1"}, {"name": "repo2"}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	repos, err := client.ListReposForOwner(context.Background(), "my-org")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repos) != 3 {
+		t.Fatalf("expected 3 repos across both pages, got %d", len(repos))
+	}
+	if repos[2].GetName() != "repo3" {
+		t.Errorf("expected last repo to be %q, got %q", "repo3", repos[2].GetName())
+	}
+}
+
+func TestRealClient_ListRepos_AuthenticatedUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/me", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "me", "type": "User"}`)
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "me", "type": "User"}`)
+	})
+	mux.HandleFunc("/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("affiliation") != "owner" {
+			t.Errorf("expected affiliation=owner, got %q", r.URL.Query().Get("affiliation"))
+		}
+		fmt.Fprint(w, `[{"name": "private-repo", "private": true}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	repos, err := client.ListReposForOwner(context.Background(), "me")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repos) != 1 || repos[0].GetName() != "private-repo" {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+}
+
+func TestRealClient_ListRepos_OtherUser(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/someone", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "someone", "type": "User"}`)
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login": "me", "type": "User"}`)
+	})
+	mux.HandleFunc("/users/someone/repos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"name": "public-repo"}]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	repos, err := client.ListReposForOwner(context.Background(), "someone")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(repos) != 1 || repos[0].GetName() != "public-repo" {
+		t.Errorf("unexpected repos: %+v", repos)
+	}
+}
@@ -0,0 +1,127 @@
+package githubapi_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func mockFilterRepos() []*github.Repository {
+	return []*github.Repository{
+		{
+			Name:      github.String("api-service"),
+			Private:   github.Bool(true),
+			Language:  github.String("Go"),
+			Topics:    []string{"backend"},
+			CreatedAt: &github.Timestamp{Time: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			Name:      github.String("web-app"),
+			Language:  github.String("TypeScript"),
+			Topics:    []string{"frontend"},
+			CreatedAt: &github.Timestamp{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+		{
+			Name:      github.String("api-legacy"),
+			Archived:  github.Bool(true),
+			Fork:      github.Bool(true),
+			Language:  github.String("Go"),
+			CreatedAt: &github.Timestamp{Time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		},
+	}
+}
+
+func repoNames(repos []githubapi.Repository) []string {
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.Name)
+	}
+	return names
+}
+
+func TestClient_ListRepositories_Filters(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected []string
+	}{
+		{"", []string{"api-service", "web-app", "api-legacy"}},
+		{"visibility=private", []string{"api-service"}},
+		{"archived=false&fork=false", []string{"api-service", "web-app"}},
+		{"language=go", []string{"api-service", "api-legacy"}},
+		{"topic=frontend", []string{"web-app"}},
+		{"name_prefix=api-", []string{"api-service", "api-legacy"}},
+		{"name_regex=^web", []string{"web-app"}},
+		{"sort=name", []string{"api-legacy", "api-service", "web-app"}},
+		{"sort=created", []string{"web-app", "api-service", "api-legacy"}},
+		{"sort=created&direction=asc", []string{"api-legacy", "api-service", "web-app"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			filter, err := githubapi.ParseRepoFilter(query)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			mockClient := &mocks.MockGitHubClient{Repos: mockFilterRepos()}
+			client := githubapi.NewTestClient(mockClient, "test_owner")
+
+			repos, err := client.ListRepositories(context.Background(), filter)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			names := repoNames(repos)
+			if len(names) != len(tt.expected) {
+				t.Fatalf("expected %v, got %v", tt.expected, names)
+			}
+			for i := range names {
+				if names[i] != tt.expected[i] {
+					t.Fatalf("expected %v, got %v", tt.expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRepoFilter_Invalid(t *testing.T) {
+	queries := []string{
+		"visibility=secret",
+		"archived=maybe",
+		"name_regex=(",
+		"sort=stars",
+		"direction=up",
+	}
+
+	for _, q := range queries {
+		query, _ := url.ParseQuery(q)
+		if _, err := githubapi.ParseRepoFilter(query); err == nil {
+			t.Errorf("expected an error for %q, got nil", q)
+		}
+	}
+}
+
+func TestSelectFields(t *testing.T) {
+	repos := []githubapi.Repository{githubapi.NewRepository(mockFilterRepos()[0])}
+
+	selected, err := githubapi.SelectFields(repos, []string{"name", " visibility"})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(selected[0]) != 2 {
+		t.Fatalf("expected 2 fields, got %+v", selected[0])
+	}
+	if selected[0]["visibility"] != "private" {
+		t.Errorf("expected visibility 'private', got %v", selected[0]["visibility"])
+	}
+
+	if _, err := githubapi.SelectFields(repos, []string{"owner_secret"}); err == nil {
+		t.Error("expected an error for an unknown field, got nil")
+	}
+}
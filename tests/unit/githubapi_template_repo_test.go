@@ -0,0 +1,133 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestClient_CreateRepoFromTemplate_Success(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	repo, err := client.CreateRepoFromTemplate(context.Background(), githubapi.TemplateRepoOptions{
+		Name:          "new-service",
+		TemplateOwner: "test_owner",
+		TemplateRepo:  "service-template",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repo.GetTemplateRepository().GetFullName() != "test_owner/service-template" {
+		t.Errorf("unexpected template: %q", repo.GetTemplateRepository().GetFullName())
+	}
+}
+
+func TestClient_CreateRepoFromTemplate_Invalid(t *testing.T) {
+	mockClient := &mocks.MockGitHubClient{}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+
+	_, err := client.CreateRepoFromTemplate(context.Background(), githubapi.TemplateRepoOptions{Name: "new-service"})
+	if err != githubapi.ErrMissingTemplate {
+		t.Fatalf("expected ErrMissingTemplate, got %v", err)
+	}
+
+	_, err = client.CreateRepoFromTemplate(context.Background(), githubapi.TemplateRepoOptions{
+		Name:          "new-service",
+		Visibility:    "internal",
+		TemplateOwner: "test_owner",
+		TemplateRepo:  "service-template",
+	})
+	if err != githubapi.ErrTemplateVisibility {
+		t.Fatalf("expected ErrTemplateVisibility, got %v", err)
+	}
+}
+
+func TestRealClient_CreateRepoFromTemplate_WaitsForDefaultBranch(t *testing.T) {
+	branchChecks := 0
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test_owner/service-template/generate", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "new-service"}`)
+	})
+	mux.HandleFunc("/repos/test_owner/new-service", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "new-service", "default_branch": "main"}`)
+	})
+	mux.HandleFunc("/repos/test_owner/new-service/branches/main", func(w http.ResponseWriter, r *http.Request) {
+		// The branch shows up on the second check, like an in-progress generation
+		branchChecks++
+		if branchChecks == 1 {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message": "Branch not found"}`)
+			return
+		}
+		fmt.Fprint(w, `{"name": "main"}`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	repo, err := client.CreateRepoFromTemplateForOwner(context.Background(), "test_owner", githubapi.TemplateRepoOptions{
+		Name:          "new-service",
+		TemplateOwner: "test_owner",
+		TemplateRepo:  "service-template",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if branchChecks != 2 {
+		t.Errorf("expected 2 branch checks, got %d", branchChecks)
+	}
+	if repo.GetDefaultBranch() != "main" {
+		t.Errorf("expected default branch 'main', got %q", repo.GetDefaultBranch())
+	}
+}
+
+func TestRealClient_CreateRepoFromTemplate_NotReady(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test_owner/service-template/generate", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "new-service"}`)
+	})
+	mux.HandleFunc("/repos/test_owner/new-service", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "Not Found"}`)
+	})
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	opts := githubapi.TemplateRepoOptions{Name: "new-service", TemplateOwner: "test_owner", TemplateRepo: "service-template"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	repo, err := client.CreateRepoFromTemplateForOwner(ctx, "test_owner", opts)
+	if !errors.Is(err, githubapi.ErrTemplateNotReady) {
+		t.Fatalf("expected ErrTemplateNotReady, got %v", err)
+	}
+	if repo.GetName() != "new-service" {
+		t.Errorf("expected the generated repo along with the error, got %v", repo)
+	}
+}
+
+func TestRealClient_CreateRepoFromTemplate_PollingErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/test_owner/service-template/generate", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name": "new-service"}`)
+	})
+	mux.HandleFunc("/repos/test_owner/new-service", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"message": "Resource not accessible"}`)
+	})
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+	opts := githubapi.TemplateRepoOptions{Name: "new-service", TemplateOwner: "test_owner", TemplateRepo: "service-template"}
+
+	repo, err := client.CreateRepoFromTemplateForOwner(context.Background(), "test_owner", opts)
+	if !errors.Is(err, githubapi.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if repo.GetName() != "new-service" {
+		t.Errorf("expected the generated repo along with the error, got %v", repo)
+	}
+}
@@ -0,0 +1,508 @@
+package githubapi_test
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/httpcache"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+// etagHandler serves body with an ETag, answering 304 to matching If-None-Match
+type etagHandler struct {
+	mu          sync.Mutex
+	body        string
+	etag        string
+	full        int // 200 responses
+	notModified int // 304 responses
+}
+
+func (h *etagHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	w.Header().Set("ETag", h.etag)
+	if r.Header.Get("If-None-Match") == h.etag {
+		h.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.full++
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	fmt.Fprint(w, h.body)
+}
+
+func (h *etagHandler) counts() (int, int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.full, h.notModified
+}
+
+func cachedGet(t *testing.T, client *http.Client, url string) (string, string) {
+	t.Helper()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read body: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	return string(body), resp.Header.Get(httpcache.CacheHeader)
+}
+
+func TestCache_RevalidatesWithETag(t *testing.T) {
+	handler := &etagHandler{body: `{"name":"repo1"}`, etag: `"v1"`}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cache := httpcache.New(httpcache.NewMemoryStore(10), 0)
+	client := &http.Client{Transport: cache.Transport(nil)}
+
+	body, status := cachedGet(t, client, server.URL+"/repos/octo/repo1")
+	if body != `{"name":"repo1"}` || status != "" {
+		t.Fatalf("unexpected first response %q (cache %q)", body, status)
+	}
+	body, status = cachedGet(t, client, server.URL+"/repos/octo/repo1")
+	if body != `{"name":"repo1"}` || status != "revalidated" {
+		t.Fatalf("unexpected second response %q (cache %q)", body, status)
+	}
+	if full, notModified := handler.counts(); full != 1 || notModified != 1 {
+		t.Errorf("expected 1 full and 1 not modified response, got %d and %d", full, notModified)
+	}
+
+	// A changed resource is stored again
+	handler.mu.Lock()
+	handler.body, handler.etag = `{"name":"renamed"}`, `"v2"`
+	handler.mu.Unlock()
+	if body, _ := cachedGet(t, client, server.URL+"/repos/octo/repo1"); body != `{"name":"renamed"}` {
+		t.Errorf("expected the changed body, got %q", body)
+	}
+}
+
+func TestCache_FreshWithinTTL(t *testing.T) {
+	handler := &etagHandler{body: `[]`, etag: `"v1"`}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cache := httpcache.New(httpcache.NewMemoryStore(10), time.Hour)
+	client := &http.Client{Transport: cache.Transport(nil)}
+
+	cachedGet(t, client, server.URL+"/orgs/octo/repos")
+	if _, status := cachedGet(t, client, server.URL+"/orgs/octo/repos"); status != "hit" {
+		t.Errorf("expected a cache hit, got %q", status)
+	}
+	if full, notModified := handler.counts(); full != 1 || notModified != 0 {
+		t.Errorf("expected a single request to GitHub, got %d and %d", full, notModified)
+	}
+
+	// Other queries are other entries
+	cachedGet(t, client, server.URL+"/orgs/octo/repos?page=2")
+	if full, _ := handler.counts(); full != 2 {
+		t.Errorf("expected page 2 to be fetched, got %d requests", full)
+	}
+}
+
+func TestCache_HonorsCacheControl(t *testing.T) {
+	handler := &etagHandler{body: `{}`, etag: `"v1"`}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rate_limit", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "no-cache")
+		handler.ServeHTTP(w, r)
+	})
+	mux.HandleFunc("/secret", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "private, no-store")
+		handler.ServeHTTP(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := httpcache.NewMemoryStore(10)
+	client := &http.Client{Transport: httpcache.New(store, time.Hour).Transport(nil)}
+
+	cachedGet(t, client, server.URL+"/rate_limit")
+	if _, status := cachedGet(t, client, server.URL+"/rate_limit"); status != "revalidated" {
+		t.Errorf("expected no-cache to be revalidated, got %q", status)
+	}
+	cachedGet(t, client, server.URL+"/secret")
+	if store.Len() != 1 {
+		t.Errorf("expected no-store not to be stored, got %d entries", store.Len())
+	}
+}
+
+func TestCache_SkipsResponsesWithoutETag(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{}`)
+	}))
+	defer server.Close()
+
+	store := httpcache.NewMemoryStore(10)
+	client := &http.Client{Transport: httpcache.New(store, time.Hour).Transport(nil)}
+	cachedGet(t, client, server.URL+"/user")
+	cachedGet(t, client, server.URL+"/user")
+
+	if requests != 2 || store.Len() != 0 {
+		t.Errorf("expected nothing cached, got %d requests and %d entries", requests, store.Len())
+	}
+}
+
+func TestCache_WritesInvalidateRepository(t *testing.T) {
+	handler := &etagHandler{body: `{}`, etag: `"v1"`}
+	mux := http.NewServeMux()
+	mux.Handle("GET /", handler)
+	mux.HandleFunc("PATCH /", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := httpcache.NewMemoryStore(10)
+	client := &http.Client{Transport: httpcache.New(store, time.Hour).Transport(nil)}
+	base := server.URL + "/api/v3"
+	for _, path := range []string{"/repos/octo/repo1", "/repos/octo/repo1/labels?per_page=100", "/repos/octo/repo10", "/orgs/octo/repos"} {
+		cachedGet(t, client, base+path)
+	}
+
+	req, _ := http.NewRequest(http.MethodPatch, base+"/repos/octo/repo1", strings.NewReader(`{}`))
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("PATCH failed: %v", err)
+	}
+	resp.Body.Close()
+
+	// repo10 and the org listing aren't about repo1
+	if store.Len() != 2 {
+		t.Errorf("expected 2 entries left, got %d", store.Len())
+	}
+	if _, status := cachedGet(t, client, base+"/repos/octo/repo10"); status != "hit" {
+		t.Errorf("expected repo10 to stay cached, got %q", status)
+	}
+	if _, status := cachedGet(t, client, base+"/repos/octo/repo1"); status != "" {
+		t.Errorf("expected repo1 to be fetched again, got %q", status)
+	}
+}
+
+func TestKey_KeepsPathCase(t *testing.T) {
+	upper, _ := url.Parse("HTTPS://API.GitHub.com/repos/octo/repo1/contents/README.md?ref=Main")
+	lower, _ := url.Parse("https://api.github.com/repos/octo/repo1/contents/readme.md?ref=Main")
+
+	if got := httpcache.Key(upper); got != "https://api.github.com/repos/octo/repo1/contents/README.md?ref=Main" {
+		t.Errorf("expected only the scheme and host lowercased, got %q", got)
+	}
+	if httpcache.Key(upper) == httpcache.Key(lower) {
+		t.Error("expected files differing in case to have different keys")
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := httpcache.NewMemoryStore(2)
+
+	store.Set(ctx, "a", &httpcache.Entry{ETag: "a"})
+	store.Set(ctx, "b", &httpcache.Entry{ETag: "b"})
+	store.Get(ctx, "a")
+	store.Set(ctx, "c", &httpcache.Entry{ETag: "c"})
+
+	if _, ok, _ := store.Get(ctx, "b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := store.Get(ctx, key); !ok {
+			t.Errorf("expected %s to be kept", key)
+		}
+	}
+
+	store.Set(ctx, "a/1", &httpcache.Entry{})
+	store.DeletePrefix(ctx, "a")
+	if store.Len() != 1 {
+		t.Errorf("expected only c left, got %d entries", store.Len())
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	ctx := context.Background()
+	redis := mocks.NewMockRedis()
+	store := httpcache.NewRedisStore(redis, "test:")
+
+	entry := &httpcache.Entry{ETag: `"v1"`, Header: http.Header{"Content-Type": {"application/json"}}, Body: []byte(`{}`), StoredAt: time.Now()}
+	if err := store.Set(ctx, "https://api.github.com/repos/octo/repo1", entry); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store.Set(ctx, "https://api.github.com/repos/octo/repo1/labels", entry)
+	store.Set(ctx, "https://api.github.com/repos/octo/repo2", entry)
+
+	got, ok, err := store.Get(ctx, "https://api.github.com/repos/octo/repo1")
+	if err != nil || !ok {
+		t.Fatalf("expected the entry, got %v, %v", ok, err)
+	}
+	if got.ETag != `"v1"` || string(got.Body) != `{}` || got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected entry %+v", got)
+	}
+	if redis.Expiry["test:https://api.github.com/repos/octo/repo1"] <= 0 {
+		t.Error("expected entries to expire")
+	}
+
+	if err := store.DeletePrefix(ctx, "https://api.github.com/repos/octo/repo1/"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if keys := redis.Keys(); len(keys) != 2 {
+		t.Errorf("expected 2 keys left, got %v", keys)
+	}
+}
+
+func TestCache_StoreErrorsAreMisses(t *testing.T) {
+	handler := &etagHandler{body: `{}`, etag: `"v1"`}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	redis := mocks.NewMockRedis()
+	redis.Err = fmt.Errorf("connection refused")
+	client := &http.Client{Transport: httpcache.New(httpcache.NewRedisStore(redis, ""), time.Hour).Transport(nil)}
+
+	cachedGet(t, client, server.URL+"/repos/octo/repo1")
+	cachedGet(t, client, server.URL+"/repos/octo/repo1")
+	if full, _ := handler.counts(); full != 2 {
+		t.Errorf("expected both requests to reach GitHub, got %d", full)
+	}
+}
+
+// serveRedis is a Redis server knowing just enough commands for the store
+func serveRedis(t *testing.T, password string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	values := map[string]string{}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				authenticated := password == ""
+				for {
+					args, err := readCommand(reader)
+					if err != nil {
+						return
+					}
+
+					mu.Lock()
+					var reply string
+					switch command := strings.ToUpper(args[0]); {
+					case command == "AUTH":
+						authenticated = args[1] == password
+						reply = "+OK\r\n"
+						if !authenticated {
+							reply = "-WRONGPASS invalid password\r\n"
+						}
+					case !authenticated:
+						reply = "-NOAUTH Authentication required.\r\n"
+					case command == "PING":
+						reply = "+PONG\r\n"
+					case command == "SET":
+						values[args[1]] = args[2]
+						reply = "+OK\r\n"
+					case command == "GET":
+						value, ok := values[args[1]]
+						reply = "$-1\r\n"
+						if ok {
+							reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+						}
+					case command == "DEL":
+						for _, key := range args[1:] {
+							delete(values, key)
+						}
+						reply = fmt.Sprintf(":%d\r\n", len(args)-1)
+					case command == "SCAN":
+						prefix := strings.ReplaceAll(strings.TrimSuffix(args[3], "*"), `\`, "")
+						var keys []string
+						for key := range values {
+							if strings.HasPrefix(key, prefix) {
+								keys = append(keys, fmt.Sprintf("$%d\r\n%s\r\n", len(key), key))
+							}
+						}
+						reply = fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n%s", len(keys), strings.Join(keys, ""))
+					default:
+						reply = "-ERR unknown command\r\n"
+					}
+					mu.Unlock()
+
+					if _, err := io.WriteString(conn, reply); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, count)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return nil, err
+		}
+		args[i] = string(data[:size])
+	}
+	return args, nil
+}
+
+func TestDialRedis(t *testing.T) {
+	ctx := context.Background()
+	addr := serveRedis(t, "secret")
+
+	if _, err := httpcache.DialRedis(addr, "wrong"); err == nil {
+		t.Fatal("expected a wrong password to fail")
+	}
+
+	redis, err := httpcache.DialRedis(addr, "secret")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	store := httpcache.NewRedisStore(redis, "octo-manager:")
+
+	if _, ok, err := store.Get(ctx, "missing"); ok || err != nil {
+		t.Fatalf("expected a miss, got %v, %v", ok, err)
+	}
+	entry := &httpcache.Entry{ETag: `"v1"`, Body: []byte("line\r\nbreak")}
+	for _, key := range []string{"https://x/repos/o/r*", "https://x/repos/o/r*/labels", "https://x/repos/o/rr"} {
+		if err := store.Set(ctx, key, entry); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	got, ok, err := store.Get(ctx, "https://x/repos/o/r*")
+	if err != nil || !ok || string(got.Body) != "line\r\nbreak" {
+		t.Fatalf("unexpected entry %+v, %v, %v", got, ok, err)
+	}
+
+	// Glob characters in keys are matched literally
+	if err := store.DeletePrefix(ctx, "https://x/repos/o/r*"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok, _ := store.Get(ctx, "https://x/repos/o/r*/labels"); ok {
+		t.Error("expected the prefix to be deleted")
+	}
+	if _, ok, _ := store.Get(ctx, "https://x/repos/o/rr"); !ok {
+		t.Error("expected other keys to be kept")
+	}
+}
+
+func TestCachingClient_RepoChangesInvalidateListing(t *testing.T) {
+	listing := &etagHandler{body: `[{"name":"repo1"}]`, etag: `"v1"`}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/octo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login":"octo","type":"Organization"}`)
+	})
+	mux.Handle("GET /orgs/octo/repos", listing)
+	mux.HandleFunc("GET /repos/octo/repo1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"repo1"}`)
+	})
+	mux.HandleFunc("DELETE /repos/octo/repo1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	cache := httpcache.New(httpcache.NewMemoryStore(10), time.Hour)
+	client := githubapi.NewCachingClient(newTestGitHub(t, mux), cache)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		repos, err := client.ListReposForOwner(ctx, "octo")
+		if err != nil || len(repos) != 1 {
+			t.Fatalf("unexpected listing %v, %v", repos, err)
+		}
+	}
+	if full, _ := listing.counts(); full != 1 {
+		t.Fatalf("expected the listing to be cached, got %d requests", full)
+	}
+
+	if err := client.DeleteRepoForOwner(ctx, "octo", "repo1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.ListReposForOwner(ctx, "octo")
+	if full, _ := listing.counts(); full != 2 {
+		t.Errorf("expected the listing to be fetched after the delete, got %d requests", full)
+	}
+}
+
+func TestCachingClient_RepoChangesInvalidateInstallationListing(t *testing.T) {
+	listing := &etagHandler{body: `{"total_count":1,"repositories":[{"name":"repo1","owner":{"login":"octo"}}]}`, etag: `"v1"`}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/octo", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"login":"octo","type":"User"}`)
+	})
+	mux.Handle("GET /installation/repositories", listing)
+	mux.HandleFunc("GET /repos/octo/repo1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"name":"repo1"}`)
+	})
+	mux.HandleFunc("DELETE /repos/octo/repo1", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	cache := httpcache.New(httpcache.NewMemoryStore(10), time.Hour)
+	client := githubapi.NewAppCachingClient(newTestGitHub(t, mux), cache)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		repos, err := client.ListReposForOwner(ctx, "octo")
+		if err != nil || len(repos) != 1 {
+			t.Fatalf("unexpected listing %v, %v", repos, err)
+		}
+	}
+	if full, _ := listing.counts(); full != 1 {
+		t.Fatalf("expected the listing to be cached, got %d requests", full)
+	}
+
+	if err := client.DeleteRepoForOwner(ctx, "octo", "repo1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client.ListReposForOwner(ctx, "octo")
+	if full, _ := listing.counts(); full != 2 {
+		t.Errorf("expected the listing to be fetched after the delete, got %d requests", full)
+	}
+}
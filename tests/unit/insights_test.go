@@ -0,0 +1,149 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/insights"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestRealClient_StatsPending(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/my-org/repo1/stats/contributors", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprint(w, `{}`)
+	})
+	mux.HandleFunc("/repos/my-org/repo1/stats/code_frequency", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[[1700000000, 120, -30]]`)
+	})
+
+	client := githubapi.NewRealGitHubClient(newTestGitHub(t, mux))
+
+	if _, err := client.ListContributorStatsForOwner(context.Background(), "my-org", "repo1"); err != githubapi.ErrStatsPending {
+		t.Errorf("expected ErrStatsPending for a 202, got %v", err)
+	}
+
+	frequency, err := client.ListCodeFrequencyForOwner(context.Background(), "my-org", "repo1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(frequency) != 1 || frequency[0].GetAdditions() != 120 || frequency[0].GetDeletions() != -30 {
+		t.Errorf("unexpected code frequency %+v", frequency)
+	}
+}
+
+func insightsMock() *mocks.MockGitHubClient {
+	week := &github.Timestamp{Time: time.Date(2024, 3, 3, 0, 0, 0, 0, time.UTC)}
+	return &mocks.MockGitHubClient{
+		Languages: map[string]map[string]int{"repo1": {"Go": 300, "Shell": 100}},
+		ContributorStats: map[string][]*github.ContributorStats{"repo1": {
+			{Author: &github.Contributor{Login: github.String("bob")}, Total: github.Int(3),
+				Weeks: []*github.WeeklyStats{{Additions: github.Int(10), Deletions: github.Int(2)}}},
+			{Author: &github.Contributor{Login: github.String("alice")}, Total: github.Int(12),
+				Weeks: []*github.WeeklyStats{{Additions: github.Int(50), Deletions: github.Int(5)}, {Additions: github.Int(7)}}},
+		}},
+		CommitActivity: map[string][]*github.WeeklyCommitActivity{"repo1": {
+			{Week: week, Total: github.Int(4), Days: []int{0, 1, 1, 2, 0, 0, 0}},
+		}},
+		CodeFrequency: map[string][]*github.WeeklyStats{"repo1": {
+			{Week: week, Additions: github.Int(57), Deletions: github.Int(-7)},
+		}},
+		TrafficViews: map[string]*github.TrafficViews{"repo1": {
+			Count: github.Int(40), Uniques: github.Int(9),
+			Views: []*github.TrafficData{{Timestamp: week, Count: github.Int(40), Uniques: github.Int(9)}},
+		}},
+	}
+}
+
+func newInsightsService(mock *mocks.MockGitHubClient, ttl time.Duration) *insights.Service {
+	service := insights.NewService(githubapi.NewTestClient(mock, "my-org"), ttl)
+	service.PollDelays = []time.Duration{time.Millisecond, time.Millisecond}
+	return service
+}
+
+func TestInsights_Get(t *testing.T) {
+	service := newInsightsService(insightsMock(), time.Hour)
+
+	result, err := service.Get(context.Background(), "repo1", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(result.Languages) != 2 || result.Languages[0].Name != "Go" || result.Languages[0].Percent != 75 {
+		t.Errorf("expected Go first at 75%%, got %+v", result.Languages)
+	}
+	if len(result.TopContributors) != 2 || result.TopContributors[0] != (insights.Contributor{Login: "alice", Commits: 12, Additions: 57, Deletions: 5}) {
+		t.Errorf("expected alice as the top contributor, got %+v", result.TopContributors)
+	}
+	if len(result.CommitActivity) != 1 || result.CommitActivity[0].Commits != 4 {
+		t.Errorf("unexpected commit activity %+v", result.CommitActivity)
+	}
+	if len(result.CodeFrequency) != 1 || result.CodeFrequency[0].Deletions != 7 {
+		t.Errorf("expected positive deletions, got %+v", result.CodeFrequency)
+	}
+	if result.Traffic == nil || result.Traffic.Views.Count != 40 || len(result.Traffic.Views.Daily) != 1 {
+		t.Errorf("unexpected traffic %+v", result.Traffic)
+	}
+}
+
+func TestInsights_PollsWhilePending(t *testing.T) {
+	mock := insightsMock()
+	mock.StatsPending = 3
+	service := newInsightsService(mock, 0)
+
+	if _, err := service.Get(context.Background(), "repo1", false); err != nil {
+		t.Fatalf("expected the pending statistics to be polled, got %v", err)
+	}
+	if mock.StatsRequests != 6 {
+		t.Errorf("expected 3 computed statistics plus 3 retries, got %d requests", mock.StatsRequests)
+	}
+
+	// Still pending after every poll
+	mock.StatsPending = 100
+	if _, err := service.Get(context.Background(), "repo1", false); !errors.Is(err, githubapi.ErrStatsPending) {
+		t.Errorf("expected ErrStatsPending, got %v", err)
+	}
+}
+
+func TestInsights_Cache(t *testing.T) {
+	mock := insightsMock()
+	service := newInsightsService(mock, time.Hour)
+
+	first, _ := service.Get(context.Background(), "repo1", false)
+	requests := mock.StatsRequests
+
+	second, err := service.Get(context.Background(), "repo1", false)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.StatsRequests != requests || !second.GeneratedAt.Equal(first.GeneratedAt) {
+		t.Error("expected the cached insights to be returned")
+	}
+
+	if _, err := service.Get(context.Background(), "repo1", true); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if mock.StatsRequests == requests {
+		t.Error("expected refresh to bypass the cache")
+	}
+}
+
+func TestInsights_TrafficForbidden(t *testing.T) {
+	mock := insightsMock()
+	mock.MethodErrs = map[string]error{"ListTrafficViewsForOwner": githubapi.ErrForbidden}
+
+	result, err := newInsightsService(mock, 0).Get(context.Background(), "repo1", false)
+	if err != nil {
+		t.Fatalf("expected traffic to be optional, got %v", err)
+	}
+	if result.Traffic != nil || len(result.Languages) != 2 {
+		t.Errorf("expected insights without traffic, got %+v", result)
+	}
+}
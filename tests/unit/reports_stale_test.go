@@ -0,0 +1,146 @@
+package githubapi_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/reports"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func daysAgo(days int) *github.Timestamp {
+	return &github.Timestamp{Time: time.Now().AddDate(0, 0, -days)}
+}
+
+func stalePullsMock() *mocks.MockGitHubClient {
+	return &mocks.MockGitHubClient{
+		Repos: []*github.Repository{
+			{Name: github.String("api")},
+			{Name: github.String("web")},
+			{Name: github.String("docs")},
+		},
+		RepoPullRequests: map[string][]*github.PullRequest{
+			"api": {
+				{Number: github.Int(1), Title: github.String("Old fix"), User: &github.User{Login: github.String("alice")}, CreatedAt: daysAgo(40), UpdatedAt: daysAgo(30)},
+				{Number: github.Int(2), User: &github.User{Login: github.String("bob")}, CreatedAt: daysAgo(20), UpdatedAt: daysAgo(15),
+					RequestedReviewers: []*github.User{{Login: github.String("carol")}}},
+				{Number: github.Int(3), User: &github.User{Login: github.String("alice")}, CreatedAt: daysAgo(3), UpdatedAt: daysAgo(1)},
+			},
+			"web": {
+				{Number: github.Int(4), User: &github.User{Login: github.String("alice")}, CreatedAt: daysAgo(60), UpdatedAt: daysAgo(2)},
+			},
+			"docs": {
+				{Number: github.Int(5), State: github.String("closed"), User: &github.User{Login: github.String("bob")}, CreatedAt: daysAgo(90), UpdatedAt: daysAgo(90)},
+			},
+		},
+		Reviews: map[int][]*github.PullRequestReview{
+			1: {
+				{User: &github.User{Login: github.String("carol")}, State: github.String("APPROVED")},
+				{User: &github.User{Login: github.String("dave")}, State: github.String("COMMENTED")},
+			},
+		},
+	}
+}
+
+func TestStalePulls_GroupsByRepoAndAuthor(t *testing.T) {
+	reporter := reports.NewReporter(githubapi.NewTestClient(stalePullsMock(), "my-org"), 2)
+
+	report, err := reporter.StalePulls(context.Background(), 14)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if report.Count != 2 || len(report.Repositories) != 1 {
+		t.Fatalf("expected 2 stale PRs in api only, got %+v", report)
+	}
+	api := report.Repositories[0]
+	if api.Repository != "api" || len(api.Authors) != 2 {
+		t.Fatalf("expected api grouped by 2 authors, got %+v", api)
+	}
+
+	alice, bob := api.Authors[0], api.Authors[1]
+	if alice.Author != "alice" || len(alice.PullRequests) != 1 || alice.PullRequests[0].Number != 1 {
+		t.Errorf("expected alice's PR 1, got %+v", alice)
+	}
+	if got := alice.PullRequests[0]; got.ReviewStatus != reports.ReviewApproved || got.IdleDays != 30 || got.AgeDays != 40 {
+		t.Errorf("expected an approved PR idle for 30 days, got %+v", got)
+	}
+	if got := bob.PullRequests[0]; got.ReviewStatus != reports.ReviewRequired {
+		t.Errorf("expected bob's PR to be waiting for review, got %q", got.ReviewStatus)
+	}
+}
+
+func TestStalePulls_InvalidDays(t *testing.T) {
+	reporter := reports.NewReporter(githubapi.NewTestClient(stalePullsMock(), "my-org"), 0)
+
+	if _, err := reporter.StalePulls(context.Background(), 0); err != reports.ErrInvalidDays {
+		t.Errorf("expected ErrInvalidDays, got %v", err)
+	}
+}
+
+func TestStalePulls_RepoErrorsAreReported(t *testing.T) {
+	mock := stalePullsMock()
+	mock.MethodErrs = map[string]error{"ListReviewsForOwner": githubapi.ErrForbidden}
+
+	report, err := reports.NewReporter(githubapi.NewTestClient(mock, "my-org"), 2).StalePulls(context.Background(), 14)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if report.Count != 0 || report.Errors["api"] == "" {
+		t.Errorf("expected api to be listed under errors, got %+v", report)
+	}
+}
+
+func TestStalePulls_RateLimit(t *testing.T) {
+	mock := stalePullsMock()
+	mock.Rate = &github.Rate{Limit: 5000, Remaining: 2, Reset: github.Timestamp{Time: time.Now().Add(time.Minute)}}
+
+	_, err := reports.NewReporter(githubapi.NewTestClient(mock, "my-org"), 2).StalePulls(context.Background(), 14)
+	if !errors.Is(err, githubapi.ErrRateLimited) {
+		t.Errorf("expected too few requests left for 3 repos to be refused, got %v", err)
+	}
+
+	// Running out midway returns what was read
+	mock.Rate = nil
+	mock.MethodErrs = map[string]error{"ListReviewsForOwner": githubapi.ErrRateLimited}
+	report, err := reports.NewReporter(githubapi.NewTestClient(mock, "my-org"), 1).StalePulls(context.Background(), 14)
+	if err != nil {
+		t.Fatalf("expected a partial report, got %v", err)
+	}
+	if !report.Partial || !slices.Contains(report.Unread, "api") {
+		t.Errorf("expected a partial report with api unread, got %+v", report)
+	}
+	if report.Errors["api"] != "" {
+		t.Errorf("expected the rate limited repo to be unread rather than failed, got %+v", report.Errors)
+	}
+}
+
+func TestStaleReport_WriteCSV(t *testing.T) {
+	report, err := reports.NewReporter(githubapi.NewTestClient(stalePullsMock(), "my-org"), 2).StalePulls(context.Background(), 14)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %q", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "repository,author,number,title,review_status") {
+		t.Errorf("unexpected header %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "api,alice,1,Old fix,approved,40,30,") {
+		t.Errorf("unexpected first row %q", lines[1])
+	}
+}
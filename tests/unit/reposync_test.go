@@ -0,0 +1,184 @@
+package githubapi_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/reposync"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func labelsMock() *mocks.MockGitHubClient {
+	return &mocks.MockGitHubClient{
+		Labels: map[string][]*github.Label{"repo1": {
+			{Name: github.String("Bug"), Color: github.String("D73A4A"), Description: github.String("Something is broken")},
+			{Name: github.String("docs"), Color: github.String("0075ca"), Description: github.String("")},
+			{Name: github.String("wontfix"), Color: github.String("ffffff"), Description: github.String("")},
+		}},
+	}
+}
+
+var canonicalLabels = []githubapi.Label{
+	{Name: "bug", Color: "#d73a4a", Description: "Something is broken"},
+	{Name: "docs", Color: "0075ca"},
+	{Name: "feature", Color: "a2eeef", Description: "New functionality"},
+}
+
+func changeActions[T any](changes []reposync.Change[T]) map[string]string {
+	actions := map[string]string{}
+	for _, change := range changes {
+		actions[change.Name] = change.Action
+	}
+	return actions
+}
+
+func TestSyncLabels_DryRun(t *testing.T) {
+	mock := labelsMock()
+	syncer := reposync.NewSyncer(githubapi.NewTestClient(mock, "my-org"), 0)
+
+	result, err := syncer.Labels(context.Background(), "repo1", canonicalLabels, reposync.Options{Prune: true, DryRun: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Bug only differs by case, docs matches, wontfix is extra
+	expected := map[string]string{"bug": "update", "feature": "create", "wontfix": "delete"}
+	actions := changeActions(result.Changes)
+	if len(actions) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actions)
+	}
+	for name, action := range expected {
+		if actions[name] != action {
+			t.Errorf("expected %s to %s, got %q", name, action, actions[name])
+		}
+	}
+	if result.Applied || len(mock.Labels["repo1"]) != 3 {
+		t.Error("expected a dry run to change nothing")
+	}
+}
+
+func TestSyncLabels_Apply(t *testing.T) {
+	mock := labelsMock()
+	syncer := reposync.NewSyncer(githubapi.NewTestClient(mock, "my-org"), 0)
+
+	result, err := syncer.Labels(context.Background(), "repo1", canonicalLabels, reposync.Options{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !result.Applied {
+		t.Error("expected the changes to be applied")
+	}
+
+	// Extras stay without prune
+	labels := mock.Labels["repo1"]
+	if len(labels) != 4 || labels[0].GetName() != "bug" || labels[3].GetName() != "feature" {
+		t.Errorf("unexpected labels %+v", labels)
+	}
+
+	// Synced, so nothing left to change
+	result, _ = syncer.Labels(context.Background(), "repo1", canonicalLabels, reposync.Options{})
+	if len(result.Changes) != 0 {
+		t.Errorf("expected no changes, got %+v", result.Changes)
+	}
+}
+
+func TestSyncLabels_Invalid(t *testing.T) {
+	syncer := reposync.NewSyncer(githubapi.NewTestClient(labelsMock(), "my-org"), 0)
+
+	duplicated := []githubapi.Label{{Name: "bug", Color: "d73a4a"}, {Name: "BUG", Color: "d73a4a"}}
+	if _, err := syncer.Labels(context.Background(), "repo1", duplicated, reposync.Options{}); !errors.Is(err, reposync.ErrDuplicateLabel) {
+		t.Errorf("expected ErrDuplicateLabel, got %v", err)
+	}
+	if _, err := syncer.Labels(context.Background(), "repo1", []githubapi.Label{{Name: "bug", Color: "red"}}, reposync.Options{}); !errors.Is(err, githubapi.ErrInvalidLabelColor) {
+		t.Errorf("expected ErrInvalidLabelColor, got %v", err)
+	}
+}
+
+func TestSyncMilestones(t *testing.T) {
+	due := time.Date(2025, 6, 30, 7, 0, 0, 0, time.UTC)
+	mock := &mocks.MockGitHubClient{
+		Milestones: map[string][]*github.Milestone{"repo1": {
+			{Number: github.Int(1), Title: github.String("v1.0"), State: github.String("open"), DueOn: &github.Timestamp{Time: due}},
+			{Number: github.Int(2), Title: github.String("v0.9"), State: github.String("open")},
+			{Number: github.Int(3), Title: github.String("someday"), State: github.String("open")},
+		}},
+	}
+	syncer := reposync.NewSyncer(githubapi.NewTestClient(mock, "my-org"), 0)
+
+	// Same day at another time
+	desiredDue := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	desired := []githubapi.Milestone{
+		{Title: "v1.0", DueOn: &desiredDue},
+		{Title: "v0.9", State: "closed"},
+		{Title: "v2.0", Description: "Next major"},
+	}
+
+	result, err := syncer.Milestones(context.Background(), "repo1", desired, reposync.Options{Prune: true})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := map[string]string{"v0.9": "update", "v2.0": "create", "someday": "delete"}
+	actions := changeActions(result.Changes)
+	if len(actions) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, actions)
+	}
+	for name, action := range expected {
+		if actions[name] != action {
+			t.Errorf("expected %s to %s, got %q", name, action, actions[name])
+		}
+	}
+
+	milestones := mock.Milestones["repo1"]
+	if len(milestones) != 3 || milestones[1].GetState() != "closed" || milestones[2].GetNumber() != 4 {
+		t.Errorf("unexpected milestones %+v", milestones)
+	}
+}
+
+func TestSyncAllLabels(t *testing.T) {
+	mock := labelsMock()
+	mock.Repos = []*github.Repository{
+		{Name: github.String("repo2")},
+		{Name: github.String("repo1")},
+		{Name: github.String("old"), Archived: github.Bool(true)},
+	}
+	syncer := reposync.NewSyncer(githubapi.NewTestClient(mock, "my-org"), 2)
+
+	results, err := syncer.AllLabels(context.Background(), canonicalLabels, reposync.Options{})
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(results) != 2 || results[0].Repository != "repo1" || results[1].Repository != "repo2" {
+		t.Fatalf("expected the unarchived repos sorted by name, got %+v", results)
+	}
+	if len(mock.Labels["repo2"]) != 3 || len(mock.Labels["old"]) != 0 {
+		t.Errorf("expected repo2 to be synced and the archived repo skipped, got %+v", mock.Labels)
+	}
+	if reposync.Failed(results) != 0 {
+		t.Errorf("expected no failures, got %+v", results)
+	}
+}
+
+func TestSyncAllLabels_Errors(t *testing.T) {
+	mock := labelsMock()
+	mock.Repos = []*github.Repository{{Name: github.String("repo1")}, {Name: github.String("repo2")}}
+	mock.MethodErrs = map[string]error{"CreateLabelForOwner": githubapi.ErrForbidden}
+	syncer := reposync.NewSyncer(githubapi.NewTestClient(mock, "my-org"), 0)
+
+	results, err := syncer.AllLabels(context.Background(), canonicalLabels, reposync.Options{})
+	if err != nil {
+		t.Fatalf("expected failures to be reported per repo, got %v", err)
+	}
+	if reposync.Failed(results) != 2 || results[0].Error == "" {
+		t.Errorf("expected both repos to fail, got %+v", results)
+	}
+
+	mock.MethodErrs = map[string]error{"ListLabelsForOwner": githubapi.ErrRateLimited}
+	if _, err := syncer.AllLabels(context.Background(), canonicalLabels, reposync.Options{}); !errors.Is(err, githubapi.ErrRateLimited) {
+		t.Errorf("expected ErrRateLimited to stop the sync, got %v", err)
+	}
+}
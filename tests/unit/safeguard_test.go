@@ -0,0 +1,152 @@
+package githubapi_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/safeguard"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func TestGuard_Protected(t *testing.T) {
+	guard, err := safeguard.NewGuard(nil, []string{"Prod-API"}, []string{"infra-.*"}, 0)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	tests := map[string]bool{
+		"prod-api":       true, // Names are case insensitive
+		"infra-network":  true,
+		"my-infra-tools": false, // Patterns match the whole name
+		"prod-api-old":   false,
+	}
+	for name, expected := range tests {
+		if got := guard.Protected(name); got != expected {
+			t.Errorf("Protected(%q): expected %v, got %v", name, expected, got)
+		}
+	}
+
+	if guard.ConfirmationRequired() {
+		t.Error("expected no confirmation with a zero ttl")
+	}
+}
+
+func TestGuard_InvalidPattern(t *testing.T) {
+	if _, err := safeguard.NewGuard(nil, nil, []string{"("}, 0); err == nil {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+func TestGuard_ConfirmationTokens(t *testing.T) {
+	guard, _ := safeguard.NewGuard(nil, nil, nil, time.Minute)
+
+	token, expiresAt, err := guard.IssueToken("repo1", true, "tarball")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if time.Until(expiresAt) > time.Minute {
+		t.Errorf("expected the token to expire within a minute, got %v", expiresAt)
+	}
+
+	if err := guard.Confirm("repo2", token, true, "tarball"); err != safeguard.ErrInvalidToken {
+		t.Errorf("expected a token for another repo to be refused, got %v", err)
+	}
+	if err := guard.Confirm("repo1", token, false, "tarball"); err != safeguard.ErrInvalidToken {
+		t.Errorf("expected a token for a soft delete to be refused for a hard one, got %v", err)
+	}
+	if err := guard.Confirm("repo1", token, true, ""); err != safeguard.ErrInvalidToken {
+		t.Errorf("expected a token for a backed up delete to be refused without a backup, got %v", err)
+	}
+	if err := guard.Confirm("repo1", token, true, "tarball"); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// Claimed until the deletion is over, a failed one gives it back
+	if err := guard.Confirm("repo1", token, true, "tarball"); err != safeguard.ErrTokenInUse {
+		t.Errorf("expected ErrTokenInUse, got %v", err)
+	}
+	guard.Release(token)
+	if err := guard.Confirm("repo1", token, true, "tarball"); err != nil {
+		t.Errorf("expected the token to outlive a failed deletion, got %v", err)
+	}
+	guard.Consume(token)
+	guard.Release(token)
+	if err := guard.Confirm("repo1", token, true, "tarball"); err != safeguard.ErrInvalidToken {
+		t.Errorf("expected the token to work only once, got %v", err)
+	}
+}
+
+func TestGuard_ConcurrentConfirmations(t *testing.T) {
+	guard, _ := safeguard.NewGuard(nil, nil, nil, time.Minute)
+	token, _, err := guard.IssueToken("repo1", false, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	var confirmed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if guard.Confirm("repo1", token, false, "") == nil {
+				confirmed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if confirmed.Load() != 1 {
+		t.Errorf("expected a single deletion to claim the token, got %d", confirmed.Load())
+	}
+}
+
+func TestGuard_ExpiredToken(t *testing.T) {
+	guard, _ := safeguard.NewGuard(nil, nil, nil, time.Millisecond)
+
+	token, _, err := guard.IssueToken("repo1", false, "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if err := guard.Confirm("repo1", token, false, ""); err != safeguard.ErrInvalidToken {
+		t.Errorf("expected ErrInvalidToken, got %v", err)
+	}
+}
+
+func TestGuard_DryRun(t *testing.T) {
+	pushedAt := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	mockClient := &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{
+			Name:            github.String("repo1"),
+			StargazersCount: github.Int(12),
+			ForksCount:      github.Int(3),
+			OpenIssuesCount: github.Int(5),
+			PushedAt:        &github.Timestamp{Time: pushedAt},
+		}},
+		PullRequests: []*github.PullRequest{{Number: github.Int(1)}, {Number: github.Int(2)}},
+	}
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	guard, _ := safeguard.NewGuard(client, []string{"repo1"}, nil, 0)
+
+	report, err := guard.DryRun(context.Background(), "repo1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if !report.Protected || report.OpenPullRequests != 2 || report.OpenIssues != 3 || report.Stars != 12 || report.Forks != 3 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.PushedAt == nil || !report.PushedAt.Equal(pushedAt) {
+		t.Errorf("expected pushed_at %v, got %v", pushedAt, report.PushedAt)
+	}
+	if len(mockClient.Repos) != 1 {
+		t.Error("expected the dry run to delete nothing")
+	}
+}
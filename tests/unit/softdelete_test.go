@@ -0,0 +1,203 @@
+package githubapi_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/githubapi"
+	"github.com/jorgebaptista/octo-manager/internal/softdelete"
+	"github.com/jorgebaptista/octo-manager/tests/mocks"
+)
+
+func newSoftDeleteMock() *mocks.MockGitHubClient {
+	return &mocks.MockGitHubClient{
+		Repos: []*github.Repository{{Name: github.String("repo1"), Topics: []string{"go"}}},
+	}
+}
+
+func TestSoftDelete_ArchivesAndRestores(t *testing.T) {
+	mockClient := newSoftDeleteMock()
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	manager := softdelete.NewManager(client, softdelete.NewMemoryStore(), time.Hour)
+
+	entry, err := manager.SoftDelete(context.Background(), "repo1")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if entry.PurgeAt.Sub(entry.DeletedAt) != time.Hour {
+		t.Errorf("expected a one hour grace period, got %v", entry.PurgeAt.Sub(entry.DeletedAt))
+	}
+
+	repo := mockClient.Repos[0]
+	if !repo.GetArchived() {
+		t.Error("expected the repo to be archived")
+	}
+	if len(repo.Topics) != 2 || repo.Topics[1] != softdelete.PendingTopic {
+		t.Errorf("expected the repo to be tagged, got %v", repo.Topics)
+	}
+
+	if _, err := manager.SoftDelete(context.Background(), "repo1"); err != softdelete.ErrAlreadyPending {
+		t.Errorf("expected ErrAlreadyPending, got %v", err)
+	}
+
+	if err := manager.Restore(context.Background(), "repo1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if repo.GetArchived() || len(repo.Topics) != 1 || repo.Topics[0] != "go" {
+		t.Errorf("expected the repo to be back to normal, got archived=%v topics=%v", repo.GetArchived(), repo.Topics)
+	}
+
+	if err := manager.Restore(context.Background(), "repo1"); err != softdelete.ErrNotPending {
+		t.Errorf("expected ErrNotPending, got %v", err)
+	}
+}
+
+func TestSoftDelete_Topics(t *testing.T) {
+	full := make([]string, githubapi.MaxTopics)
+	for i := range full {
+		full[i] = fmt.Sprintf("topic-%d", i)
+	}
+
+	for name, topics := range map[string][]string{
+		"already tagged": {"go", softdelete.PendingTopic},
+		"no room":        full,
+	} {
+		mockClient := &mocks.MockGitHubClient{Repos: []*github.Repository{{Name: github.String("repo1"), Topics: topics}}}
+		manager := softdelete.NewManager(githubapi.NewTestClient(mockClient, "test_owner"), softdelete.NewMemoryStore(), time.Hour)
+
+		if _, err := manager.SoftDelete(context.Background(), "repo1"); err != nil {
+			t.Fatalf("%s: expected no error, got %v", name, err)
+		}
+		repo := mockClient.Repos[0]
+		if !repo.GetArchived() || !slices.Equal(repo.Topics, topics) {
+			t.Errorf("%s: expected the repo archived with its topics unchanged, got archived=%v topics=%v", name, repo.GetArchived(), repo.Topics)
+		}
+	}
+}
+
+func TestSoftDelete_ArchiveFailureRemovesTag(t *testing.T) {
+	mockClient := newSoftDeleteMock()
+	mockClient.MethodErrs = map[string]error{"SetRepoArchivedForOwner": githubapi.ErrForbidden}
+	store := softdelete.NewMemoryStore()
+	manager := softdelete.NewManager(githubapi.NewTestClient(mockClient, "test_owner"), store, time.Hour)
+
+	if _, err := manager.SoftDelete(context.Background(), "repo1"); err != githubapi.ErrForbidden {
+		t.Fatalf("expected ErrForbidden, got %v", err)
+	}
+	if topics := mockClient.Repos[0].Topics; len(topics) != 1 || topics[0] != "go" {
+		t.Errorf("expected the original topics back, got %v", topics)
+	}
+	if _, pending, _ := store.Get("repo1"); pending {
+		t.Error("expected the repo not to be pending deletion")
+	}
+}
+
+func TestSoftDelete_ReapAfterGracePeriod(t *testing.T) {
+	mockClient := newSoftDeleteMock()
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	manager := softdelete.NewManager(client, softdelete.NewMemoryStore(), 0)
+
+	if _, err := manager.SoftDelete(context.Background(), "repo1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// With no grace period the restore window is already over
+	if err := manager.Restore(context.Background(), "repo1"); err != softdelete.ErrRestoreExpired {
+		t.Errorf("expected ErrRestoreExpired, got %v", err)
+	}
+
+	deleted, err := manager.Reap(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "repo1" {
+		t.Errorf("expected repo1 to be reaped, got %v", deleted)
+	}
+	if len(mockClient.Repos) != 0 {
+		t.Errorf("expected the repo to be deleted, got %d repos", len(mockClient.Repos))
+	}
+
+	pending, _ := manager.Pending()
+	if len(pending) != 0 {
+		t.Errorf("expected no pending deletions, got %+v", pending)
+	}
+}
+
+func TestSoftDelete_ReapKeepsFailedDeletions(t *testing.T) {
+	mockClient := newSoftDeleteMock()
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	manager := softdelete.NewManager(client, softdelete.NewMemoryStore(), 0)
+
+	if _, err := manager.SoftDelete(context.Background(), "repo1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	mockClient.Err = githubapi.Error("mock error")
+	deleted, err := manager.Reap(context.Background())
+	if err != nil || len(deleted) != 0 {
+		t.Fatalf("expected nothing reaped and no error, got %v and %v", deleted, err)
+	}
+
+	pending, _ := manager.Pending()
+	if len(pending) != 1 {
+		t.Errorf("expected the deletion to be retried later, got %+v", pending)
+	}
+}
+
+func TestSoftDelete_ReapForgetsRepositoriesAlreadyDeleted(t *testing.T) {
+	mockClient := newSoftDeleteMock()
+	client := githubapi.NewTestClient(mockClient, "test_owner")
+	manager := softdelete.NewManager(client, softdelete.NewMemoryStore(), 0)
+
+	if _, err := manager.SoftDelete(context.Background(), "repo1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// Deleted on GitHub in the meantime
+	mockClient.Repos = nil
+	deleted, err := manager.Reap(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "repo1" {
+		t.Errorf("expected repo1 to count as reaped, got %v", deleted)
+	}
+
+	pending, _ := manager.Pending()
+	if len(pending) != 0 {
+		t.Errorf("expected no pending deletions, got %+v", pending)
+	}
+}
+
+func TestSoftDelete_FileStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pending.json")
+	store := softdelete.NewFileStore(path)
+
+	entry := softdelete.Entry{Repo: "repo1", PurgeAt: time.Now().Add(time.Hour).UTC()}
+	if err := store.Put(entry); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	// A new store on the same file sees the entry
+	reloaded := softdelete.NewFileStore(path)
+	got, ok, err := reloaded.Get("repo1")
+	if err != nil || !ok {
+		t.Fatalf("expected the entry to be persisted, got ok=%v err=%v", ok, err)
+	}
+	if !got.PurgeAt.Equal(entry.PurgeAt) {
+		t.Errorf("expected purge time %v, got %v", entry.PurgeAt, got.PurgeAt)
+	}
+
+	if err := reloaded.Delete("repo1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	entries, _ := store.List()
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %+v", entries)
+	}
+}
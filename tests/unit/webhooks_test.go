@@ -0,0 +1,156 @@
+package githubapi_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v67/github"
+	"github.com/jorgebaptista/octo-manager/internal/webhooks"
+)
+
+const webhookSecret = "It's a Secret to Everybody"
+
+func webhookRequest(t *testing.T, event, delivery, contentType, body, secret string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest("POST", "/webhooks/github", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-GitHub-Delivery", delivery)
+	return req
+}
+
+const pushPayload = `{"ref": "refs/heads/main", "repository": {"name": "repo1"}}`
+
+func TestReceiver_Dispatch(t *testing.T) {
+	receiver := webhooks.NewReceiver(webhookSecret, time.Hour)
+	var pushed []string
+	receiver.OnPush(func(ctx context.Context, event *github.PushEvent) error {
+		pushed = append(pushed, event.GetRepo().GetName()+"@"+event.GetRef())
+		return nil
+	})
+	receiver.OnPullRequest(func(ctx context.Context, event *github.PullRequestEvent) error {
+		t.Error("expected only push handlers to be called")
+		return nil
+	})
+
+	delivery, err := receiver.Receive(context.Background(), webhookRequest(t, "push", "d1", "application/json; charset=utf-8", pushPayload, webhookSecret))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if delivery != (webhooks.Delivery{ID: "d1", Event: "push", Handled: 1}) {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+	if len(pushed) != 1 || pushed[0] != "repo1@refs/heads/main" {
+		t.Errorf("expected the push to be handled, got %v", pushed)
+	}
+
+	// Form deliveries sign the whole body
+	form := "payload=" + url.QueryEscape(pushPayload)
+	if _, err := receiver.Receive(context.Background(), webhookRequest(t, "push", "d2", "application/x-www-form-urlencoded", form, webhookSecret)); err != nil {
+		t.Fatalf("expected a form delivery to be received, got %v", err)
+	}
+	if len(pushed) != 2 {
+		t.Errorf("expected the form push to be handled, got %v", pushed)
+	}
+
+	// Events without handlers are still received
+	delivery, err = receiver.Receive(context.Background(), webhookRequest(t, "ping", "d3", "application/json", `{"zen": "Keep it simple."}`, webhookSecret))
+	if err != nil || delivery.Handled != 0 {
+		t.Errorf("expected an unhandled ping, got %+v and %v", delivery, err)
+	}
+}
+
+func TestReceiver_Signature(t *testing.T) {
+	receiver := webhooks.NewReceiver(webhookSecret, time.Hour)
+
+	if _, err := receiver.Receive(context.Background(), webhookRequest(t, "push", "d1", "application/json", pushPayload, "wrong secret")); err != webhooks.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+
+	req := webhookRequest(t, "push", "d1", "application/json", pushPayload, webhookSecret)
+	req.Header.Del("X-Hub-Signature-256")
+	if _, err := receiver.Receive(context.Background(), req); err != webhooks.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature without a signature, got %v", err)
+	}
+}
+
+func TestReceiver_Deduplicates(t *testing.T) {
+	receiver := webhooks.NewReceiver(webhookSecret, time.Hour)
+	failures := 1
+	calls := 0
+	receiver.OnPush(func(ctx context.Context, event *github.PushEvent) error {
+		calls++
+		if failures > 0 {
+			failures--
+			return errors.New("handler failed")
+		}
+		return nil
+	})
+	receive := func() error {
+		_, err := receiver.Receive(context.Background(), webhookRequest(t, "push", "d1", "application/json", pushPayload, webhookSecret))
+		return err
+	}
+
+	if err := receive(); err == nil {
+		t.Fatal("expected the handler error")
+	}
+	// A failed delivery can be redelivered
+	if err := receive(); err != nil {
+		t.Fatalf("expected the redelivery to be handled, got %v", err)
+	}
+	if err := receive(); err != webhooks.ErrDuplicateDelivery {
+		t.Errorf("expected ErrDuplicateDelivery, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 handler calls, got %d", calls)
+	}
+}
+
+func TestReceiver_InvalidPayload(t *testing.T) {
+	receiver := webhooks.NewReceiver(webhookSecret, time.Hour)
+
+	if _, err := receiver.Receive(context.Background(), webhookRequest(t, "push", "", "application/json", pushPayload, webhookSecret)); err != webhooks.ErrMissingDelivery {
+		t.Errorf("expected ErrMissingDelivery, got %v", err)
+	}
+	if _, err := receiver.Receive(context.Background(), webhookRequest(t, "push", "d1", "text/plain", pushPayload, webhookSecret)); !errors.Is(err, webhooks.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for text, got %v", err)
+	}
+
+	// Only events with handlers need to be parsed
+	receiver.On("not_an_event", func(ctx context.Context, event interface{}) error { return nil })
+	if _, err := receiver.Receive(context.Background(), webhookRequest(t, "not_an_event", "d2", "application/json", pushPayload, webhookSecret)); !errors.Is(err, webhooks.ErrInvalidPayload) {
+		t.Errorf("expected ErrInvalidPayload for an unknown event, got %v", err)
+	}
+}
+
+func TestReceiver_UnknownEventWithoutHandlers(t *testing.T) {
+	receiver := webhooks.NewReceiver(webhookSecret, time.Hour)
+
+	delivery, err := receiver.Receive(context.Background(), webhookRequest(t, "new_event_type", "d1", "application/json", `{"action": "created"}`, webhookSecret))
+	if err != nil {
+		t.Fatalf("expected the event to be received, got %v", err)
+	}
+	if delivery != (webhooks.Delivery{ID: "d1", Event: "new_event_type", Handled: 0}) {
+		t.Errorf("unexpected delivery %+v", delivery)
+	}
+
+	// The signature is still checked first
+	if _, err := receiver.Receive(context.Background(), webhookRequest(t, "new_event_type", "d2", "application/json", `{}`, "wrong secret")); err != webhooks.ErrInvalidSignature {
+		t.Errorf("expected ErrInvalidSignature, got %v", err)
+	}
+}